@@ -28,6 +28,8 @@ import (
 	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
 	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
 	"github.com/hashicorp/nomad-pack/internal/pkg/logging"
+	"github.com/hashicorp/nomad-pack/internal/pkg/planfile"
+	"github.com/hashicorp/nomad-pack/internal/pkg/release"
 	"github.com/hashicorp/nomad-pack/internal/pkg/testfixture"
 	"github.com/hashicorp/nomad-pack/internal/pkg/version"
 	"github.com/hashicorp/nomad-pack/internal/runner/job"
@@ -150,12 +152,195 @@ func TestCLI_JobRunFails(t *testing.T) {
 	must.StrContains(t, result.cmdOut.String(), "Failed To Find Pack")
 }
 
+func TestCLI_JobRunFails_JSON(t *testing.T) {
+	t.Parallel()
+	// This test doesn't require a Nomad cluster.
+	result := runPackCmd(t, []string{"run", "--output=json", "fake-job"})
+	must.Eq(t, 1, result.exitCode)
+
+	events := decodeJSONEvents(t, result.cmdOut)
+	must.Len(t, 2, events)
+	must.Eq(t, "error", events[0]["type"])
+	must.Eq(t, "Failed To Find Pack", events[0]["@message"])
+	must.Eq(t, "result", events[1]["type"])
+	must.Eq[any](t, map[string]any{"command": "run", "success": false, "exit_code": float64(1)}, events[1]["data"])
+}
+
+func TestCLI_JobRun_AtomicReverts(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		client, err := ct.NewTestClient(s)
+		must.NoError(t, err)
+
+		packDir := testfixture.Clone(t, "v2/test_registry/packs/simple_raw_exec")
+		result := runTestPackCmd(t, s, []string{"run", packDir, "--var=count=1"})
+		expectGoodPackDeploy(t, result)
+
+		// Add a job which is new to the run, and one which Nomad refuses to
+		// register. They are deployed either side of the existing job, as
+		// templates are registered in name order.
+		newJobTpl := `job "new_job" {
+  group "app" {
+    task "server" {
+      driver = "raw_exec"
+
+      config {
+        command = "/bin/sleep"
+        args    = ["300"]
+      }
+    }
+  }
+}
+`
+		must.NoError(t, os.WriteFile(filepath.Join(packDir, "templates", "new_job.nomad.tpl"), []byte(newJobTpl), 0o644))
+		invalidJobTpl := `job "invalid_job" {
+  group "app" {
+    count = -1
+
+    task "server" {
+      driver = "raw_exec"
+
+      config {
+        command = "/bin/true"
+      }
+    }
+  }
+}
+`
+		must.NoError(t, os.WriteFile(filepath.Join(packDir, "templates", "zz_invalid.nomad.tpl"), []byte(invalidJobTpl), 0o644))
+
+		result = runTestPackCmd(t, s, []string{"run", packDir, "--var=count=2", "--atomic"})
+		must.Eq(t, 1, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+		out := result.cmdOut.String()
+		must.StrContains(t, out, fmt.Sprintf("Job '%s' in pack deployment '%s' registered successfully", testPack, testPack))
+		must.StrContains(t, out, fmt.Sprintf("Job '%s' reverted to version 0", testPack))
+		must.StrContains(t, out, "Job 'new_job' did not exist before this deployment and has been purged")
+
+		// The job is back at the spec it had before the failed run.
+		j, _, err := client.Jobs().Info(testPack, &api.QueryOptions{})
+		must.NoError(t, err)
+		must.Eq(t, 1, *j.TaskGroups[0].Count)
+		must.Eq(t, 2, *j.Version)
+
+		// The job new to the run is purged rather than left stopped.
+		_, _, err = client.Jobs().Info("new_job", &api.QueryOptions{})
+		must.ErrorContains(t, err, "not found")
+
+		_, _, err = client.Jobs().Info("invalid_job", &api.QueryOptions{})
+		must.ErrorContains(t, err, "not found")
+	})
+}
+
+func TestCLI_JobPlan_JSON(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		result := runTestPackCmd(t, s, []string{"plan", "--json", getTestPackPath(t, testPack)})
+		must.Eq(t, 1, result.exitCode)
+		expectNoStdErrOutput(t, result)
+
+		var plan, final map[string]any
+		for _, event := range decodeJSONEvents(t, result.cmdOut) {
+			switch event["type"] {
+			case "plan":
+				plan = event["data"].(map[string]any)
+			case "result":
+				final = event["data"].(map[string]any)
+			}
+		}
+		must.NotNil(t, plan)
+		must.Eq(t, testPack, plan["job_id"])
+		must.Eq(t, true, plan["changes"])
+		must.MapContainsKey(t, plan, "diff")
+		must.Eq(t, map[string]any{
+			"command": "plan", "deployment": testPack, "success": true, "exit_code": float64(1),
+		}, final)
+	})
+}
+
 func TestCLI_JobPlan(t *testing.T) {
 	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
 		expectGoodPackPlan(t, runTestPackCmd(t, s, []string{"plan", getTestPackPath(t, testPack)}))
 	})
 }
 
+func TestCLI_JobPlan_OutRun(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		planPath := filepath.Join(t.TempDir(), "plan.json")
+		result := runTestPackCmd(t, s, []string{"plan", "--out=" + planPath, getTestPackPath(t, testPack)})
+		expectGoodPackPlan(t, result)
+		must.StrContains(t, result.cmdOut.String(), "Plan saved to "+planPath)
+
+		expectGoodPackDeploy(t, runTestPackCmd(t, s, []string{"run", planPath}))
+
+		// The job has changed since it was planned, so applying the plan
+		// again must fail.
+		result = runTestPackCmd(t, s, []string{"run", planPath})
+		must.Eq(t, 1, result.exitCode)
+		must.StrContains(t, result.cmdOut.String(), "modify index")
+	})
+}
+
+func TestCLI_JobPlan_OutHookTemplates(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		packDir := testfixture.Clone(t, "v2/test_registry/packs/simple_raw_exec")
+
+		batchJobTpl := `job %q {
+			datacenters = ["dc1"]
+			type        = "batch"
+
+			group "app" {
+				task "run" {
+					driver = "raw_exec"
+
+					config {
+						command = "/bin/true"
+					}
+				}
+			}
+		}
+		`
+		for dir, name := range map[string]string{
+			filepath.Join("hooks", "pre-run"): "migrate",
+			"tests":                           "smoke",
+		} {
+			tplDir := filepath.Join(packDir, "templates", dir)
+			must.NoError(t, os.MkdirAll(tplDir, 0755))
+			must.NoError(t, os.WriteFile(filepath.Join(tplDir, name+".nomad.tpl"), fmt.Appendf(nil, batchJobTpl, name), 0644))
+		}
+
+		planPath := filepath.Join(t.TempDir(), "plan.json")
+		expectGoodPackPlan(t, runTestPackCmd(t, s, []string{"plan", "--out=" + planPath, packDir}))
+
+		// The hook and test templates are saved without a job plan, and the
+		// plan can still be applied.
+		plan, err := planfile.Read(planPath)
+		must.NoError(t, err)
+		must.MapLen(t, 3, plan.Templates)
+		must.Len(t, 1, plan.Jobs)
+		must.Eq(t, []string{
+			"simple_raw_exec/templates/hooks/pre-run/migrate.nomad.tpl",
+			"simple_raw_exec/templates/tests/smoke.nomad.tpl",
+		}, plan.Unplanned)
+	})
+}
+
+func TestCLI_JobRun_PlanFileFlags(t *testing.T) {
+	t.Parallel()
+	// This test doesn't require a Nomad cluster.
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	must.NoError(t, planfile.Write(planPath, &planfile.Plan{
+		DeploymentName: testPack,
+		Templates:      map[string]string{"simple_raw_exec/templates/simple_raw_exec.nomad.tpl": `job "simple_raw_exec" {}`},
+		Jobs:           []*planfile.Job{{Template: "simple_raw_exec/templates/simple_raw_exec.nomad.tpl", ID: testPack}},
+	}))
+
+	result := runPackCmd(t, []string{"run", "--check-index=10", planPath})
+	must.Eq(t, 1, result.exitCode)
+	must.StrContains(t, result.cmdOut.String(), "--check-index cannot be used with a plan file")
+
+	result = runPackCmd(t, []string{"run", "--name=other", planPath})
+	must.Eq(t, 1, result.exitCode)
+	must.StrContains(t, result.cmdOut.String(), `does not match the deployment "simple_raw_exec" of the plan`)
+}
+
 func TestCLI_JobPlan_BadJob(t *testing.T) {
 	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
 		result := runTestPackCmd(t, s, []string{"plan", "fake-job"})
@@ -802,6 +987,113 @@ func TestCLI_PackStatus_Fails(t *testing.T) {
 	})
 }
 
+func TestCLI_PackTest_UnitJobID(t *testing.T) {
+	t.Parallel()
+
+	// Give the job a name which differs from its ID.
+	packDir := testfixture.Clone(t, "v2/test_registry/packs/simple_raw_exec")
+	tplPath := filepath.Join(packDir, "templates", "simple_raw_exec.nomad.tpl")
+	tpl, err := os.ReadFile(tplPath)
+	must.NoError(t, err)
+	tpl = bytes.Replace(tpl, []byte(`type = "service"`), []byte("name = \"display_name\"\n  type = \"service\""), 1)
+	must.NoError(t, os.WriteFile(tplPath, tpl, 0o644))
+
+	caseDir := filepath.Join(packDir, "tests", "by_id")
+	must.NoError(t, os.MkdirAll(caseDir, 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(caseDir, "assertions.hcl"), []byte(`
+assert "targets_id" {
+  job       = "simple_raw_exec"
+  condition = groups.app.Count == 1
+}
+`), 0o644))
+
+	result := runPackCmd(t, []string{"test", "--unit", packDir})
+	must.Zero(t, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+}
+
+func TestCLI_PackTest(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		client, err := ct.NewTestClient(s)
+		must.NoError(t, err)
+
+		packDir := testfixture.Clone(t, "v2/test_registry/packs/simple_raw_exec")
+		testsDir := filepath.Join(packDir, "templates", "tests")
+		must.NoError(t, os.MkdirAll(testsDir, 0o755))
+
+		testJobTpl := `job %q {
+			datacenters = ["dc1"]
+			type        = "batch"
+
+			group "check" {
+				restart {
+					attempts = 0
+					mode     = "fail"
+				}
+
+				reschedule {
+					attempts  = 0
+					unlimited = false
+				}
+
+				task "check" {
+					driver = "raw_exec"
+
+					config {
+						command = "/bin/sh"
+						args    = ["-c", %q]
+					}
+				}
+			}
+		}
+		`
+		passTpl := filepath.Join(testsDir, "pass.nomad.tpl")
+		must.NoError(t, os.WriteFile(passTpl, []byte(fmt.Sprintf(testJobTpl, "pack_test_pass", "echo pack-test-ok")), 0o644))
+
+		// There is nothing to test until the pack is running.
+		result := runTestPackCmd(t, s, []string{"test", packDir})
+		must.Eq(t, 1, result.exitCode)
+		must.StrContains(t, result.cmdOut.String(), fmt.Sprintf("no running jobs found for deployment %q", testPack))
+
+		expectGoodPackDeploy(t, runTestPackCmd(t, s, []string{"run", packDir}))
+		must.Wait(t, wait.InitialSuccess(
+			wait.BoolFunc(func() bool {
+				j, _, err := client.Jobs().Info(testPack, &api.QueryOptions{})
+				return err == nil && *j.Status == "running"
+			}),
+			wait.Timeout(30*time.Second),
+			wait.Gap(500*time.Millisecond),
+		), must.Sprint("simple_raw_exec is not running"))
+
+		// The test is dispatched, its logs are streamed, and its jobs are
+		// purged once it has passed.
+		result = runTestPackCmd(t, s, []string{"test", packDir})
+		must.Zero(t, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+		out := result.cmdOut.String()
+		must.StrContains(t, out, "Test job 'pack_test_pass' dispatched as 'pack_test_pass/dispatch-")
+		must.StrContains(t, out, "pack-test-ok")
+		must.StrContains(t, out, fmt.Sprintf("All tests of pack deployment '%s' passed", testPack))
+
+		_, _, err = client.Jobs().Info("pack_test_pass", &api.QueryOptions{})
+		must.ErrorContains(t, err, "not found")
+
+		// A failing test fails the command, and its dispatched job is left
+		// for inspection.
+		must.NoError(t, os.Remove(passTpl))
+		failTpl := filepath.Join(testsDir, "fail.nomad.tpl")
+		must.NoError(t, os.WriteFile(failTpl, []byte(fmt.Sprintf(testJobTpl, "pack_test_fail", "exit 1")), 0o644))
+
+		result = runTestPackCmd(t, s, []string{"test", packDir})
+		must.Eq(t, 1, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+		out = result.cmdOut.String()
+		must.StrContains(t, out, "Test job 'pack_test_fail' failed")
+		must.StrContains(t, out, fmt.Sprintf("1 of 1 tests of pack deployment '%s' failed", testPack))
+
+		children, _, err := client.Jobs().PrefixList("pack_test_fail/dispatch-")
+		must.NoError(t, err)
+		must.Len(t, 1, children)
+	})
+}
+
 func TestCLI_PackRender_RootVar(t *testing.T) {
 	t.Parallel()
 	// This test has to do some extra shenanigans because dependent pack template
@@ -895,6 +1187,56 @@ func TestCLI_PackRender_VarsInOutputTemplate(t *testing.T) {
 	must.SliceContainsAll(t, expected, elems, must.Sprintf("unexpected returned value.\nexpected: %v\nelems: %v\nstdout:\n%v\n", expected, elems, result.cmdOut.String()))
 }
 
+func TestCLI_PackRender_CompareTo(t *testing.T) {
+	t.Parallel()
+	outDir := t.TempDir()
+
+	result := runPackCmd(t, []string{
+		"render",
+		"--no-format=true",
+		"--to-dir", outDir,
+		getTestPackPath(t, "my_alias_test"),
+	})
+	must.Eq(t, 0, result.exitCode, must.Sprintf("incorrect exit code.\nstdout:\n%v\nstderr:%v\n", result.cmdOut.String(), result.cmdErr.String()))
+
+	// Rendering the pack with the same variables matches the previous render.
+	result = runPackCmd(t, []string{
+		"render",
+		"--no-format=true",
+		"--compare-to", outDir,
+		getTestPackPath(t, "my_alias_test"),
+	})
+	must.Eq(t, 0, result.exitCode, must.Sprintf("incorrect exit code.\nstdout:\n%v\nstderr:%v\n", result.cmdOut.String(), result.cmdErr.String()))
+	must.StrContains(t, result.cmdOut.String(), "Rendered files match")
+
+	// Changing a variable is reported as a diff of the affected file only.
+	result = runPackCmd(t, []string{
+		"render",
+		"--no-format=true",
+		"--var", "child1.job_name=override",
+		"--compare-to", outDir,
+		getTestPackPath(t, "my_alias_test"),
+	})
+	must.Eq(t, 1, result.exitCode, must.Sprintf("incorrect exit code.\nstdout:\n%v\nstderr:%v\n", result.cmdOut.String(), result.cmdErr.String()))
+	must.StrContains(t, result.cmdOut.String(), "+++ deps_test/child1/child1.nomad")
+	must.StrContains(t, result.cmdOut.String(), "-child1")
+	must.StrContains(t, result.cmdOut.String(), "+override")
+	must.StrNotContains(t, result.cmdOut.String(), "child2.nomad")
+}
+
+func TestCLI_PackLint(t *testing.T) {
+	t.Parallel()
+
+	// The pack only has warnings, so passes unless --strict is used.
+	result := runPackCmd(t, []string{"lint", getTestPackPath(t, "simple_raw_exec")})
+	must.Eq(t, 0, result.exitCode, must.Sprintf("incorrect exit code.\nstdout:\n%v\nstderr:%v\n", result.cmdOut.String(), result.cmdErr.String()))
+	must.StrContains(t, result.cmdOut.String(), "deprecated-metadata")
+	must.StrContains(t, result.cmdOut.String(), "missing-file")
+
+	result = runPackCmd(t, []string{"lint", "--strict", getTestPackPath(t, "simple_raw_exec")})
+	must.Eq(t, 1, result.exitCode, must.Sprintf("incorrect exit code.\nstdout:\n%v\nstderr:%v\n", result.cmdOut.String(), result.cmdErr.String()))
+}
+
 func TestCLI_CLIFlag_Namespace(t *testing.T) {
 	testCases := []struct {
 		desc   string
@@ -1206,6 +1548,19 @@ func expectGoodPackDeploy(t *testing.T, r PackCommandResult) {
 	must.Zero(t, r.exitCode)
 }
 
+// decodeJSONEvents decodes the newline-delimited events output by a command
+// run with --json.
+func decodeJSONEvents(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		must.NoError(t, json.Unmarshal([]byte(line), &event), must.Sprintf("invalid event %q", line))
+		events = append(events, event)
+	}
+	return events
+}
+
 // expectGoodPackPlan bundles the test expectations that should be met when
 // determining if the pack CLI successfully planned a pack.
 func expectGoodPackPlan(t *testing.T, r PackCommandResult) {
@@ -1448,3 +1803,103 @@ func TestCLI_RunReconcileIgnoresOtherDeployments(t *testing.T) {
 		must.NotEq(t, "dead", *j.Status)
 	})
 }
+
+// TestCLI_RunRecordsReleaseAndRollback verifies that each successful run
+// records a release and that rollback re-registers the jobs of an earlier
+// release.
+func TestCLI_RunRecordsReleaseAndRollback(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		client, err := ct.NewTestClient(s)
+		must.NoError(t, err)
+
+		result := runTestPackCmd(t, s, []string{"run", getTestPackPath(t, testPack), "--name=rollback", "--var=count=1"})
+		expectGoodPackDeploy(t, result)
+		must.StrContains(t, result.cmdOut.String(), `Recorded release 1 of deployment "rollback"`)
+
+		result = runTestPackCmd(t, s, []string{"run", getTestPackPath(t, testPack), "--name=rollback", "--var=count=2"})
+		expectGoodPackDeploy(t, result)
+		must.StrContains(t, result.cmdOut.String(), `Recorded release 2 of deployment "rollback"`)
+
+		j, _, err := client.Jobs().Info(testPack, &api.QueryOptions{})
+		must.NoError(t, err)
+		must.Eq(t, 2, *j.TaskGroups[0].Count)
+
+		store := release.NewStore(client, "")
+		releases, err := store.List("rollback")
+		must.NoError(t, err)
+		must.Len(t, 2, releases)
+		must.Eq[any](t, float64(1), releases[0].Variables[testPack+".count"])
+		must.Eq[any](t, float64(2), releases[1].Variables[testPack+".count"])
+		must.Eq(t, []string{testPack}, releases[1].JobIDs())
+
+		// The jobs of the release are checked against the policy rules
+		// before they are re-registered.
+		policyDir := t.TempDir()
+		must.NoError(t, os.WriteFile(filepath.Join(policyDir, "count.hcl"), []byte(`
+rule "min_count" {
+  condition = groups.app.Count >= 2
+  message   = "Groups must have at least two instances."
+}
+`), 0o644))
+		result = runTestPackCmd(t, s, []string{"rollback", "rollback", "--policy-dir=" + policyDir})
+		must.Eq(t, 1, result.exitCode)
+		must.StrContains(t, result.cmdOut.String(), "Groups must have at least two instances.")
+
+		j, _, err = client.Jobs().Info(testPack, &api.QueryOptions{})
+		must.NoError(t, err)
+		must.Eq(t, 2, *j.TaskGroups[0].Count)
+
+		// Without --to, rollback targets the revision before the latest.
+		result = runTestPackCmd(t, s, []string{"rollback", "rollback"})
+		must.Zero(t, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+		must.StrContains(t, result.cmdOut.String(), `successfully rolled back to revision 1`)
+
+		j, _, err = client.Jobs().Info(testPack, &api.QueryOptions{})
+		must.NoError(t, err)
+		must.Eq(t, 1, *j.TaskGroups[0].Count)
+		must.Eq(t, "rollback", j.Meta[job.PackDeploymentNameKey])
+
+		latest, err := store.Latest("rollback")
+		must.NoError(t, err)
+		must.Eq(t, 3, latest.Revision)
+		must.Eq(t, 1, latest.RollbackOf)
+
+		// Rolling back to a revision that does not exist fails.
+		result = runTestPackCmd(t, s, []string{"rollback", "rollback", "--to=42"})
+		must.Eq(t, 1, result.exitCode)
+		must.StrContains(t, result.cmdOut.String(), release.ErrNotFound.Error())
+	})
+}
+
+func TestCLI_History(t *testing.T) {
+	ct.HTTPTestParallel(t, ct.WithDefaultConfig(), func(s *agent.TestAgent) {
+		result := runTestPackCmd(t, s, []string{"history", testPack, "--name=history"})
+		must.Zero(t, result.exitCode)
+		must.StrContains(t, result.cmdOut.String(), `no releases found for pack "simple_raw_exec" in deployment "history"`)
+
+		result = runTestPackCmd(t, s, []string{"run", getTestPackPath(t, testPack), "--name=history", "--var=count=1"})
+		expectGoodPackDeploy(t, result)
+
+		result = runTestPackCmd(t, s, []string{"run", getTestPackPath(t, testPack), "--name=history", "--var=count=2"})
+		expectGoodPackDeploy(t, result)
+
+		// Without --name, deployments are discovered from the registered jobs.
+		result = runTestPackCmd(t, s, []string{"history", testPack})
+		must.Zero(t, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+		out := result.cmdOut.String()
+		must.StrContains(t, out, "superseded")
+		must.StrContains(t, out, "current")
+		must.StrContains(t, out, testPack+"@v0")
+		must.StrContains(t, out, testPack+"@v1")
+
+		result = runTestPackCmd(t, s, []string{"history", testPack, "--name=history", "--json"})
+		must.Zero(t, result.exitCode, must.Sprintf("unexpected output: %s", result.cmdOut.String()))
+		out = result.cmdOut.String()
+		must.StrContains(t, out, `"type":"release"`)
+		must.StrContains(t, out, `"deployment_name":"history"`)
+		must.StrContains(t, out, `"revision":2`)
+		must.StrContains(t, out, `"superseded":true`)
+		must.StrContains(t, out, `"superseded":false`)
+		must.StrContains(t, out, `"type":"result"`)
+	})
+}
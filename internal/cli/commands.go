@@ -11,6 +11,7 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/api"
@@ -30,6 +31,18 @@ const (
 	EnvPlanExitCodeNoChanges    = "NOMAD_PACK_PLAN_EXIT_CODE_NO_CHANGES"
 	EnvPlanExitCodeMakesChanges = "NOMAD_PACK_PLAN_EXIT_CODE_MAKES_CHANGES"
 	EnvPlanExitCodeError        = "NOMAD_PACK_PLAN_EXIT_CODE_ERROR"
+
+	// OCI registry credential environment variables
+	EnvRegistryUsername = "NOMAD_PACK_REGISTRY_USERNAME"
+	EnvRegistryPassword = "NOMAD_PACK_REGISTRY_PASSWORD"
+
+	// Registry signature environment variables
+	EnvTrustedKeys       = "NOMAD_PACK_TRUSTED_KEYS"
+	EnvSigningPassphrase = "NOMAD_PACK_SIGNING_KEY_PASSPHRASE"
+
+	// EnvVarSourceCacheKey is the env var holding the key cached external
+	// variable source values are encrypted with.
+	EnvVarSourceCacheKey = "NOMAD_PACK_VAR_SOURCE_CACHE_KEY"
 )
 
 // baseCommand is embedded in all commands to provide common logic and data.
@@ -61,6 +74,11 @@ type baseCommand struct {
 	// flagPlain is whether the output should be in plain mode.
 	flagPlain bool
 
+	// flagJSON and outputFormat select the JSON output mode, in which the
+	// command outputs newline-delimited JSON events rather than text.
+	flagJSON     bool
+	outputFormat string
+
 	// vars sets values for defined input variables
 	vars map[string]string
 
@@ -72,9 +90,24 @@ type baseCommand struct {
 	varFiles []string
 
 	// varSources is a list of external variable source URLs
-	// (e.g., consul:///path)
+	// (e.g., consul:///path or file:///path/vars.json)
 	varSources []string
 
+	// varSourceCache caches the values read from external variable sources,
+	// reusing them for varSourceCacheTTL. offline renders only from the
+	// cached values, without contacting the sources.
+	varSourceCache    bool
+	varSourceCacheTTL time.Duration
+	offline           bool
+
+	// varSourceTimeout is the maximum time each external variable source
+	// may take to read the variables of a pack
+	varSourceTimeout time.Duration
+
+	// policyDir is the directory of policy rules the rendered jobs are
+	// checked against before they are planned or run
+	policyDir string
+
 	// allowUnsetVars suppresses errors from variables with nil values,
 	// i.e. those that are not set and have no default
 	allowUnsetVars bool
@@ -173,6 +206,11 @@ type nomadConfig struct {
 	clientKey     string
 }
 
+// jsonOutput returns whether the command outputs JSON events rather than text.
+func (c *baseCommand) jsonOutput() bool {
+	return c.flagJSON || c.outputFormat == outputFormatJSON
+}
+
 // Init initializes the command by parsing flags, parsing the configuration,
 // setting up the project, etc. You can control what is done by using the
 // options.
@@ -228,6 +266,19 @@ func (c *baseCommand) Init(opts ...Option) error {
 		c.ui = terminal.NonInteractiveUI(c.Ctx)
 	}
 
+	// Reset the UI to JSON if that was set. The events are written to the
+	// stdout of the UI being replaced, which is closed as it is not needed.
+	if c.jsonOutput() {
+		stdout, _, err := c.ui.OutputWriters()
+		if err != nil {
+			return err
+		}
+		if closer, ok := c.ui.(io.Closer); ok {
+			closer.Close()
+		}
+		c.ui = terminal.JSONUI(stdout)
+	}
+
 	// Perform the cache ensure, but skip if we are running the version
 	// command.
 	if c.cmdKey != "version" {
@@ -282,10 +333,10 @@ func (c *baseCommand) flagSet(bit flagSetBit, f func(*flag.Sets)) *flag.Sets {
 				Target:  &c.varSources,
 				Default: make([]string, 0),
 				Usage: `Specifies an external variable source as a URL, and may be
-						given more than once to read from several sources. Three source
+						given more than once to read from several sources. Five source
 						types are supported. Consul KV uses the form
 						consul://<host>:<port>/<path>; for example,
-						consul://localhost:8500/nomad-pack. Vault KV v2 uses the form
+						consul://localhost:8500/nomad-pack. Vault uses the form
 						vault://<host>:<port>/<mount>/<path>; for example,
 						vault://localhost:8200/secret/nomad-pack. Nomad Variables use
 						the form nomad://<host>:<port>/<path>; for example,
@@ -298,13 +349,65 @@ func (c *baseCommand) flagSet(bit flagSetBit, f func(*flag.Sets)) *flag.Sets {
 						such as a Nomad task's API socket, omit the host
 						(nomad:///<path>) and set NOMAD_ADDR to the socket. For Consul
 						each variable is read from <path>/<variable-name>; for Vault each
-						variable is a field of the secret at <mount>/<path>; for Nomad
-						each variable is an item of the Nomad Variable at <path>.
-						External sources rank below local input: values from --var,
+						variable is a field of the secret at <mount>/<path>, where the
+						engine query parameter selects kv2 (the default), kv1 or logical,
+						and each path parameter merges another secret of the mount; for Nomad
+						each variable is an item of the Nomad Variable at <path>. A
+						local HCL, JSON or YAML document uses the form file:///<path>,
+						and a document served over HTTP uses its http:// or https://
+						URL; each top-level attribute or key of the document is a
+						variable, and the format is taken from the file extension or
+						the Content-Type of the response. Any {pack} in a source path
+						is replaced with the ID of the pack being resolved, with the
+						IDs of dependency packs written as <parent>/<dependency>. Prefix
+						the URL with a pack ID and "=", such as
+						my_pack.redis=consul:///redis, to use a source only for that
+						pack. External sources rank below local input: values from --var,
 						--var-file, and the environment all override them. When more
 						than one --var-source provides the same variable, the one given
 						later on the command line wins.`,
 			})
+
+			f.DurationVar(&flag.DurationVar{
+				Name:    "var-source-timeout",
+				Target:  &c.varSourceTimeout,
+				Default: 30 * time.Second,
+				Usage: `The maximum time each --var-source may take to read the
+						variables of a pack. Sources, and the pack and each of its
+						dependencies, are read concurrently, so a source which
+						times out fails without delaying the others, and this also
+						bounds the time taken to read them all.`,
+			})
+
+			f.BoolVar(&flag.BoolVar{
+				Name:    "var-source-cache",
+				Target:  &c.varSourceCache,
+				Default: false,
+				Usage: fmt.Sprintf(`Cache the values read from each --var-source on disk, so
+						they can be replayed with --offline. Values of Vault and Nomad
+						sources are only cached encrypted, with a key derived from the
+						%s environment variable, which must then be set;
+						the values of other sources are encrypted when it is set.`,
+					EnvVarSourceCacheKey),
+			})
+
+			f.DurationVar(&flag.DurationVar{
+				Name:    "var-source-cache-ttl",
+				Target:  &c.varSourceCacheTTL,
+				Default: 0,
+				Usage: `Reuse the cached values of a --var-source for this long
+						after they were read, instead of reading them again. Implies
+						--var-source-cache.`,
+			})
+
+			f.BoolVar(&flag.BoolVar{
+				Name:    "offline",
+				Target:  &c.offline,
+				Default: false,
+				Usage: `Render from the values last cached for each --var-source,
+						however old, without contacting the sources. Fails if any
+						source has no cached values for a pack.`,
+			})
 		}
 
 		f.BoolVar(&flag.BoolVar{
@@ -359,6 +462,45 @@ func (c *baseCommand) flagSet(bit flagSetBit, f func(*flag.Sets)) *flag.Sets {
 			migrating them to the new syntax`,
 		})
 	}
+	if bit&flagSetPolicy != 0 {
+		f := set.NewSet("Policy Options")
+		f.StringVar(&flag.StringVar{
+			Name:    "policy-dir",
+			Target:  &c.policyDir,
+			Default: "",
+			Usage: `Specifies a directory of policy files, whose rules every
+					job must satisfy before it is planned, run, or rolled back
+					to. Each .hcl file in the directory may contain rule blocks
+					with a condition expression evaluated against the job, an
+					optional message, and an optional level of "error" or
+					"warning". An error level violation stops the command, while
+					a warning is reported but allows the command to continue.`,
+			Completion: complete.PredictDirs(""),
+		})
+	}
+	if bit&flagSetOutput != 0 {
+		f := set.NewSet("Output Options")
+		f.BoolVar(&flag.BoolVar{
+			Name:    "json",
+			Target:  &c.flagJSON,
+			Default: false,
+			Usage: `Output newline-delimited JSON events rather than text.
+					Equivalent to --output=json.`,
+		})
+
+		f.EnumSingleVar(&flag.EnumSingleVar{
+			Name:    "output",
+			Target:  &c.outputFormat,
+			Values:  []string{outputFormatText, outputFormatJSON},
+			Default: outputFormatText,
+			Usage: `The format of the command's output. The json format
+					outputs one JSON object per line for each message, along
+					with events for registered jobs, plans, deployment status,
+					and the final result of the command.`,
+			Completion: complete.PredictSet(outputFormatText, outputFormatJSON),
+		})
+	}
+
 	if bit&flagSetNeedsApproval != 0 {
 		f := set.NewSet("Approval Options")
 		f.BoolVarP(&flag.BoolVarP{
@@ -480,6 +622,14 @@ const (
 	flagSetNeedsApproval                             // adds the -y flag for commands that require approval to run
 	flagSetNomadClient                               // adds client config flags
 	flagSetExternalVarSources                        // adds --var-source; only for commands that compute a fresh deployment (run, plan, render)
+	flagSetPolicy                                    // adds --policy-dir for commands that submit jobs to Nomad (run, plan, rollback)
+	flagSetOutput                                    // adds --json and --output for commands with machine-readable output (run, plan, destroy, stop, history)
+)
+
+// The formats accepted by the --output flag.
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
 )
 
 var (
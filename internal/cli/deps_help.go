@@ -24,11 +24,11 @@ func (d *depsHelpCommand) Run(args []string) int {
 		WithNoConfig(),
 		WithClient(false),
 	); err != nil {
-		d.ui.Info("The deps command requires the following subcommand: vendor.")
+		d.ui.Info("The deps command requires one of the following subcommands: vendor, tree, outdated.")
 		return 1
 	}
 
-	d.ui.Info("The deps command requires the following subcommand: vendor.")
+	d.ui.Info("The deps command requires one of the following subcommands: vendor, tree, outdated.")
 	return 0
 }
 
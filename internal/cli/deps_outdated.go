@@ -0,0 +1,141 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/deps"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// depsOutdatedCommand reports the dependencies of a pack for which newer
+// versions are available at their sources.
+type depsOutdatedCommand struct {
+	*baseCommand
+	packConfig *caching.PackConfig
+	seconds    int
+}
+
+func (d *depsOutdatedCommand) Run(args []string) int {
+	d.cmdKey = "deps outdated"
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := d.Init(
+		WithExactArgs(1, args),
+		WithFlags(d.Flags()),
+		WithNoConfig(),
+		WithClient(false),
+	); err != nil {
+		d.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		d.ui.Info(d.helpUsageMessage())
+		return 1
+	}
+
+	d.packConfig.Name = d.args[0]
+
+	// Set the packConfig defaults if necessary and generate our UI error context.
+	errorContext := initPackCommand(d.packConfig)
+
+	if err := caching.VerifyPackExists(d.packConfig, errorContext, d.ui); err != nil {
+		return 1
+	}
+
+	timeout := time.Duration(d.seconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	outdated, err := deps.Outdated(ctx, d.packConfig.Path)
+	if err != nil {
+		d.ui.ErrorWithContext(err, "failed to check dependencies", errorContext.GetAll()...)
+		return 1
+	}
+
+	if len(outdated) == 0 {
+		d.ui.Success(fmt.Sprintf("All dependencies of pack %q are up to date", d.packConfig.Name))
+		return 0
+	}
+
+	tbl := terminal.NewTable("Dependency", "Current", "Latest", "Source")
+	for _, dep := range outdated {
+		tbl.Rows = append(tbl.Rows, []string{dep.Name, dep.Current, dep.Latest, dep.Source})
+	}
+	d.ui.Table(tbl)
+	return 0
+}
+
+func (d *depsOutdatedCommand) Flags() *flag.Sets {
+	return d.flagSet(0, func(set *flag.Sets) {
+		d.packConfig = &caching.PackConfig{}
+
+		f := set.NewSet("Outdated Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "registry",
+			Target:  &d.packConfig.Registry,
+			Default: "",
+			Usage: `Specific registry name containing the pack. If not
+					specified, the default registry will be used.`,
+		})
+
+		f.StringVar(&flag.StringVar{
+			Name:    "ref",
+			Target:  &d.packConfig.Ref,
+			Default: "",
+			Usage: `Specific git ref of the pack. Supports tags, SHA, and
+					latest. If no ref is specified, defaults to latest.
+
+					Using ref with a file path is not supported.`,
+		})
+
+		f.IntVar(&flag.IntVar{
+			Name:    "timeout",
+			Target:  &d.seconds,
+			Default: 30,
+			Usage:   `Timeout (in seconds) for listing the refs of dependency sources.`,
+		})
+	})
+}
+
+func (d *depsOutdatedCommand) AutocompleteArgs() complete.Predictor {
+	return predictPackName
+}
+
+func (d *depsOutdatedCommand) AutocompleteFlags() complete.Flags {
+	return d.Flags().Completions()
+}
+
+func (d *depsOutdatedCommand) Synopsis() string {
+	return "List the dependencies of a pack with newer versions available."
+}
+
+func (d *depsOutdatedCommand) Help() string {
+	d.Example = `
+	# List the outdated dependencies of the pack in the current directory
+	nomad-pack deps outdated .
+	`
+
+	return formatHelp(`
+	Usage: nomad-pack deps outdated <pack-name> [options]
+
+	List the dependencies of a pack, and of its vendored dependencies, for
+	which newer versions are available at their git sources.
+
+	A dependency pinned to a version, by a tag ref or the version its
+	constraint resolved to in pack.lock.hcl, is outdated when its source has
+	a tag with a higher version. Any other dependency follows a branch, or the
+	default branch, and is outdated when the head of that branch differs from
+	its locked commit. Dependencies which are disabled, or do not have a git
+	source, are not checked.
+
+	Run "nomad-pack deps vendor --upgrade" to update outdated dependencies.
+
+` + d.GetExample() + d.Flags().Help())
+}
@@ -0,0 +1,122 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/deps"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/manager"
+)
+
+// depsTreeCommand prints the tree of dependencies of a pack, as loaded for
+// rendering.
+type depsTreeCommand struct {
+	*baseCommand
+	packConfig *caching.PackConfig
+}
+
+func (d *depsTreeCommand) Run(args []string) int {
+	d.cmdKey = "deps tree"
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := d.Init(
+		WithExactArgs(1, args),
+		WithFlags(d.Flags()),
+		WithNoConfig(),
+		WithClient(false),
+	); err != nil {
+		d.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		d.ui.Info(d.helpUsageMessage())
+		return 1
+	}
+
+	d.packConfig.Name = d.args[0]
+
+	// Set the packConfig defaults if necessary and generate our UI error context.
+	errorContext := initPackCommand(d.packConfig)
+
+	if err := caching.VerifyPackExists(d.packConfig, errorContext, d.ui); err != nil {
+		return 1
+	}
+
+	packManager := manager.NewPackManager(&manager.Config{Path: d.packConfig.Path}, nil)
+
+	p, err := packManager.LoadPack()
+	if err != nil {
+		d.ui.ErrorWithContext(err, "failed to load pack", errorContext.GetAll()...)
+		return 1
+	}
+
+	lock, err := deps.ReadLockFile(d.packConfig.Path)
+	if err != nil {
+		d.ui.ErrorWithContext(err, "failed to read dependency lock file", errorContext.GetAll()...)
+		return 1
+	}
+
+	d.ui.Output(deps.FormatTree(deps.Tree(p, lock)))
+	return 0
+}
+
+func (d *depsTreeCommand) Flags() *flag.Sets {
+	return d.flagSet(0, func(set *flag.Sets) {
+		d.packConfig = &caching.PackConfig{}
+
+		f := set.NewSet("Tree Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "registry",
+			Target:  &d.packConfig.Registry,
+			Default: "",
+			Usage: `Specific registry name containing the pack. If not
+					specified, the default registry will be used.`,
+		})
+
+		f.StringVar(&flag.StringVar{
+			Name:    "ref",
+			Target:  &d.packConfig.Ref,
+			Default: "",
+			Usage: `Specific git ref of the pack. Supports tags, SHA, and
+					latest. If no ref is specified, defaults to latest.
+
+					Using ref with a file path is not supported.`,
+		})
+	})
+}
+
+func (d *depsTreeCommand) AutocompleteArgs() complete.Predictor {
+	return predictPackName
+}
+
+func (d *depsTreeCommand) AutocompleteFlags() complete.Flags {
+	return d.Flags().Completions()
+}
+
+func (d *depsTreeCommand) Synopsis() string {
+	return "Print the dependency tree of a pack."
+}
+
+func (d *depsTreeCommand) Help() string {
+	d.Example = `
+	# Print the dependency tree of the pack in the current directory
+	nomad-pack deps tree .
+
+	# Print the dependency tree of the "hello_world" pack from the default
+	# registry
+	nomad-pack deps tree hello_world
+	`
+
+	return formatHelp(`
+	Usage: nomad-pack deps tree <pack-name> [options]
+
+	Print the tree of dependencies of a pack. Dependencies are shown by the
+	name the pack refers to them by, along with the pack they are an alias of.
+	The version and commit recorded in pack.lock.hcl are shown for vendored
+	dependencies. Disabled dependencies are shown, but are not loaded, so
+	their own dependencies are not.
+
+` + d.GetExample() + d.Flags().Help())
+}
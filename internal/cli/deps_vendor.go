@@ -18,6 +18,7 @@ type depsVendorCommand struct {
 	*baseCommand
 	targetPath string
 	seconds    int
+	upgrade    bool
 }
 
 func (d *depsVendorCommand) Run(args []string) int {
@@ -43,7 +44,7 @@ func (d *depsVendorCommand) Run(args []string) int {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	err := deps.Vendor(ctx, d.ui, d.targetPath)
+	err := deps.Vendor(ctx, d.ui, d.targetPath, d.upgrade)
 	if err != nil {
 		d.ui.ErrorWithContext(err, "failed to vendor dependencies", errorContext.GetAll()...)
 		return 1
@@ -70,6 +71,15 @@ func (d *depsVendorCommand) Flags() *flag.Sets {
 			Default: 30,
 			Usage:   `Timeout (in seconds) for downloading dependencies.`,
 		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "upgrade",
+			Target:  &d.upgrade,
+			Default: false,
+			Usage: `Resolve every dependency again from its source and ref,
+					ignoring the commits and hashes recorded in pack.lock.hcl,
+					and update the lock file with the result.`,
+		})
 	})
 }
 
@@ -91,5 +101,16 @@ func (d *depsVendorCommand) Help() string {
 
 	Vendor dependencies for a pack in the current directory.
 
+	Dependencies declared by the vendored packs are vendored in turn, into
+	the deps directory of the pack which declares them, and the resulting
+	tree of dependencies is output. A source shared by several dependencies
+	is only downloaded once.
+
+	The resolved commit and content hash of each dependency are recorded in
+	the pack.lock.hcl file of the pack. Later runs of this command download
+	the locked commits and fail if their content does not match the locked
+	hashes, unless --upgrade is passed. Rendering a pack also checks its
+	vendored dependencies against the lock file.
+
 ` + d.GetExample() + d.Flags().Help())
 }
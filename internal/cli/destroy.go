@@ -39,7 +39,7 @@ func (c *DestroyCommand) Run(args []string) int {
 }
 
 func (c *DestroyCommand) Flags() *flag.Sets {
-	return c.flagSet(flagSetOperation|flagSetNomadClient, func(set *flag.Sets) {
+	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetOutput, func(set *flag.Sets) {
 		c.packConfig = &caching.PackConfig{}
 
 		set.HideUnusedFlags("Operation Options", []string{"var", "var-file"})
@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/ryanuber/columnize"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/deps"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
 )
 
 // formatList takes a set of strings and formats them into properly
@@ -86,3 +89,35 @@ func prettyTimeDiff(first, second time.Time) string {
 		return fmt.Sprintf("%dd%dh ago", days, hours)
 	}
 }
+
+// formatDependency returns the info row of a dependency, including the version
+// and commit it is locked to, if any.
+func formatDependency(dep *pack.Dependency, locked *deps.LockedDependency) string {
+	var details []string
+	if dep.Source != "" {
+		details = append(details, "source: "+dep.Source)
+	}
+	if dep.Ref != "" {
+		details = append(details, "ref: "+dep.Ref)
+	}
+	if dep.Version != "" {
+		details = append(details, "version: "+dep.Version)
+	}
+	if locked != nil {
+		if locked.Version != "" {
+			details = append(details, "locked version: "+locked.Version)
+		}
+		if locked.Commit != "" {
+			details = append(details, "locked commit: "+locked.Commit)
+		}
+	}
+	if dep.Enabled != nil && !*dep.Enabled {
+		details = append(details, "disabled")
+	}
+
+	row := fmt.Sprintf("\t- %q", dep.Name)
+	if len(details) > 0 {
+		row += " (" + strings.Join(details, ", ") + ")"
+	}
+	return row
+}
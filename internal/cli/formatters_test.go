@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/deps"
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper/pointer"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
 )
 
 func Test_FormatList(t *testing.T) {
@@ -168,6 +172,38 @@ func Test_PrettyTimeDiff(t *testing.T) {
 	}
 }
 
+func Test_FormatDependency(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dep      *pack.Dependency
+		locked   *deps.LockedDependency
+		expected string
+	}{
+		{
+			name:     "local",
+			dep:      &pack.Dependency{Name: "helpers"},
+			expected: "\t- \"helpers\"",
+		},
+		{
+			name:     "unlocked version",
+			dep:      &pack.Dependency{Name: "helpers", Source: "git::https://example.com/packs//helpers", Version: "~> 1.2"},
+			expected: "\t- \"helpers\" (source: git::https://example.com/packs//helpers, version: ~> 1.2)",
+		},
+		{
+			name:     "locked version",
+			dep:      &pack.Dependency{Name: "helpers", Source: "./helpers", Version: "~> 1.2", Enabled: pointer.Of(false)},
+			locked:   &deps.LockedDependency{Name: "helpers", Version: "1.2.3", Commit: "abc123"},
+			expected: "\t- \"helpers\" (source: ./helpers, version: ~> 1.2, locked version: 1.2.3, locked commit: abc123, disabled)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			must.Eq(t, tc.expected, formatDependency(tc.dep, tc.locked))
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && findSubstring(s, substr)))
@@ -8,15 +8,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/hashicorp/nomad/api"
 	"github.com/posener/complete"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
 	"github.com/hashicorp/nomad-pack/internal/pkg/manager"
+	"github.com/hashicorp/nomad-pack/internal/pkg/planfile"
+	"github.com/hashicorp/nomad-pack/internal/pkg/policy"
+	"github.com/hashicorp/nomad-pack/internal/pkg/release"
 	"github.com/hashicorp/nomad-pack/internal/pkg/renderer"
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature"
 	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser"
 	"github.com/hashicorp/nomad-pack/internal/pkg/variable/source"
 	"github.com/hashicorp/nomad-pack/internal/runner"
@@ -42,6 +51,16 @@ func initPackCommand(cfg *caching.PackConfig) (errorContext *errors.UIErrorConte
 
 // generatePackManager is used to generate the pack manager for this Nomad Pack run.
 func generatePackManager(c *baseCommand, client *api.Client, packCfg *caching.PackConfig) (*manager.PackManager, error) {
+	cfg, err := packManagerConfig(c, packCfg)
+	if err != nil {
+		return nil, err
+	}
+	return manager.NewPackManager(cfg, client), nil
+}
+
+// packManagerConfig returns the pack manager configuration for the pack and
+// the variable flags of the command.
+func packManagerConfig(c *baseCommand, packCfg *caching.PackConfig) (*manager.Config, error) {
 	// Parse external variable source configurations if provided.
 	var externalSourceConfigs []source.SourceConfig
 	if len(c.varSources) > 0 {
@@ -52,6 +71,13 @@ func generatePackManager(c *baseCommand, client *api.Client, packCfg *caching.Pa
 		externalSourceConfigs = configs
 	}
 
+	if c.varSourceCache || c.varSourceCacheTTL > 0 || c.offline {
+		cache := source.NewSourceCache(varSourceCachePath(), c.varSourceCacheTTL, c.offline, os.Getenv(EnvVarSourceCacheKey))
+		for i, cfg := range externalSourceConfigs {
+			externalSourceConfigs[i] = source.WithCache(cfg, cache)
+		}
+	}
+
 	// TODO: Refactor to have manager use cache.
 	cfg := manager.Config{
 		Path:                  packCfg.Path,
@@ -61,8 +87,16 @@ func generatePackManager(c *baseCommand, client *api.Client, packCfg *caching.Pa
 		AllowUnsetVars:        c.allowUnsetVars,
 		UseParserV1:           c.useParserV1,
 		ExternalSourceConfigs: externalSourceConfigs,
+		ExternalSourceTimeout: c.varSourceTimeout,
 	}
-	return manager.NewPackManager(&cfg, client), nil
+	return &cfg, nil
+}
+
+// varSourceCachePath returns the directory the values of external variable
+// sources are cached in. It is beside the pack cache rather than in it, where
+// it would be taken for a registry.
+func varSourceCachePath() string {
+	return filepath.Join(filepath.Dir(caching.DefaultCachePath()), "pack-var-sources")
 }
 
 // predictPackName is a complete.Predictor that suggests cached pack names.
@@ -173,6 +207,16 @@ func registryName(cr *caching.Registry) string {
 	return fmt.Sprintf("%s@%s (%s)", cr.Name, formatSHA1Reference(cr.Ref), formatSHA1Reference(cr.LocalRef))
 }
 
+// trustedKeys reads the public keys configured with the NOMAD_PACK_TRUSTED_KEYS
+// environment variable, which registries must be signed with when set.
+func trustedKeys() (openpgp.EntityList, error) {
+	keysPath := os.Getenv(EnvTrustedKeys)
+	if keysPath == "" {
+		return nil, nil
+	}
+	return signature.ReadKeyRing(keysPath)
+}
+
 func packRow(cachedRegistry *caching.Registry, cachedPack *caching.Pack) []string {
 	return []string{
 		// The Name of the registryPack
@@ -214,6 +258,19 @@ func renderPack(
 	return r, nil
 }
 
+// deploymentTemplates returns the rendered templates of the pack and of its
+// dependencies, which are deployed together.
+func deploymentTemplates(r *renderer.Rendered) map[string]string {
+	templates := make(map[string]string, r.LenDependentRenders()+r.LenParentRenders())
+	for dn, ds := range r.DependentRenders() {
+		templates[dn] = ds
+	}
+	for pn, ps := range r.ParentRenders() {
+		templates[pn] = ps
+	}
+	return templates
+}
+
 // getNamespaceOrDefault returns the provided namespace or "default" if empty
 func getNamespaceOrDefault(ns string) string {
 	if ns == "" {
@@ -222,20 +279,11 @@ func getNamespaceOrDefault(ns string) string {
 	return ns
 }
 
-// createNomadVariables creates Nomad Variables defined in nomad_variable blocks
-func createNomadVariables(
-	parsedVars *parser.ParsedVariables,
-	client *api.Client,
-	ui terminal.UI,
-) error {
-	nomadVars := parsedVars.GetNomadVars()
-	if len(nomadVars) == 0 {
-		return nil // No nomad variables to create
-	}
-
-	ui.Output("Creating Nomad Variables...")
-
-	for _, nvList := range nomadVars {
+// nomadVariables converts the Nomad Variables defined in nomad_variable blocks
+// into the form they are stored in Nomad, with each item as a string.
+func nomadVariables(parsedVars *parser.ParsedVariables) ([]*planfile.NomadVariable, error) {
+	var out []*planfile.NomadVariable
+	for _, nvList := range parsedVars.GetNomadVars() {
 		for _, nv := range nvList {
 			// Convert cty.Value items to map[string]string for Nomad API
 			items := make(map[string]string)
@@ -243,7 +291,7 @@ func createNomadVariables(
 				// Convert cty.Value to Go interface
 				goVal, err := variables.ConvertCtyToInterface(val)
 				if err != nil {
-					return fmt.Errorf("failed to convert variable %s.%s: %w", nv.Name, key, err)
+					return nil, fmt.Errorf("failed to convert variable %s.%s: %w", nv.Name, key, err)
 				}
 
 				// serialize complex types as JSON, simple types as strings
@@ -257,42 +305,65 @@ func createNomadVariables(
 					// for maps, slices and other complex types, use JSON
 					jsonBytes, err := json.Marshal(goVal)
 					if err != nil {
-						return fmt.Errorf("failed to serialize variable %s.%s as JSON: %w", nv.Name, key, err)
+						return nil, fmt.Errorf("failed to serialize variable %s.%s as JSON: %w", nv.Name, key, err)
 					}
 					strVal = string(jsonBytes)
 				}
 				items[key] = strVal
 			}
 
-			// Create the Nomad Variable
-			variable := &api.Variable{
+			out = append(out, &planfile.NomadVariable{
+				Name:      nv.Name,
 				Path:      nv.Path,
 				Namespace: nv.Namespace,
 				Items:     items,
-			}
+			})
+		}
+	}
+	return out, nil
+}
 
-			// Set default namespace if not specified
-			variable.Namespace = getNamespaceOrDefault(variable.Namespace)
+// createNomadVariables creates Nomad Variables defined in nomad_variable blocks
+func createNomadVariables(
+	nomadVars []*planfile.NomadVariable,
+	client *api.Client,
+	ui terminal.UI,
+) error {
+	if len(nomadVars) == 0 {
+		return nil // No nomad variables to create
+	}
 
-			ui.Output(fmt.Sprintf("  Creating variable at path: %s (namespace: %s)",
-				nv.Path, variable.Namespace))
+	ui.Output("Creating Nomad Variables...")
 
-			// Try to create the variable first
-			_, _, err := client.Variables().Create(variable, nil)
-			if err != nil {
-				// If variable exists, update it instead
-				if strings.Contains(err.Error(), "already exists") {
-					_, _, err = client.Variables().Update(variable, nil)
-					if err != nil {
-						return fmt.Errorf("failed to update variable at path %s: %w", nv.Path, err)
-					}
-					ui.Output(fmt.Sprintf("  ✓ Updated variable: %s", nv.Name))
-				} else {
-					return fmt.Errorf("failed to create variable at path %s: %w", nv.Path, err)
+	for _, nv := range nomadVars {
+		// Create the Nomad Variable
+		variable := &api.Variable{
+			Path:      nv.Path,
+			Namespace: nv.Namespace,
+			Items:     nv.Items,
+		}
+
+		// Set default namespace if not specified
+		variable.Namespace = getNamespaceOrDefault(variable.Namespace)
+
+		ui.Output(fmt.Sprintf("  Creating variable at path: %s (namespace: %s)",
+			nv.Path, variable.Namespace))
+
+		// Try to create the variable first
+		_, _, err := client.Variables().Create(variable, nil)
+		if err != nil {
+			// If variable exists, update it instead
+			if strings.Contains(err.Error(), "already exists") {
+				_, _, err = client.Variables().Update(variable, nil)
+				if err != nil {
+					return fmt.Errorf("failed to update variable at path %s: %w", nv.Path, err)
 				}
+				ui.Output(fmt.Sprintf("  ✓ Updated variable: %s", nv.Name))
 			} else {
-				ui.Output(fmt.Sprintf("  ✓ Created variable: %s", nv.Name))
+				return fmt.Errorf("failed to create variable at path %s: %w", nv.Path, err)
 			}
+		} else {
+			ui.Output(fmt.Sprintf("  ✓ Created variable: %s", nv.Name))
 		}
 	}
 	ui.Success("Nomad Variables created successfully")
@@ -337,6 +408,144 @@ func deleteNomadVariables(
 	return nil
 }
 
+// newReleaseStore returns the release store for the cluster targeted by the
+// command. The namespace is read from the CLI configuration rather than the
+// client, as parsing templates may point the client at a job's namespace.
+func newReleaseStore(c *baseCommand, client *api.Client) *release.Store {
+	return release.NewStore(client, clientOptsFromCLI(c).Namespace)
+}
+
+// recordRelease stores the templates and resulting job versions of a
+// successful deployment as the next release of the deployment. When secrets
+// is set, the templates hold values read from a source of secrets and are
+// left out. rollbackOf should be set to the re-deployed revision when called
+// by rollback.
+func recordRelease(
+	c *baseCommand,
+	client *api.Client,
+	runnerCfg *runner.Config,
+	deployer runner.Runner,
+	templates map[string]string,
+	vars map[string]any,
+	secrets bool,
+	rollbackOf int,
+) (*release.Release, error) {
+	parsedTemplates, ok := deployer.ParsedTemplates().(map[string]job.ParsedTemplate)
+	if !ok {
+		return nil, fmt.Errorf("unsupported parsed templates type %T", deployer.ParsedTemplates())
+	}
+
+	rel := &release.Release{
+		DeploymentName: runnerCfg.DeploymentName,
+		PackName:       runnerCfg.PackName,
+		PackPath:       runnerCfg.PathPath,
+		PackRef:        runnerCfg.PackRef,
+		RegistryName:   runnerCfg.RegistryName,
+		Variables:      vars,
+		Templates:      templates,
+		RollbackOf:     rollbackOf,
+	}
+	if secrets {
+		rel.Templates = nil
+		rel.SecretsOmitted = true
+	}
+
+	// Look up the version Nomad assigned to each job. The templates are
+	// iterated in name order so the stored job list is stable.
+	tplNames := make([]string, 0, len(parsedTemplates))
+	for name := range parsedTemplates {
+		tplNames = append(tplNames, name)
+	}
+	sort.Strings(tplNames)
+
+	for _, name := range tplNames {
+		pt := parsedTemplates[name]
+		queryOpts := &api.QueryOptions{}
+		if pt.HasRegion() {
+			queryOpts.Region = *pt.Job().Region
+		}
+		if pt.HasNamespace() {
+			queryOpts.Namespace = *pt.Job().Namespace
+		}
+
+		nomadJob, _, err := client.Jobs().Info(*pt.Job().ID, queryOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job %q: %w", *pt.Job().ID, err)
+		}
+
+		relJob := &release.Job{ID: *nomadJob.ID}
+		if nomadJob.Namespace != nil {
+			relJob.Namespace = *nomadJob.Namespace
+		}
+		if nomadJob.Region != nil {
+			relJob.Region = *nomadJob.Region
+		}
+		if nomadJob.Version != nil {
+			relJob.Version = *nomadJob.Version
+		}
+		rel.Jobs = append(rel.Jobs, relJob)
+	}
+
+	if err := newReleaseStore(c, client).Record(rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// leaseWarnings returns a warning for each lease of a secret that variable
+// values were read from. The values are rendered into the jobs as they were
+// read, so the jobs keep using them after the lease expires.
+func leaseWarnings(leases []source.Lease, now time.Time) []string {
+	warnings := make([]string, 0, len(leases))
+	for _, lease := range leases {
+		names := make([]string, len(lease.Variables))
+		for i, name := range lease.Variables {
+			names[i] = string(name)
+		}
+
+		expiry := "has already expired"
+		if remaining := lease.Expires.Sub(now).Round(time.Second); remaining > 0 {
+			expiry = fmt.Sprintf("expires in %s, at %s", remaining, lease.Expires.Format(time.RFC3339))
+		}
+
+		renewal := ""
+		if lease.Renewable {
+			renewal = " Nomad Pack does not renew the lease."
+		}
+
+		warnings = append(warnings, fmt.Sprintf(
+			"Variables %s of pack %s were read from %s in %s, whose lease %s. The jobs keep the rendered values after the lease expires; run the pack again to render new ones.%s",
+			strings.Join(names, ", "), lease.Pack, lease.Path, lease.Source, expiry, renewal))
+	}
+	return warnings
+}
+
+// releaseVariables flattens the resolved pack variables into a map keyed by
+// their fully qualified name, suitable for storing with a release. Releases
+// are stored in plain form, so variables read from sources of secrets, such
+// as Vault, are left out. The templates rendered from them are left out by
+// recordRelease.
+func releaseVariables(parsedVars *parser.ParsedVariables) (map[string]any, error) {
+	if parsedVars == nil {
+		return nil, nil
+	}
+
+	out := make(map[string]any)
+	for packID, packVars := range parsedVars.GetVars() {
+		for varID, v := range packVars {
+			if v.Value == cty.NilVal || parsedVars.IsSecret(packID, varID) {
+				continue
+			}
+			goVal, err := variables.ConvertCtyToInterface(v.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert variable %s.%s: %w", packID, varID, err)
+			}
+			out[packID.String()+"."+varID.String()] = goVal
+		}
+	}
+	return out, nil
+}
+
 // TODO: This needs to be on a domain specific pkg rather than a UI helpers file.
 // This will be possible once we create a logger interface that can be passed
 // between layers.
@@ -724,3 +933,50 @@ func addNoParentTemplatesContext(errorContext *errors.UIErrorContext, packPath s
 		}
 	}
 }
+
+// checkPolicies loads the policy rules of policyDir and evaluates them against
+// the jobs parsed by the deployer. Violations are printed, and false is
+// returned if the rules could not be loaded or an error level rule was
+// violated.
+func checkPolicies(ui terminal.UI, deployer runner.Runner, policyDir string, errorContext *errors.UIErrorContext) bool {
+	rules, diags := policy.Load(policyDir)
+	if diags.HasErrors() {
+		for _, wErr := range errors.HCLDiagsToWrappedUIContext(diags) {
+			wErr.Context.Append(errorContext)
+			ui.ErrorWithContext(wErr.Err, wErr.Subject, wErr.Context.GetAll()...)
+		}
+		return false
+	}
+
+	if policyErrs := deployer.CheckPolicies(ui, rules, errorContext); policyErrs != nil {
+		for _, policyErr := range policyErrs {
+			ui.ErrorWithContext(policyErr.Err, policyErr.Subject, policyErr.Context.GetAll()...)
+		}
+		return false
+	}
+	return true
+}
+
+// resultEvent is the data of the event emitted when a command that changes a
+// deployment finishes.
+type resultEvent struct {
+	Command    string `json:"command"`
+	Deployment string `json:"deployment,omitempty"`
+	Success    bool   `json:"success"`
+	ExitCode   int    `json:"exit_code"`
+}
+
+// emitResult emits the final result of the command as a structured event,
+// so programs consuming JSON output need not interpret the exit code.
+func emitResult(c *baseCommand, command string, exitCode int, success bool) {
+	level, msg := terminal.LevelInfo, fmt.Sprintf("%s succeeded", helper.Title(command))
+	if !success {
+		level, msg = terminal.LevelError, fmt.Sprintf("%s failed", helper.Title(command))
+	}
+	terminal.Event(c.ui, terminal.EventResult, level, msg, &resultEvent{
+		Command:    command,
+		Deployment: c.deploymentName,
+		Success:    success,
+		ExitCode:   exitCode,
+	})
+}
@@ -4,13 +4,23 @@
 package cli
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/posener/complete"
 	"github.com/shoenig/test/must"
 
@@ -18,7 +28,12 @@ import (
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
 	"github.com/hashicorp/nomad-pack/internal/pkg/logging"
+	"github.com/hashicorp/nomad-pack/internal/pkg/manager"
 	"github.com/hashicorp/nomad-pack/internal/pkg/testfixture"
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/source"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/runner/job"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
 )
 
 func TestExtractFlagValue(t *testing.T) {
@@ -166,3 +181,155 @@ func TestAddNoParentTemplatesContext(t *testing.T) {
 	must.StrContains(t, contextStr, "_helpers.tpl")
 	must.StrContains(t, contextStr, "config.tpl")
 }
+
+func TestLeaseWarnings(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	leases := []source.Lease{
+		{
+			Source:    "vault(https://vault:8200/database/creds/app?engine=logical)",
+			Pack:      "web",
+			Path:      "database/creds/app",
+			Variables: []variables.ID{"db_password", "db_user"},
+			Duration:  time.Hour,
+			Renewable: true,
+			Expires:   now.Add(time.Hour),
+		},
+		{
+			Source:    "vault(https://vault:8200/pki/issue/web?engine=logical)",
+			Pack:      "web.proxy",
+			Path:      "pki/issue/web",
+			Variables: []variables.ID{"certificate"},
+			Expires:   now.Add(-time.Minute),
+		},
+	}
+
+	warnings := leaseWarnings(leases, now)
+	must.Len(t, 2, warnings)
+	must.StrContains(t, warnings[0], "Variables db_password, db_user of pack web were read from database/creds/app")
+	must.StrContains(t, warnings[0], "expires in 1h0m0s, at 2026-01-02T04:04:05Z")
+	must.StrContains(t, warnings[0], "does not renew the lease")
+	must.StrContains(t, warnings[1], "of pack web.proxy")
+	must.StrContains(t, warnings[1], "has already expired")
+	must.StrNotContains(t, warnings[1], "renew the lease")
+}
+
+func TestParseVarSourceConfig_Vault(t *testing.T) {
+	cases := []struct {
+		url    string
+		expect source.SourceConfig
+		err    string
+	}{
+		{
+			url:    "vault:///secret/app",
+			expect: source.VaultSourceConfig{Priority: 10, Mount: "secret", Paths: []string{"app"}},
+		},
+		{
+			url: "vault://localhost:8200/kv/app/common?engine=kv1&path=app/prod",
+			expect: source.VaultSourceConfig{
+				Priority: 10, Address: "localhost:8200", Engine: source.VaultEngineKVv1,
+				Mount: "kv", Paths: []string{"app/common", "app/prod"},
+			},
+		},
+		{
+			url:    "vault:///secret?path=common&path={pack}",
+			expect: source.VaultSourceConfig{Priority: 10, Mount: "secret", Paths: []string{"common", "{pack}"}},
+		},
+		{
+			url: "vault:///database/creds/app?engine=logical",
+			expect: source.VaultSourceConfig{
+				Priority: 10, Engine: source.VaultEngineLogical, Mount: "database", Paths: []string{"creds/app"},
+			},
+		},
+		{url: "vault:///secret", err: "must include a mount and path"},
+		{url: "vault:///secret/app?engine=kv3", err: `unsupported vault engine "kv3"`},
+		{url: "vault:///secret/app?version=2", err: `unsupported vault URL parameter "version"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			cfg, err := parseVarSourceConfig(tc.url, 10)
+			if tc.err != "" {
+				must.ErrorContains(t, err, tc.err)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expect, cfg)
+		})
+	}
+}
+
+func TestRecordRelease_Secrets(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v1/secret/data/app" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"command":"echo s3cret"},"metadata":{"version":1}}}`))
+	}))
+	t.Cleanup(vault.Close)
+
+	// The fake Nomad has no releases yet, and captures the one recorded.
+	var stored []byte
+	nomad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/vars":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v1/var/"):
+			body, err := io.ReadAll(r.Body)
+			must.NoError(t, err)
+			stored = body
+			_, _ = w.Write(body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(nomad.Close)
+
+	client, err := api.NewClient(&api.Config{Address: nomad.URL})
+	must.NoError(t, err)
+
+	packDir := testfixture.Clone(t, "v2/test_registry/packs/simple_raw_exec")
+	pm := manager.NewPackManager(&manager.Config{
+		Path: packDir,
+		ExternalSourceConfigs: []source.SourceConfig{
+			source.VaultSourceConfig{Priority: source.PriorityExternalBase, Address: vault.URL, Mount: "secret", Paths: []string{"app"}},
+		},
+	}, client)
+	r, wErrs := pm.ProcessTemplates(false, false, false)
+	must.SliceEmpty(t, wErrs)
+
+	templates := deploymentTemplates(r)
+	must.StrContains(t, strings.Join(slices.Collect(maps.Values(templates)), ""), "s3cret")
+	must.True(t, r.ParsedVariables().HasSecrets())
+
+	vars, err := releaseVariables(r.ParsedVariables())
+	must.NoError(t, err)
+
+	runnerCfg := &runner.Config{DeploymentName: "simple_raw_exec", PackName: "simple_raw_exec"}
+	deployer, err := generateRunner(client, "job", &job.CLIConfig{RunConfig: &job.RunCLIConfig{}}, runnerCfg)
+	must.NoError(t, err)
+
+	c := &baseCommand{}
+	rel, err := recordRelease(c, client, runnerCfg, deployer, templates, vars, r.ParsedVariables().HasSecrets(), 0)
+	must.NoError(t, err)
+	must.True(t, rel.SecretsOmitted)
+	must.MapEmpty(t, rel.Templates)
+
+	// The release is stored gzipped, so decode it before looking for the
+	// secret.
+	must.NotNil(t, stored)
+	var v api.Variable
+	must.NoError(t, json.Unmarshal(stored, &v))
+	raw, err := base64.StdEncoding.DecodeString(v.Items["release"])
+	must.NoError(t, err)
+	gz, err := gzip.NewReader(bytes.NewReader(raw))
+	must.NoError(t, err)
+	decoded, err := io.ReadAll(gz)
+	must.NoError(t, err)
+
+	must.StrContains(t, string(decoded), `"secrets_omitted":true`)
+	must.StrNotContains(t, string(decoded), "s3cret")
+	must.StrNotContains(t, string(stored), "s3cret")
+}
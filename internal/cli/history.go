@@ -0,0 +1,259 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	flag "github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/release"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+type HistoryCommand struct {
+	*baseCommand
+	packConfig *caching.PackConfig
+}
+
+// historyEntry is a single row of the history output. It is also the data of
+// the release event emitted for each revision when --json is set.
+type historyEntry struct {
+	DeploymentName string         `json:"deployment_name"`
+	Revision       int            `json:"revision"`
+	Timestamp      time.Time      `json:"timestamp"`
+	PackName       string         `json:"pack_name"`
+	PackRef        string         `json:"pack_ref"`
+	RegistryName   string         `json:"registry_name"`
+	Jobs           []*release.Job `json:"jobs"`
+	Superseded     bool           `json:"superseded"`
+	RollbackOf     int            `json:"rollback_of,omitempty"`
+}
+
+func (c *HistoryCommand) Run(args []string) int {
+	c.cmdKey = "history" // Add cmdKey here to print out helpUsageMessage on Init error
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := c.Init(
+		WithExactArgs(1, args),
+		WithFlags(c.Flags()),
+		WithNoConfig(),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	exitCode := c.run()
+	emitResult(c.baseCommand, "history", exitCode, exitCode == 0)
+	return exitCode
+}
+
+// run is the implementation of this command.
+func (c *HistoryCommand) run() int {
+	c.packConfig.Name = c.args[0]
+
+	errorContext := errors.NewUIErrorContext()
+	errorContext.Add(errors.UIContextPrefixPackName, c.packConfig.Name)
+	if c.deploymentName != "" {
+		errorContext.Add(errors.UIContextPrefixDeploymentName, c.deploymentName)
+	}
+
+	client, err := c.getAPIClient()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to initialize client", errorContext.GetAll()...)
+		return 1
+	}
+
+	deploymentNames, err := c.deploymentNames(client)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "error retrieving jobs", errorContext.GetAll()...)
+		return 1
+	}
+
+	entries, err := c.historyEntries(newReleaseStore(c.baseCommand, client), deploymentNames)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "error retrieving release history", errorContext.GetAll()...)
+		return 1
+	}
+
+	if c.jsonOutput() {
+		for _, entry := range entries {
+			terminal.Event(c.ui, terminal.EventRelease, terminal.LevelInfo,
+				fmt.Sprintf("Revision %d of deployment %q", entry.Revision, entry.DeploymentName), entry)
+		}
+		return 0
+	}
+
+	if len(entries) == 0 {
+		msg := fmt.Sprintf("no releases found for pack %q", c.packConfig.Name)
+		if c.deploymentName != "" {
+			msg += fmt.Sprintf(" in deployment %q", c.deploymentName)
+		}
+		c.ui.Warning(msg)
+		return 0
+	}
+
+	c.ui.Table(formatReleaseHistory(entries))
+	return 0
+}
+
+// deploymentNames returns the deployments to show the history of. When --name
+// is not set, these are discovered from the pack.deployment_name meta of the
+// pack's jobs currently registered in Nomad.
+func (c *HistoryCommand) deploymentNames(client *api.Client) ([]string, error) {
+	if c.deploymentName != "" {
+		return []string{c.deploymentName}, nil
+	}
+
+	packJobs, _, err := getDeployedPackJobs(client, c.packConfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	var names []string
+	for _, jobInfo := range packJobs {
+		if jobInfo.deploymentName == "" {
+			continue
+		}
+		if _, ok := seen[jobInfo.deploymentName]; ok {
+			continue
+		}
+		seen[jobInfo.deploymentName] = struct{}{}
+		names = append(names, jobInfo.deploymentName)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// historyEntries reads the releases of each deployment, ignoring releases of
+// other packs that happened to use the same deployment name, and marks every
+// release other than the latest of its deployment as superseded.
+func (c *HistoryCommand) historyEntries(store *release.Store, deploymentNames []string) ([]historyEntry, error) {
+	entries := []historyEntry{}
+
+	for _, deploymentName := range deploymentNames {
+		releases, err := store.List(deploymentName)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, rel := range releases {
+			if rel.PackName != c.packConfig.Name {
+				continue
+			}
+			if c.packConfig.Registry != "" && rel.RegistryName != c.packConfig.Registry {
+				continue
+			}
+			entries = append(entries, historyEntry{
+				DeploymentName: rel.DeploymentName,
+				Revision:       rel.Revision,
+				Timestamp:      rel.Timestamp,
+				PackName:       rel.PackName,
+				PackRef:        rel.PackRef,
+				RegistryName:   rel.RegistryName,
+				Jobs:           rel.Jobs,
+				Superseded:     i < len(releases)-1,
+				RollbackOf:     rel.RollbackOf,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+func formatReleaseHistory(entries []historyEntry) *terminal.Table {
+	tbl := terminal.NewTable("Deployment Name", "Revision", "Deployed At", "Pack Ref", "Registry Name", "Job Versions", "Status")
+	for _, entry := range entries {
+		jobVersions := make([]string, 0, len(entry.Jobs))
+		for _, j := range entry.Jobs {
+			jobVersions = append(jobVersions, fmt.Sprintf("%s@v%d", j.ID, j.Version))
+		}
+
+		status := "current"
+		if entry.Superseded {
+			status = "superseded"
+		}
+		if entry.RollbackOf > 0 {
+			status += fmt.Sprintf(" (rollback to %d)", entry.RollbackOf)
+		}
+
+		row := []string{}
+		row = append(row, entry.DeploymentName)
+		row = append(row, strconv.Itoa(entry.Revision))
+		row = append(row, formatTime(entry.Timestamp))
+		row = append(row, entry.PackRef)
+		row = append(row, entry.RegistryName)
+		row = append(row, strings.Join(jobVersions, ", "))
+		row = append(row, status)
+		tbl.Rows = append(tbl.Rows, row)
+	}
+	return tbl
+}
+
+func (c *HistoryCommand) Flags() *flag.Sets {
+	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetOutput, func(set *flag.Sets) {
+		c.packConfig = &caching.PackConfig{}
+
+		f := set.NewSet("History Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "registry",
+			Target:  &c.packConfig.Registry,
+			Default: "",
+			Usage: `Only show releases of the pack deployed from the named
+					registry.`,
+		})
+	})
+}
+
+func (c *HistoryCommand) AutocompleteArgs() complete.Predictor {
+	return predictPackName
+}
+
+func (c *HistoryCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *HistoryCommand) Help() string {
+	c.Example = `
+	# List the releases of every deployment of the example pack
+	nomad-pack history example
+
+	# List the releases of the example pack in the deployment "dev"
+	nomad-pack history example --name=dev
+
+	# Output the release history as newline-delimited JSON
+	nomad-pack history example --name=dev --json
+	`
+
+	return formatHelp(`
+	Usage: nomad-pack history <pack-name> [options]
+
+	Show the recorded releases of a pack's deployments.
+
+	Each successful run or rollback records a release of the deployment. The
+	history lists every release with its pack ref, registry, and the Nomad job
+	versions it registered. All releases other than the latest one of each
+	deployment are shown as superseded.
+
+	When --name is not set, deployments are discovered from the pack's jobs
+	currently registered in Nomad.
+
+` + c.GetExample() + c.Flags().Help())
+}
+
+// Synopsis satisfies the Synopsis function of the cli.Command interface.
+func (c *HistoryCommand) Synopsis() string {
+	return "Show the release history of a pack deployment"
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/posener/complete"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/deps"
 	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
 	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
 	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser"
@@ -96,6 +97,24 @@ func (c *InfoCommand) Run(args []string) int {
 		glint.Text(p.Metadata.App.URL),
 	).Row())
 
+	if len(p.Metadata.Dependencies) > 0 {
+		lock, err := deps.ReadLockFile(packPath)
+		if err != nil {
+			c.ui.ErrorWithContext(err, "failed to read dependency lock file", errorContext.GetAll()...)
+			return 1
+		}
+
+		doc.Append(glint.Layout(
+			glint.Style(glint.Text(fmt.Sprintf("Pack %q Dependencies:", p.Name())), glint.Bold()),
+		).Row())
+
+		for _, dep := range p.Metadata.Dependencies {
+			doc.Append(glint.Layout(glint.Style(
+				glint.Text(formatDependency(dep, lock.Get(dep.Name))),
+			)).Row())
+		}
+	}
+
 	for pName, variables := range parsedVars.GetVars() {
 
 		doc.Append(glint.Layout(
@@ -181,7 +200,9 @@ func (c *InfoCommand) Help() string {
 	return formatHelp(`
 	Usage: nomad-pack info <pack-name>
 
-	Returns information on the given pack including name, description, and variable details.
+	Returns information on the given pack including name, description, dependencies,
+	and variable details. The versions and commits of vendored dependencies are
+	read from the pack.lock.hcl file of the pack.
 
 ` + c.GetExample() + c.Flags().Help())
 }
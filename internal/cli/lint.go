@@ -0,0 +1,164 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/lint"
+	"github.com/hashicorp/nomad-pack/internal/pkg/manager"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// LintCommand checks a pack for problems such as unused or undeclared
+// variables, and templates which do not render to valid HCL. It is aimed at
+// pack authors, and registry maintainers who want consistent quality gates.
+type LintCommand struct {
+	*baseCommand
+	packConfig *caching.PackConfig
+
+	// strict causes warnings, as well as errors, to fail the command.
+	strict bool
+}
+
+// Run satisfies the Run function of the cli.Command interface.
+func (c *LintCommand) Run(args []string) int {
+	c.cmdKey = "lint" // Add cmdKey here to print out helpUsageMessage on Init error
+
+	if err := c.Init(
+		WithExactArgs(1, args),
+		WithFlags(c.Flags()),
+		WithNoConfig(),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	c.packConfig.Name = c.args[0]
+
+	// Set the packConfig defaults if necessary and generate our UI error context.
+	errorContext := initPackCommand(c.packConfig)
+
+	if err := caching.VerifyPackExists(c.packConfig, errorContext, c.ui); err != nil {
+		return 1
+	}
+
+	// Linting works on the pack alone, so neither variable overrides nor a
+	// Nomad client are used.
+	packManager := manager.NewPackManager(&manager.Config{Path: c.packConfig.Path}, nil)
+
+	p, err := packManager.LoadPack()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to load pack", errorContext.GetAll()...)
+		return 1
+	}
+
+	problems := lint.Lint(p)
+	if len(problems) == 0 {
+		c.ui.Success(fmt.Sprintf("Pack %q has no problems", p.Name()))
+		return 0
+	}
+
+	tbl := terminal.NewTable("Severity", "Check", "Location", "Message")
+	errs := 0
+	for _, problem := range problems {
+		if problem.Severity == lint.SeverityError {
+			errs++
+		}
+		tbl.Rows = append(tbl.Rows, []string{
+			string(problem.Severity), problem.Check, problem.Location(), problem.Message,
+		})
+	}
+	c.ui.Table(tbl)
+
+	summary := fmt.Sprintf("Pack %q has %d errors and %d warnings", p.Name(), errs, len(problems)-errs)
+	if errs > 0 || c.strict {
+		c.ui.Error(summary)
+		return 1
+	}
+
+	c.ui.Warning(summary)
+	return 0
+}
+
+func (c *LintCommand) Flags() *flag.Sets {
+	return c.flagSet(0, func(set *flag.Sets) {
+		c.packConfig = &caching.PackConfig{}
+
+		f := set.NewSet("Lint Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "registry",
+			Target:  &c.packConfig.Registry,
+			Default: "",
+			Usage: `Specific registry name containing the pack to be linted.
+					If not specified, the default registry will be used.`,
+		})
+
+		f.StringVar(&flag.StringVar{
+			Name:    "ref",
+			Target:  &c.packConfig.Ref,
+			Default: "",
+			Usage: `Specific git ref of the pack to be linted.
+					Supports tags, SHA, and latest. If no ref is specified,
+					defaults to latest.
+
+					Using ref with a file path is not supported.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "strict",
+			Target:  &c.strict,
+			Default: false,
+			Usage: `Exit with a non-zero status when any warnings are found,
+					rather than only when errors are found.`,
+		})
+	})
+}
+
+func (c *LintCommand) AutocompleteArgs() complete.Predictor {
+	return predictPackName
+}
+
+func (c *LintCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *LintCommand) Help() string {
+	c.Example = `
+	# Lint the pack in the current directory
+	nomad-pack lint .
+
+	# Lint a pack, failing on warnings as well as errors
+	nomad-pack lint ./my-pack --strict
+
+	# Lint the "hello_world" pack from the default registry
+	nomad-pack lint hello_world
+	`
+
+	return formatHelp(`
+	Usage: nomad-pack lint <pack-name> [options]
+
+	Check a pack for problems. The pack's templates are checked for variables
+	which are not declared, and its variables for ones which are not used by
+	any template or have no description. The pack should have README.md and
+	CHANGELOG.md files, and should not use deprecated metadata fields. The
+	templates are also rendered, using representative values for variables
+	without defaults, and the rendered job templates must be valid HCL.
+
+	Problems are reported as errors or warnings. The command exits with a
+	non-zero status if any errors are found, or any warnings when --strict is
+	used.
+
+` + c.GetExample() + c.Flags().Help())
+}
+
+func (c *LintCommand) Synopsis() string {
+	return "Check a pack for problems"
+}
@@ -168,6 +168,31 @@ func Commands(
 				baseCommand: baseCommand,
 			}, nil
 		},
+		"history": func() (cli.Command, error) {
+			return &HistoryCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"rollback": func() (cli.Command, error) {
+			return &RollbackCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"test": func() (cli.Command, error) {
+			return &TestCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"lint": func() (cli.Command, error) {
+			return &LintCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"search": func() (cli.Command, error) {
+			return &SearchCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
 		"registry": func() (cli.Command, error) {
 			return &RegistryHelpCommand{
 				baseCommand: baseCommand,
@@ -188,6 +213,11 @@ func Commands(
 				baseCommand: baseCommand,
 			}, nil
 		},
+		"registry index": func() (cli.Command, error) {
+			return &RegistryIndexCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
 		"fmt": func() (cli.Command, error) {
 			return &FmtCommand{
 				baseCommand: baseCommand,
@@ -200,6 +230,11 @@ func Commands(
 				baseCommand: baseCommand,
 			}, nil
 		},
+		"registry push": func() (cli.Command, error) {
+			return &RegistryPushCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
 		"generate": func() (cli.Command, error) {
 			return &GenerateHelpCommand{
 				baseCommand: baseCommand,
@@ -230,6 +265,16 @@ func Commands(
 				baseCommand: baseCommand,
 			}, nil
 		},
+		"deps tree": func() (cli.Command, error) {
+			return &depsTreeCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"deps outdated": func() (cli.Command, error) {
+			return &depsOutdatedCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
 	}
 
 	// register our aliases
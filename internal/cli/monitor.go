@@ -677,6 +677,7 @@ func basicMonitorDeployment(ctx context.Context, ui terminal.UI, client *api.Cli
 	q = q.WithContext(ctx)
 
 	var deploy *api.Deployment
+	var lastStatus string
 	for {
 		// Check for context cancellation
 		select {
@@ -701,6 +702,13 @@ func basicMonitorDeployment(ctx context.Context, ui terminal.UI, client *api.Cli
 
 		status = deploy.Status
 
+		// Emit each change of the deployment's status for UIs which output
+		// structured events, as the text output only reports the final one.
+		if desc := status + "/" + deploy.StatusDescription; desc != lastStatus {
+			lastStatus = desc
+			emitDeploymentStatus(ui, deploy)
+		}
+
 		switch status {
 		case api.DeploymentStatusFailed:
 			if hasAutoRevert(deploy) {
@@ -765,6 +773,34 @@ func basicMonitorDeployment(ctx context.Context, ui terminal.UI, client *api.Cli
 	return
 }
 
+// deploymentStatusEvent is the data of the event emitted when the status of
+// a monitored deployment changes.
+type deploymentStatusEvent struct {
+	DeploymentID      string `json:"deployment_id"`
+	JobID             string `json:"job_id"`
+	Namespace         string `json:"namespace"`
+	Status            string `json:"status"`
+	StatusDescription string `json:"status_description"`
+}
+
+func emitDeploymentStatus(ui terminal.UI, deploy *api.Deployment) {
+	level := terminal.LevelInfo
+	switch deploy.Status {
+	case api.DeploymentStatusFailed:
+		level = terminal.LevelError
+	case api.DeploymentStatusCancelled, api.DeploymentStatusBlocked:
+		level = terminal.LevelWarn
+	}
+	terminal.Event(ui, terminal.EventDeploymentStatus, level,
+		fmt.Sprintf("Deployment %q is %s", deploy.ID, deploy.Status), &deploymentStatusEvent{
+			DeploymentID:      deploy.ID,
+			JobID:             deploy.JobID,
+			Namespace:         deploy.Namespace,
+			Status:            deploy.Status,
+			StatusDescription: deploy.StatusDescription,
+		})
+}
+
 // formatAllocMetrics iterates the passed allocation metrics and returns a
 // formatted string representation. Critical or important information is colored
 // red to draw attention.
@@ -4,9 +4,16 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/manager"
+	"github.com/hashicorp/nomad-pack/internal/pkg/planfile"
+	"github.com/hashicorp/nomad-pack/internal/pkg/renderer"
+	"github.com/hashicorp/nomad-pack/internal/pkg/version"
 	"github.com/hashicorp/nomad-pack/internal/runner"
 	"github.com/hashicorp/nomad-pack/internal/runner/job"
 	"github.com/posener/complete"
@@ -19,9 +26,13 @@ type PlanCommand struct {
 	exitCodeNoChanges int
 	exitCodeChanges   int
 	exitCodeError     int
+
+	// planOut is the path the plan is saved to, for applying it later with
+	// the run command.
+	planOut string
 }
 
-func (c *PlanCommand) Run(args []string) int {
+func (c *PlanCommand) Run(args []string) (exitCode int) {
 	c.cmdKey = "plan" // Add cmdKey here to print out helpUsageMessage on Init error
 	// Initialize. If we fail, we just exit since Init handles the UI.
 	if err := c.Init(
@@ -34,6 +45,10 @@ func (c *PlanCommand) Run(args []string) int {
 		return c.exitCodeError
 	}
 
+	defer func() {
+		emitResult(c.baseCommand, "plan", exitCode, exitCode != c.exitCodeError)
+	}()
+
 	c.packConfig.Name = c.args[0]
 
 	// Set the packConfig defaults if necessary and generate our UI error context.
@@ -94,8 +109,10 @@ func (c *PlanCommand) Run(args []string) int {
 		return c.exitCodeError
 	}
 
-	// Set the rendered templates on the job deployer.
-	jobRunner.SetTemplates(r.ParentRenders())
+	// Set the rendered templates on the job deployer. The templates of
+	// dependencies are planned as well, as run deploys them with the pack.
+	templates := deploymentTemplates(r)
+	jobRunner.SetTemplates(templates)
 
 	// Parse the templates. If we have any error, output this and exit.
 	if validateErrs := jobRunner.ParseTemplates(); validateErrs != nil {
@@ -114,6 +131,12 @@ func (c *PlanCommand) Run(args []string) int {
 		return c.exitCodeError
 	}
 
+	// Check the jobs against the policy rules before anything is submitted
+	// to Nomad.
+	if c.policyDir != "" && !checkPolicies(c.ui, jobRunner, c.policyDir, errorContext) {
+		return c.exitCodeError
+	}
+
 	if conflictErrs := jobRunner.CheckForConflicts(errorContext); conflictErrs != nil {
 		for _, conflictErr := range conflictErrs {
 			c.ui.ErrorWithContext(conflictErr.Err, conflictErr.Subject, conflictErr.Context.GetAll()...)
@@ -128,6 +151,14 @@ func (c *PlanCommand) Run(args []string) int {
 
 	if planExitCode < 2 {
 		c.ui.Success("Plan succeeded")
+
+		if c.planOut != "" {
+			if err := c.savePlan(r, templates, packManager, jobRunner, &depConfig); err != nil {
+				c.ui.ErrorWithContext(err, "failed to save plan", errorContext.GetAll()...)
+				return c.exitCodeError
+			}
+			c.ui.Info(fmt.Sprintf("Plan saved to %s. To apply it, run: nomad-pack run %s", c.planOut, c.planOut))
+		}
 	}
 
 	// Map planExitCode to replacement values.
@@ -143,10 +174,81 @@ func (c *PlanCommand) Run(args []string) int {
 	}
 }
 
+// savePlan writes the plan of the templates to the path of the --out flag.
+func (c *PlanCommand) savePlan(r *renderer.Rendered, templates map[string]string, packManager *manager.PackManager, jobRunner runner.Runner, depConfig *runner.Config) error {
+	plan := &planfile.Plan{
+		FormatVersion:    planfile.FormatVersion,
+		NomadPackVersion: version.HumanVersion(),
+		Timestamp:        time.Now().UTC(),
+		DeploymentName:   depConfig.DeploymentName,
+		PackName:         depConfig.PackName,
+		PackPath:         depConfig.PathPath,
+		PackRef:          depConfig.PackRef,
+		RegistryName:     depConfig.RegistryName,
+		Templates:        templates,
+	}
+
+	var err error
+	if plan.Variables, err = releaseVariables(r.ParsedVariables()); err != nil {
+		return err
+	}
+	if r.ParsedVariables() != nil {
+		if plan.NomadVariables, err = nomadVariables(r.ParsedVariables()); err != nil {
+			return err
+		}
+		plan.Secrets = r.ParsedVariables().HasSecrets()
+	}
+	if plan.Output, err = packManager.ProcessOutputTemplate(); err != nil {
+		return fmt.Errorf("failed to render output template: %w", err)
+	}
+
+	parsedTemplates, ok := jobRunner.ParsedTemplates().(map[string]job.ParsedTemplate)
+	if !ok {
+		return fmt.Errorf("unsupported parsed templates type %T", jobRunner.ParsedTemplates())
+	}
+	planResults, ok := jobRunner.PlanResults().(map[string]*job.PlanResult)
+	if !ok {
+		return fmt.Errorf("unsupported plan results type %T", jobRunner.PlanResults())
+	}
+
+	for tplName, result := range planResults {
+		pt := parsedTemplates[tplName]
+		planJob := &planfile.Job{
+			Template:       tplName,
+			ID:             *pt.Job().ID,
+			JobModifyIndex: result.JobModifyIndex,
+			Diff:           result.Diff,
+		}
+		if pt.HasNamespace() {
+			planJob.Namespace = *pt.Job().Namespace
+		}
+		if pt.HasRegion() {
+			planJob.Region = *pt.Job().Region
+		}
+		plan.Jobs = append(plan.Jobs, planJob)
+	}
+
+	// Parsing sorts hook and test templates apart from the job templates, so
+	// any template without a parsed job is one of those, which are run
+	// rather than planned.
+	for tplName := range templates {
+		if _, ok := parsedTemplates[tplName]; !ok {
+			plan.Unplanned = append(plan.Unplanned, tplName)
+		}
+	}
+
+	// Check the plan can be applied before it is written, so a template
+	// which could not be planned is reported now rather than when it is run.
+	if err := plan.Validate(); err != nil {
+		return err
+	}
+	return planfile.Write(c.planOut, plan)
+}
+
 func (c *PlanCommand) Flags() *flag.Sets {
 	c.packConfig = &caching.PackConfig{}
 
-	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetExternalVarSources, func(set *flag.Sets) {
+	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetExternalVarSources|flagSetPolicy|flagSetOutput, func(set *flag.Sets) {
 		f := set.NewSet("Plan Options")
 
 		c.jobConfig = &job.CLIConfig{
@@ -180,6 +282,17 @@ func (c *PlanCommand) Flags() *flag.Sets {
 					planned job is shown. Defaults to true.`,
 		})
 
+		f.StringVar(&flag.StringVar{
+			Name:    "out",
+			Target:  &c.planOut,
+			Default: "",
+			Usage: `Path to save the plan to. The saved plan can be applied with
+					"nomad-pack run <path>", which registers exactly the
+					planned jobs, and only if none of them have changed since
+					they were planned. The file may contain secrets from the
+					pack variables.`,
+		})
+
 		f.BoolVar(&flag.BoolVar{
 			Name:    "deploy-override",
 			Target:  &c.jobConfig.PlanConfig.DeployOverride,
@@ -253,6 +366,10 @@ func (c *PlanCommand) Help() string {
 	# Plan an example pack without showing the diff
 	nomad-pack plan example --diff=false
 
+	# Save the plan of an example pack, to apply it later with
+	# "nomad-pack run plan.json"
+	nomad-pack plan example --out=plan.json
+
 	# Plan a pack under development from the filesystem - supports current
 	# working directory or relative path
 	nomad-pack plan .
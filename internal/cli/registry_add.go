@@ -5,6 +5,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/posener/complete"
@@ -60,11 +61,20 @@ func (c *RegistryAddCommand) Run(args []string) int {
 		return 1
 	}
 
+	keys, err := trustedKeys()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to read trusted keys", errorContext.GetAll()...)
+		return 1
+	}
+
 	newRegistry, err := globalCache.Add(&caching.AddOpts{
 		RegistryName: c.name,
 		Source:       c.source,
 		PackName:     c.target,
 		Ref:          c.ref,
+		Username:     os.Getenv(EnvRegistryUsername),
+		Password:     os.Getenv(EnvRegistryPassword),
+		TrustedKeys:  keys,
 	})
 	if err != nil {
 		return 1
@@ -171,11 +181,30 @@ func (c *RegistryAddCommand) Help() string {
 
     # Download packs using a commit SHA.
     nomad-pack registry add community github.com/hashicorp/nomad-pack-community-registry --ref=abc123def456
+
+    # Download the packs tagged v1.0.0 from a namespace of an OCI registry.
+    nomad-pack registry add internal oci://registry.example.com/packs --ref=v1.0.0
     `
 	return formatHelp(`
     Usage: nomad-pack registry add <name> <source> [options]
 
     Add nomad pack registries.
 
+    A source beginning with oci:// is a namespace of an OCI registry, holding
+    a repository per pack, such as oci://registry.example.com/packs/hello_world
+    for the hello_world pack. The ref is the tag of the packs to pull. Adding
+    every pack of the namespace requires the registry to serve its catalog,
+    otherwise a single pack can be added with --target. Credentials for the
+    OCI registry are read from the NOMAD_PACK_REGISTRY_USERNAME and
+    NOMAD_PACK_REGISTRY_PASSWORD environment variables.
+
+    If the NOMAD_PACK_TRUSTED_KEYS environment variable is set to a file or
+    directory of armored OpenPGP public keys, the registry must be signed by
+    one of them: the commit a git registry is cloned at, or each pack pulled
+    from an OCI registry, must carry a valid signature, otherwise nothing is
+    added. The checksum of each added pack is recorded in the cache, and
+    "nomad-pack run" refuses to deploy a cached pack which has been modified
+    since.
+
 ` + c.GetExample() + c.Flags().Help())
 }
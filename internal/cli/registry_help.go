@@ -23,11 +23,11 @@ func (c *RegistryHelpCommand) Run(args []string) int {
 		WithNoConfig(),
 		WithClient(false),
 	); err != nil {
-		c.ui.Info("The registry command requires one of the following subcommands: add, delete, list, update.")
+		c.ui.Info("The registry command requires one of the following subcommands: add, delete, index, list, push, update.")
 		return 1
 	}
 
-	c.ui.Info("The registry command requires one of the following subcommands: add, delete, list, update.")
+	c.ui.Info("The registry command requires one of the following subcommands: add, delete, index, list, push, update.")
 	return 0
 }
 
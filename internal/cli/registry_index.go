@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// RegistryIndexCommand generates the index.json of a registry, which lets
+// the cache list the packs of the registry without loading each of them.
+type RegistryIndexCommand struct {
+	*baseCommand
+}
+
+func (c *RegistryIndexCommand) Run(args []string) int {
+	c.cmdKey = "registry index"
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := c.Init(
+		WithExactArgs(1, args),
+		WithNoConfig(),
+		WithClient(false),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	registryPath := c.args[0]
+
+	errorContext := errors.NewUIErrorContext()
+	errorContext.Add(errors.UIContextPrefixRegistryPath, registryPath)
+
+	index, err := caching.GenerateIndex(registryPath)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to generate index", errorContext.GetAll()...)
+		return 1
+	}
+
+	if err := caching.WriteIndex(registryPath, index); err != nil {
+		c.ui.ErrorWithContext(err, "failed to write index", errorContext.GetAll()...)
+		return 1
+	}
+
+	c.ui.Table(indexTable(index))
+	c.ui.Success(fmt.Sprintf("Wrote index of %d packs to %s",
+		len(index.Packs), filepath.Join(registryPath, caching.IndexFileName)))
+	return 0
+}
+
+// indexTable returns a table with a row for each pack of a registry index.
+func indexTable(index *caching.Index) *terminal.Table {
+	table := terminal.NewTable("PACK NAME", "METADATA VERSION", "REFS", "DESCRIPTION")
+	for _, entry := range index.Packs {
+		table.Rows = append(table.Rows, []string{
+			entry.Name,
+			entry.Version,
+			strings.Join(entry.Refs, ", "),
+			entry.Description,
+		})
+	}
+	return table
+}
+
+func (c *RegistryIndexCommand) Flags() *flag.Sets {
+	return c.flagSet(0, nil)
+}
+
+func (c *RegistryIndexCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("")
+}
+
+func (c *RegistryIndexCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *RegistryIndexCommand) Synopsis() string {
+	return "Generate the index file of a registry."
+}
+
+func (c *RegistryIndexCommand) Help() string {
+	c.Example = `
+	# Generate the index of the registry in the current directory.
+	nomad-pack registry index .
+	`
+	return formatHelp(`
+	Usage: nomad-pack registry index <path>
+
+	Generate the index.json file of the registry at path, listing the name,
+	version, description, integration flags, variables and checksum of each
+	pack in its packs directory. If the registry is a git repository, the tags
+	containing each pack are listed as its refs.
+
+	When a registry publishes an index.json at its root, the packs it lists are
+	not loaded to be displayed by "nomad-pack list" and
+	"nomad-pack registry list" or to be searched by "nomad-pack search", and
+	they can be listed before the registry is
+	added with "nomad-pack registry list --index". The index should be
+	regenerated and committed along with any change to the packs.
+
+` + c.GetExample() + c.Flags().Help())
+}
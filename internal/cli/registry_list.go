@@ -7,6 +7,7 @@ import (
 	"github.com/posener/complete"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
 )
 
@@ -14,13 +15,17 @@ import (
 // to the current machine.
 type RegistryListCommand struct {
 	*baseCommand
+	index string
 }
 
 func (c *RegistryListCommand) Run(args []string) int {
 	c.cmdKey = "registry list"
 
+	flagSet := c.Flags()
+
 	if err := c.Init(
 		WithNoArgs(args),
+		WithFlags(flagSet),
 		WithNoConfig(),
 		WithClient(false),
 	); err != nil {
@@ -29,6 +34,11 @@ func (c *RegistryListCommand) Run(args []string) int {
 		return 1
 	}
 
+	// List the packs of a registry index instead of the cached registries.
+	if c.index != "" {
+		return c.listIndex()
+	}
+
 	// Get the global cache dir - may be configurable in the future, so using this
 	// helper function rather than a direct reference to the CONST.
 	globalCache, err := caching.NewCache(&caching.CacheConfig{
@@ -72,8 +82,39 @@ func (c *RegistryListCommand) Run(args []string) int {
 	return 0
 }
 
+// listIndex lists the packs of the index passed with the --index flag.
+func (c *RegistryListCommand) listIndex() int {
+	errorContext := errors.NewUIErrorContext()
+	errorContext.Add(errors.UIContextPrefixRegistryIndex, c.index)
+
+	index, err := caching.FetchIndex(c.index)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to read registry index", errorContext.GetAll()...)
+		return 1
+	}
+
+	if len(index.Packs) == 0 {
+		c.ui.Output("No packs present in the registry index.")
+		return 0
+	}
+	c.ui.Table(indexTable(index))
+	return 0
+}
+
 func (c *RegistryListCommand) Flags() *flag.Sets {
-	return c.flagSet(0, nil)
+	return c.flagSet(0, func(set *flag.Sets) {
+		f := set.NewSet("Registry Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "index",
+			Target:  &c.index,
+			Default: "",
+			Usage: `Path or URL of the index.json of a registry, or path of a
+					registry directory. When set, the packs listed in the
+					index are displayed instead of the cached registries, so
+					the packs of a registry can be listed before adding it.`,
+		})
+	})
 }
 
 func (c *RegistryListCommand) AutocompleteArgs() complete.Predictor {
@@ -92,9 +133,13 @@ func (c *RegistryListCommand) Help() string {
 	c.Example = `
 	# List all configured registries
 	nomad-pack registry list
+
+	# List the packs of a registry before adding it
+	nomad-pack registry list \
+		--index=https://raw.githubusercontent.com/hashicorp/nomad-pack-community-registry/main/index.json
 	`
 	return formatHelp(`
-	Usage: nomad-pack registry list
+	Usage: nomad-pack registry list [options]
 
 	List nomad pack registries.
 
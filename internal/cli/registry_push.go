@@ -0,0 +1,166 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci"
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature"
+)
+
+// RegistryPushCommand publishes a pack directory to an OCI registry, so it
+// can be added to the cache of other users with "registry add".
+type RegistryPushCommand struct {
+	*baseCommand
+	ref     string
+	signKey string
+}
+
+func (c *RegistryPushCommand) Run(args []string) int {
+	c.cmdKey = "registry push"
+	flagSet := c.Flags()
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := c.Init(
+		WithExactArgs(2, args),
+		WithFlags(flagSet),
+		WithNoConfig(),
+		WithClient(false),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	packPath, destination := c.args[0], c.args[1]
+
+	errorContext := errors.NewUIErrorContext()
+	errorContext.Add(errors.UIContextPrefixPackPath, packPath)
+	errorContext.Add(errors.UIContextPrefixOCIReference, destination)
+
+	p, err := loader.Load(packPath)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to load pack", errorContext.GetAll()...)
+		return 1
+	}
+	if err := p.Validate(); err != nil {
+		c.ui.ErrorWithContext(err, "failed to validate pack", errorContext.GetAll()...)
+		return 1
+	}
+
+	namespace, err := oci.ParseReference(destination)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "invalid destination", errorContext.GetAll()...)
+		return 1
+	}
+	if namespace.Tag != "" {
+		c.ui.ErrorWithContext(errors.New("destination cannot include a tag, use --ref instead"),
+			"invalid destination", errorContext.GetAll()...)
+		return 1
+	}
+
+	ref, err := namespace.Pack(p.Name(), c.ref)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "invalid destination", errorContext.GetAll()...)
+		return 1
+	}
+
+	client := &oci.Client{
+		Username: os.Getenv(EnvRegistryUsername),
+		Password: os.Getenv(EnvRegistryPassword),
+	}
+	if c.signKey != "" {
+		key, err := signature.ReadSigningKey(c.signKey, os.Getenv(EnvSigningPassphrase))
+		if err != nil {
+			c.ui.ErrorWithContext(err, "failed to read signing key", errorContext.GetAll()...)
+			return 1
+		}
+		client.Sign = func(digest []byte) (string, error) {
+			return signature.Sign(key, digest)
+		}
+	}
+	digest, err := client.Push(context.Background(), ref, packPath, oci.PackAnnotations(p.Metadata, time.Now()))
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to push pack", errorContext.GetAll()...)
+		return 1
+	}
+
+	c.ui.Success(fmt.Sprintf("Pushed pack %q to %s", p.Name(), ref))
+	c.ui.Info(fmt.Sprintf("Digest: %s", digest))
+	return 0
+}
+
+func (c *RegistryPushCommand) Flags() *flag.Sets {
+	return c.flagSet(0, func(set *flag.Sets) {
+		f := set.NewSet("Registry Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "ref",
+			Target:  &c.ref,
+			Default: "",
+			Usage: `Tag to push the pack to, such as the version of the pack.
+					Defaults to latest.`,
+		})
+
+		f.StringVar(&flag.StringVar{
+			Name:    "sign-key",
+			Target:  &c.signKey,
+			Default: "",
+			Usage: `Path to an armored OpenPGP private key to sign the pack
+					with. If the key is encrypted, its passphrase is read from
+					the NOMAD_PACK_SIGNING_KEY_PASSPHRASE environment variable.`,
+		})
+	})
+}
+
+func (c *RegistryPushCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("")
+}
+
+func (c *RegistryPushCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *RegistryPushCommand) Synopsis() string {
+	return "Push a pack to an OCI registry."
+}
+
+func (c *RegistryPushCommand) Help() string {
+	c.Example = `
+	# Push the pack in the current directory to the latest tag.
+	nomad-pack registry push . oci://registry.example.com/packs
+
+	# Push a pack at its version.
+	nomad-pack registry push ./hello_world oci://registry.example.com/packs --ref=v1.0.0
+
+	# Push a signed pack.
+	nomad-pack registry push ./hello_world oci://registry.example.com/packs --sign-key=./release.asc
+	`
+	return formatHelp(`
+	Usage: nomad-pack registry push <path> <destination> [options]
+
+	Push a pack directory to a namespace of an OCI registry. The pack is pushed
+	to the repository named after the pack within the namespace, as an
+	artifact with the content of the pack as its single layer and the fields
+	of its metadata.hcl as annotations. A namespace can be added as a registry
+	with "nomad-pack registry add".
+
+	Credentials for the OCI registry are read from the
+	NOMAD_PACK_REGISTRY_USERNAME and NOMAD_PACK_REGISTRY_PASSWORD environment
+	variables.
+
+	A pack pushed with --sign-key carries a signature of its content, which is
+	verified when the pack is added by users who trust the key.
+
+` + c.GetExample() + c.Flags().Help())
+}
@@ -5,6 +5,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/posener/complete"
@@ -97,11 +98,20 @@ func (c *RegistryUpdateCommand) Run(args []string) int {
 	source := existingRegistry.Source
 	errorContext.Add(errors.UIContextPrefixGitRegistryURL, source)
 
+	keys, err := trustedKeys()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to read trusted keys", errorContext.GetAll()...)
+		return 1
+	}
+
 	newRegistry, err := globalCache.Add(&caching.AddOpts{
 		RegistryName: c.name,
 		Source:       source,
 		PackName:     c.target,
 		Ref:          c.ref,
+		Username:     os.Getenv(EnvRegistryUsername),
+		Password:     os.Getenv(EnvRegistryPassword),
+		TrustedKeys:  keys,
 	})
 	if err != nil {
 		c.ui.ErrorWithContext(err, "failed to update registry")
@@ -214,5 +224,8 @@ func (c *RegistryUpdateCommand) Help() string {
 	the registry name is required. The registry must have been previously
 	added using "nomad-pack registry add".
 
+	As with "nomad-pack registry add", the registry must be signed by one of
+	the keys in NOMAD_PACK_TRUSTED_KEYS when the environment variable is set.
+
 ` + c.GetExample() + c.Flags().Help())
 }
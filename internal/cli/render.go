@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
 	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
 	"github.com/hashicorp/nomad-pack/terminal"
 )
@@ -37,6 +39,10 @@ type RenderCommand struct {
 	// standard output.
 	renderToDir string
 
+	// compareToDir is the path of previously rendered job files, as written
+	// by renderToDir, to compare the rendered job files with.
+	compareToDir string
+
 	// noRenderAuxFiles is a boolean flag to control whether we should also render
 	// auxiliary files inside templates/
 	noRenderAuxFiles bool
@@ -163,6 +169,67 @@ func writeFile(c *RenderCommand, path string, content string) error {
 	return nil
 }
 
+// compareRenders compares the renders with the files found within dir, laid
+// out as written by toFile, and returns a unified diff for each render which
+// differs. Renders without a file, and files without a render, are diffed
+// against an empty file.
+func compareRenders(dir string, renders []Render) ([]string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --compare-to path: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, errors.New("--compare-to must be a directory")
+	}
+
+	previous := make(map[string]string)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		previous[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previously rendered files: %w", err)
+	}
+
+	var diffs []string
+	rendered := make(map[string]struct{}, len(renders))
+
+	for _, render := range renders {
+		rendered[render.Name] = struct{}{}
+
+		fromName := path.Join(dir, render.Name)
+		content, ok := previous[render.Name]
+		if !ok {
+			fromName = os.DevNull
+		}
+		if diff := helper.UnifiedDiff(fromName, render.Name, content, render.Content); diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	removed := maps.Keys(previous)
+	slices.Sort(removed)
+	for _, name := range removed {
+		if _, ok := rendered[name]; ok {
+			continue
+		}
+		diffs = append(diffs, helper.UnifiedDiff(path.Join(dir, name), os.DevNull, previous[name], ""))
+	}
+
+	return diffs, nil
+}
+
 // rangeRenders populates a slice of `Render` (rendered templates) such that the
 // target slice is sorted by Pack ID, Filename.
 func rangeRenders(subj map[string]string, target *[]Render) {
@@ -328,6 +395,21 @@ func (c *RenderCommand) Run(args []string) int {
 		}
 	}
 
+	// Compare the renders before writing any files, so the previous renders
+	// can be replaced by passing the same directory to --to-dir. Only the
+	// differences are output, rather than the renders themselves.
+	var diffs []string
+	if c.compareToDir != "" {
+		diffs, err = compareRenders(c.compareToDir, renders)
+		if err != nil {
+			c.ui.ErrorWithContext(err, "failed to compare renders", errorContext.GetAll()...)
+			return 1
+		}
+		for _, diff := range diffs {
+			c.ui.Output("%s", diff)
+		}
+	}
+
 	// Output the renders. Output the files first if enabled so that any renders
 	// that display will also have been written to disk.
 	for _, render := range renders {
@@ -341,7 +423,17 @@ func (c *RenderCommand) Run(args []string) int {
 				return 1
 			}
 		}
-		render.toTerminal(c)
+		if c.compareToDir == "" {
+			render.toTerminal(c)
+		}
+	}
+
+	if c.compareToDir != "" {
+		if len(diffs) > 0 {
+			c.ui.Error(fmt.Sprintf("%d rendered file(s) differ from %s", len(diffs), c.compareToDir))
+			return 1
+		}
+		c.ui.Success(fmt.Sprintf("Rendered files match %s", c.compareToDir))
 	}
 
 	return 0
@@ -404,6 +496,17 @@ func (c *RenderCommand) Flags() *flag.Sets {
 			},
 			Shorthand: "o",
 		})
+
+		f.StringVar(&flag.StringVar{
+			Name:    "compare-to",
+			Target:  &c.compareToDir,
+			Default: "",
+			Usage: `Path of a directory of previously rendered files, as written
+					by --to-dir, to compare the rendered files with. A unified
+					diff of each file which differs is output instead of the
+					rendered files, and the command exits non-zero if any
+					file differs.`,
+		})
 	})
 }
 
@@ -437,6 +540,10 @@ func (c *RenderCommand) Help() string {
 	# Render a pack under development from the filesystem - supports current
 	# working directory or relative path
 	nomad-pack render .
+
+	# Compare an example pack with a previous render written with --to-dir,
+	# exiting non-zero if any rendered file has changed.
+	nomad-pack render example --var-file="./prod.hcl" --compare-to ~/out
 	`
 
 	return formatHelp(`
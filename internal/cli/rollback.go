@@ -0,0 +1,285 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/release"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/runner/job"
+)
+
+type RollbackCommand struct {
+	*baseCommand
+	revision  int
+	jobConfig *job.CLIConfig
+
+	// runHooks re-runs the hook jobs of the release, which are otherwise
+	// left out of the rollback.
+	runHooks bool
+}
+
+func (c *RollbackCommand) Run(args []string) int {
+	c.cmdKey = "rollback" // Add cmdKey here to print out helpUsageMessage on Init error
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := c.Init(
+		WithExactArgs(1, args),
+		WithFlags(c.Flags()),
+		WithNoConfig(),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	c.deploymentName = c.args[0]
+
+	errorContext := errors.NewUIErrorContext()
+	errorContext.Add(errors.UIContextPrefixDeploymentName, c.deploymentName)
+
+	client, err := c.getAPIClient()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to initialize client", errorContext.GetAll()...)
+		return 1
+	}
+
+	store := newReleaseStore(c.baseCommand, client)
+
+	target, err := c.targetRelease(store)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to find release", errorContext.GetAll()...)
+		return 1
+	}
+
+	errorContext.Add(errors.UIContextPrefixRevision, strconv.Itoa(target.Revision))
+	errorContext.Add(errors.UIContextPrefixPackName, target.PackName)
+	errorContext.Add(errors.UIContextPrefixPackRef, target.PackRef)
+
+	if target.SecretsOmitted {
+		err = fmt.Errorf("revision %d was rendered with variables read from a source of secrets, so its templates were not stored; run the pack again to redeploy it", target.Revision)
+		c.ui.ErrorWithContext(err, "failed to roll back", errorContext.GetAll()...)
+		return 1
+	}
+
+	c.ui.Info(fmt.Sprintf("Rolling back deployment %q to revision %d (pack %s, ref %s, recorded %s)",
+		c.deploymentName, target.Revision, target.PackName, target.PackRef, formatTime(target.Timestamp)))
+
+	// Re-use the runner configuration recorded with the release, so the jobs
+	// carry exactly the same nomad-pack meta as when they were first deployed.
+	depConfig := runner.Config{
+		PackName:       target.PackName,
+		PathPath:       target.PackPath,
+		PackRef:        target.PackRef,
+		DeploymentName: target.DeploymentName,
+		RegistryName:   target.RegistryName,
+	}
+
+	rollbackDeployer, err := generateRunner(client, "job", c.jobConfig, &depConfig)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to generate deployer", errorContext.GetAll()...)
+		return 1
+	}
+
+	// Hooks such as database migrations are not safe to repeat against the
+	// current state, so they are only run again when asked for.
+	templates := target.Templates
+	if !c.runHooks {
+		templates = make(map[string]string, len(target.Templates))
+		for name, tpl := range target.Templates {
+			if job.IsHookTemplate(name) {
+				continue
+			}
+			templates[name] = tpl
+		}
+	}
+	rollbackDeployer.SetTemplates(templates)
+
+	length := shortId
+	if c.jobConfig.RunConfig.Verbose {
+		length = fullId
+	}
+	rollbackDeployer.SetStageWaiter(newStageWaiter(c.Ctx, c.ui, client, length, c.jobConfig.RunConfig.StageTimeout))
+
+	if validateErrs := rollbackDeployer.ParseTemplates(); validateErrs != nil {
+		for _, validateErr := range validateErrs {
+			validateErr.Context.Append(errorContext)
+			c.ui.ErrorWithContext(validateErr.Err, validateErr.Subject, validateErr.Context.GetAll()...)
+		}
+		return 1
+	}
+
+	if canonicalizeErrs := rollbackDeployer.CanonicalizeTemplates(); canonicalizeErrs != nil {
+		for _, canonicalizeErr := range canonicalizeErrs {
+			canonicalizeErr.Context.Append(errorContext)
+			c.ui.ErrorWithContext(canonicalizeErr.Err, canonicalizeErr.Subject, canonicalizeErr.Context.GetAll()...)
+		}
+		return 1
+	}
+
+	// The policy rules may have changed since the release was deployed, so
+	// its jobs are checked again before they are re-registered.
+	if c.policyDir != "" && !checkPolicies(c.ui, rollbackDeployer, c.policyDir, errorContext) {
+		return 1
+	}
+
+	if conflictErrs := rollbackDeployer.CheckForConflicts(errorContext); conflictErrs != nil {
+		for _, conflictErr := range conflictErrs {
+			c.ui.ErrorWithContext(conflictErr.Err, conflictErr.Subject, conflictErr.Context.GetAll()...)
+		}
+		return 1
+	}
+
+	if deployErr := rollbackDeployer.Deploy(c.ui, errorContext); deployErr != nil {
+		c.ui.ErrorWithContext(deployErr.Err, deployErr.Subject, deployErr.Context.GetAll()...)
+		return 1
+	}
+
+	if !c.jobConfig.RunConfig.Detach {
+		mon := newMonitor(c.Ctx, c.ui, client, length)
+		if exitCode := mon.monitor(rollbackDeployer.EvalIDs()); exitCode != 0 {
+			return exitCode
+		}
+	}
+
+	// The rollback is itself recorded as a new release, so history stays
+	// linear and a rollback can be undone in the same way as an upgrade.
+	rel, err := recordRelease(c.baseCommand, client, &depConfig, rollbackDeployer, target.Templates, target.Variables, false, target.Revision)
+	if err != nil {
+		c.ui.Warning(fmt.Sprintf("Failed to record release of deployment %q: %s", c.deploymentName, err))
+	} else {
+		c.ui.Info(fmt.Sprintf("Recorded release %d of deployment %q", rel.Revision, c.deploymentName))
+	}
+
+	c.ui.Success(fmt.Sprintf("Deployment %q successfully rolled back to revision %d", c.deploymentName, target.Revision))
+	return 0
+}
+
+// targetRelease returns the release to roll back to. Without --to, this is
+// the revision prior to the latest one.
+func (c *RollbackCommand) targetRelease(store *release.Store) (*release.Release, error) {
+	if c.revision > 0 {
+		return store.Get(c.deploymentName, c.revision)
+	}
+
+	releases, err := store.List(c.deploymentName)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) < 2 {
+		return nil, fmt.Errorf("deployment %q has no previous revision to roll back to", c.deploymentName)
+	}
+
+	return releases[len(releases)-2], nil
+}
+
+func (c *RollbackCommand) Flags() *flag.Sets {
+	return c.flagSet(flagSetNomadClient|flagSetPolicy, func(set *flag.Sets) {
+		f := set.NewSet("Rollback Options")
+
+		c.jobConfig = &job.CLIConfig{
+			RunConfig: &job.RunCLIConfig{},
+		}
+
+		f.IntVar(&flag.IntVar{
+			Name:    "to",
+			Target:  &c.revision,
+			Default: 0,
+			Usage: `The revision of the deployment to roll back to, as shown by
+					the history command. If not set, the deployment is rolled
+					back to the revision prior to the latest one.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "run-hooks",
+			Target:  &c.runHooks,
+			Default: false,
+			Usage: `Run the pre-run and post-run hook jobs of the release, as a run
+					of the pack does. By default, hooks are not run again when
+					rolling back.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "policy-override",
+			Target:  &c.jobConfig.RunConfig.PolicyOverride,
+			Default: false,
+			Usage: `Sets the flag to force override any soft mandatory Sentinel
+					policies.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "detach",
+			Target:  &c.jobConfig.RunConfig.Detach,
+			Default: false,
+			Usage: `If set, deployment monitoring will be skipped and the command
+					will return immediately after registration.`,
+		})
+
+		f.DurationVar(&flag.DurationVar{
+			Name:    "stage-timeout",
+			Target:  &c.jobConfig.RunConfig.StageTimeout,
+			Default: 30 * time.Minute,
+			Usage: `The maximum time to wait for each stage of a pack ordered
+					with pack.depends_on, and each pre-run or post-run hook, to
+					complete before failing the rollback. Set to 0 to wait without a
+					limit.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "verbose",
+			Target:  &c.jobConfig.RunConfig.Verbose,
+			Default: false,
+			Usage: `If set, deployment monitoring will show verbose output
+					including allocation details.`,
+		})
+	})
+}
+
+func (c *RollbackCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *RollbackCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *RollbackCommand) Help() string {
+	c.Example = `
+	# Roll back the deployment "example" to the revision before the latest one
+	nomad-pack rollback example
+
+	# Roll back the deployment "dev" to revision 3
+	nomad-pack rollback dev --to=3
+
+	# Roll back the deployment "dev", running its hook jobs again
+	nomad-pack rollback dev --run-hooks
+	`
+
+	return formatHelp(`
+	Usage: nomad-pack rollback <deployment-name> [options]
+
+	Re-register the jobs of a previously recorded release of a pack deployment.
+
+	Every successful run records a release containing the rendered jobs, so
+	rolling back does not require the original pack, ref, or variable files.
+	The rollback is recorded as a new release of the deployment.
+
+	Only the jobs are rolled back. Hook jobs are not run again unless
+	--run-hooks is set, and Nomad Variables declared by the pack keep their
+	current values.
+
+` + c.GetExample() + c.Flags().Help())
+}
+
+// Synopsis satisfies the Synopsis function of the cli.Command interface.
+func (c *RollbackCommand) Synopsis() string {
+	return "Roll back a pack deployment to a previous release"
+}
@@ -5,12 +5,16 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/posener/complete"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/planfile"
+	"github.com/hashicorp/nomad-pack/internal/pkg/release"
 	"github.com/hashicorp/nomad-pack/internal/runner"
 	"github.com/hashicorp/nomad-pack/internal/runner/job"
 )
@@ -35,13 +39,30 @@ func (c *RunCommand) Run(args []string) int {
 		c.ui.Info(c.helpUsageMessage())
 		return 1
 	}
-	return c.run()
+
+	exitCode := c.run()
+	emitResult(c.baseCommand, "run", exitCode, exitCode == 0)
+	return exitCode
 }
 
 // run is the implementation of this command. It is used to ensure the args are
 // pulled from the RunCommand as these are parsed with the Run.
 func (c *RunCommand) run() int {
 
+	// An atomic run relies on monitoring the deployments to decide whether to
+	// revert, which cannot happen when detached.
+	if c.jobConfig.RunConfig.Atomic && c.jobConfig.RunConfig.Detach {
+		c.ui.ErrorWithContext(errors.New("--atomic cannot be used with --detach"), ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	// A plan saved by the plan command is applied as it is, rather than
+	// rendering a pack.
+	if planfile.IsPlanFile(c.args[0]) {
+		return c.runPlanFile(c.args[0])
+	}
+
 	c.packConfig.Name = c.args[0]
 
 	// Set the packConfig defaults if necessary and generate our UI error context.
@@ -53,6 +74,14 @@ func (c *RunCommand) run() int {
 		return 1
 	}
 
+	// refuse to deploy a cached pack which has been modified since it was added
+	if err := caching.VerifyPackChecksum(c.packConfig); err != nil {
+		c.ui.ErrorWithContext(err, "failed to verify pack", errorContext.GetAll()...)
+		c.ui.Info(fmt.Sprintf(`Run "nomad-pack registry update %s --target=%s --ref=%s" to restore the pack.`,
+			c.packConfig.Registry, c.packConfig.Name, c.packConfig.Ref))
+		return 1
+	}
+
 	// If no deploymentName set default to pack@ref
 	c.deploymentName = getDeploymentName(c.baseCommand, c.packConfig)
 	errorContext.Add(errors.UIContextPrefixDeploymentName, c.deploymentName)
@@ -90,9 +119,6 @@ func (c *RunCommand) run() int {
 		return 1
 	}
 
-	renderedParents := r.ParentRenders()
-	renderedDeps := r.DependentRenders()
-
 	// TODO: Refactor to use PackConfig. Maybe PackConfig should be in a more common
 	// pkg than cache, or maybe it's ok for runner to depend on the cache.
 	// Need to discuss with jrasell.
@@ -104,22 +130,144 @@ func (c *RunCommand) run() int {
 		RegistryName:   c.packConfig.Registry,
 	}
 
+	d := &runDeployment{
+		config:    &depConfig,
+		templates: deploymentTemplates(r),
+		output:    packManager.ProcessOutputTemplate,
+	}
+
+	if r.ParsedVariables() != nil {
+		if d.nomadVariables, err = nomadVariables(r.ParsedVariables()); err != nil {
+			c.ui.ErrorWithContext(err, "failed to convert Nomad Variables", errorContext.GetAll()...)
+			return 1
+		}
+
+		// Secrets with leases are injected at render time, so warn that the
+		// jobs will outlive them.
+		for _, warning := range leaseWarnings(r.ParsedVariables().Leases(), time.Now()) {
+			c.ui.Warning(warning)
+		}
+		d.secrets = r.ParsedVariables().HasSecrets()
+	}
+	d.variables, d.variablesErr = releaseVariables(r.ParsedVariables())
+
+	if c.packConfig.Registry == caching.DevRegistryName {
+		d.successMsg = fmt.Sprintf("Pack successfully deployed. Use %s to manage this deployed instance with plan, stop, destroy, or info", c.packConfig.SourcePath)
+	} else {
+		d.successMsg = fmt.Sprintf("Pack successfully deployed. Use %s with --ref=%s to manage this deployed instance with plan, stop, destroy, or info", c.packConfig.Name, c.packConfig.Ref)
+	}
+
+	return c.deploy(client, d, errorContext)
+}
+
+// runDeployment is what the run command deploys, which is either rendered
+// from a pack or read from a saved plan.
+type runDeployment struct {
+	config    *runner.Config
+	templates map[string]string
+
+	// variables are recorded with the release of the deployment. If they
+	// could not be converted, variablesErr is set and no release is recorded.
+	variables    map[string]any
+	variablesErr error
+
+	// secrets is set when any variable was read from a source of secrets, so
+	// the templates are left out of the release.
+	secrets bool
+
+	nomadVariables []*planfile.NomadVariable
+
+	// output returns the rendered output template of the pack.
+	output func() (string, error)
+
+	successMsg string
+}
+
+// runPlanFile applies the plan saved at path. The jobs are registered from
+// the planned templates, each only if its modify index is unchanged since
+// it was planned.
+func (c *RunCommand) runPlanFile(path string) int {
+	errorContext := errors.NewUIErrorContext()
+	errorContext.Add(errors.UIContextPrefixPlanFile, path)
+
+	plan, err := planfile.Read(path)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to read plan file", errorContext.GetAll()...)
+		return 1
+	}
+
+	// The deployment and the check indexes are fixed by the plan, so flags
+	// which would change them cannot be used.
+	switch {
+	case c.deploymentName != "" && c.deploymentName != plan.DeploymentName:
+		err = fmt.Errorf("--name %q does not match the deployment %q of the plan", c.deploymentName, plan.DeploymentName)
+	case c.jobConfig.RunConfig.CheckIndex != 0:
+		err = errors.New("--check-index cannot be used with a plan file")
+	case c.packConfig.Registry != "" || c.packConfig.Ref != "":
+		err = errors.New("--registry and --ref cannot be used with a plan file")
+	case len(c.vars) > 0 || len(c.varFiles) > 0 || len(c.varSources) > 0:
+		err = errors.New("variables cannot be set when running a plan file, as the plan is already rendered")
+	}
+	if err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags, errorContext.GetAll()...)
+		return 1
+	}
+
+	c.deploymentName = plan.DeploymentName
+	errorContext.Add(errors.UIContextPrefixDeploymentName, c.deploymentName)
+	errorContext.Add(errors.UIContextPrefixPackName, plan.PackName)
+	errorContext.Add(errors.UIContextPrefixPackRef, plan.PackRef)
+
+	client, err := c.getAPIClient()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to initialize client", errorContext.GetAll()...)
+		return 1
+	}
+
+	c.ui.Info(fmt.Sprintf("Applying plan of deployment %q (pack %s, ref %s, planned %s)",
+		plan.DeploymentName, plan.PackName, plan.PackRef, formatTime(plan.Timestamp)))
+
+	c.jobConfig.RunConfig.CheckIndexes = plan.CheckIndexes()
+
+	return c.deploy(client, &runDeployment{
+		config: &runner.Config{
+			PackName:       plan.PackName,
+			PathPath:       plan.PackPath,
+			PackRef:        plan.PackRef,
+			DeploymentName: plan.DeploymentName,
+			RegistryName:   plan.RegistryName,
+		},
+		templates:      plan.Templates,
+		variables:      plan.Variables,
+		secrets:        plan.Secrets,
+		nomadVariables: plan.NomadVariables,
+		output:         func() (string, error) { return plan.Output, nil },
+		successMsg: fmt.Sprintf("Plan successfully applied. Use %s to manage this deployed instance with plan, stop, destroy, or info",
+			plan.PackPath),
+	}, errorContext)
+}
+
+// deploy registers the templates of the deployment with Nomad, monitors the
+// resulting deployments, and records the release.
+func (c *RunCommand) deploy(client *api.Client, d *runDeployment, errorContext *errors.UIErrorContext) int {
+
 	// TODO(jrasell) come up with a better way to pass the appropriate config.
-	runDeployer, err := generateRunner(client, "job", c.jobConfig, &depConfig)
+	runDeployer, err := generateRunner(client, "job", c.jobConfig, d.config)
 	if err != nil {
 		c.ui.ErrorWithContext(err, "failed to generate deployer", errorContext.GetAll()...)
 		return 1
 	}
 
-	// Set the rendered templates on the job deployer.
-	templates := make(map[string]string, r.LenDependentRenders()+r.LenParentRenders())
-	for dn, ds := range renderedDeps {
-		templates[dn] = ds
-	}
-	for pn, ps := range renderedParents {
-		templates[pn] = ps
+	runDeployer.SetTemplates(d.templates)
+
+	// Jobs ordered with depends_on are deployed in stages, waiting for each
+	// stage to complete before the next. This happens even when detached, as
+	// later stages rely on the earlier ones.
+	length := shortId
+	if c.jobConfig.RunConfig.Verbose {
+		length = fullId
 	}
-	runDeployer.SetTemplates(templates)
+	runDeployer.SetStageWaiter(newStageWaiter(c.Ctx, c.ui, client, length, c.jobConfig.RunConfig.StageTimeout))
 
 	// Parse the templates. If we have any error, output this and exit.
 	if validateErrs := runDeployer.ParseTemplates(); validateErrs != nil {
@@ -139,6 +287,12 @@ func (c *RunCommand) run() int {
 		return 1
 	}
 
+	// Check the jobs against the policy rules before anything is submitted
+	// to Nomad.
+	if c.policyDir != "" && !checkPolicies(c.ui, runDeployer, c.policyDir, errorContext) {
+		return 1
+	}
+
 	if conflictErrs := runDeployer.CheckForConflicts(errorContext); conflictErrs != nil {
 		for _, conflictErr := range conflictErrs {
 			c.ui.ErrorWithContext(conflictErr.Err, conflictErr.Subject, conflictErr.Context.GetAll()...)
@@ -154,36 +308,51 @@ func (c *RunCommand) run() int {
 	}
 
 	// create nomad variables
-	if r.ParsedVariables() != nil {
-		if err := createNomadVariables(r.ParsedVariables(), client, c.ui); err != nil {
-			c.ui.Warning("Job is running, but variable creation failed")
-			c.ui.ErrorWithContext(err, "failed to create Nomad Variables", errorContext.GetAll()...)
-			return 1
-		}
+	if err := createNomadVariables(d.nomadVariables, client, c.ui); err != nil {
+		c.ui.Warning("Job is running, but variable creation failed")
+		c.ui.ErrorWithContext(err, "failed to create Nomad Variables", errorContext.GetAll()...)
+		return 1
 	}
 
 	// Monitor deployments unless detach flag is set
 	if !c.jobConfig.RunConfig.Detach {
 		evalIDs := runDeployer.EvalIDs()
-		length := shortId
-		if c.jobConfig.RunConfig.Verbose {
-			length = fullId
-		}
 		mon := newMonitor(c.Ctx, c.ui, client, length)
 		if exitCode := mon.monitor(evalIDs); exitCode != 0 {
+			if c.jobConfig.RunConfig.Atomic {
+				for _, revertErr := range runDeployer.RevertDeployment(c.ui, errorContext) {
+					c.ui.ErrorWithContext(revertErr.Err, revertErr.Subject, revertErr.Context.GetAll()...)
+				}
+
+				// The jobs are reverted, but not the Nomad Variables written
+				// once they were registered.
+				if len(d.nomadVariables) > 0 {
+					c.ui.Warning("Nomad Variables written by this run have not been reverted")
+				}
+			}
 			return exitCode
 		}
 	}
 
-	if c.packConfig.Registry == caching.DevRegistryName {
-		c.ui.Success(fmt.Sprintf("Pack successfully deployed. Use %s to manage this deployed instance with plan, stop, destroy, or info", c.packConfig.SourcePath))
-	} else {
-		c.ui.Success(fmt.Sprintf("Pack successfully deployed. Use %s with --ref=%s to manage this deployed instance with plan, stop, destroy, or info", c.packConfig.Name, c.packConfig.Ref))
+	// Record the release so this deployment can be listed or rolled back to
+	// later. The jobs are already running, so failing to record is not fatal.
+	err = d.variablesErr
+	if err == nil {
+		var rel *release.Release
+		rel, err = recordRelease(c.baseCommand, client, d.config, runDeployer, d.templates, d.variables, d.secrets, 0)
+		if err == nil {
+			c.ui.Info(fmt.Sprintf("Recorded release %d of deployment %q", rel.Revision, c.deploymentName))
+		}
+	}
+	if err != nil {
+		c.ui.Warning(fmt.Sprintf("Failed to record release of deployment %q: %s", c.deploymentName, err))
 	}
 
-	output, err := packManager.ProcessOutputTemplate()
+	c.ui.Success(d.successMsg)
+
+	output, err := d.output()
 	if err != nil {
-		c.ui.ErrorWithContext(err, "failed to render output template", "Pack Name: "+c.packConfig.Name)
+		c.ui.ErrorWithContext(err, "failed to render output template", "Pack Name: "+d.config.PackName)
 		return 1
 	}
 
@@ -195,7 +364,7 @@ func (c *RunCommand) run() int {
 
 // Flags defines the flag.Sets for the operation.
 func (c *RunCommand) Flags() *flag.Sets {
-	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetExternalVarSources, func(set *flag.Sets) {
+	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetExternalVarSources|flagSetPolicy|flagSetOutput, func(set *flag.Sets) {
 		f := set.NewSet("Run Options")
 
 		c.packConfig = &caching.PackConfig{}
@@ -315,7 +484,18 @@ func (c *RunCommand) Flags() *flag.Sets {
 			Target:  &c.jobConfig.RunConfig.Detach,
 			Default: false,
 			Usage: `If set, deployment monitoring will be skipped and the command
-					will return immediately after registration.`,
+					will return immediately after registration. Earlier stages
+					of a pack ordered with pack.depends_on are still waited on.`,
+		})
+
+		f.DurationVar(&flag.DurationVar{
+			Name:    "stage-timeout",
+			Target:  &c.jobConfig.RunConfig.StageTimeout,
+			Default: 30 * time.Minute,
+			Usage: `The maximum time to wait for each stage of a pack ordered
+					with pack.depends_on, and each pre-run or post-run hook, to
+					complete before failing the run. Set to 0 to wait without a
+					limit.`,
 		})
 
 		f.BoolVar(&flag.BoolVar{
@@ -326,6 +506,17 @@ func (c *RunCommand) Flags() *flag.Sets {
 					including allocation details.`,
 		})
 
+		f.BoolVar(&flag.BoolVar{
+			Name:    "atomic",
+			Target:  &c.jobConfig.RunConfig.Atomic,
+			Default: false,
+			Usage: `If set, a failure to register any job in the pack, or a failed
+					deployment of any job, reverts every job in the pack to the
+					version it was at before the run. Jobs that did not exist
+					before the run are purged. Nomad Variables written by the
+					run are not reverted. Cannot be used with --detach.`,
+		})
+
 		f.BoolVar(&flag.BoolVar{
 			Name:    "rollback",
 			Hidden:  true,
@@ -363,13 +554,20 @@ func (c *RunCommand) Help() string {
 	# Run a pack under development from the filesystem - supports current
 	# working directory or relative path
 	nomad-pack run .
+
+	# Apply a plan saved by "nomad-pack plan --out"
+	nomad-pack run plan.json
 	`
 
 	return formatHelp(`
-	Usage: nomad-pack run <pack-name> [options]
+	Usage: nomad-pack run <pack-name | plan-file> [options]
 
 	Install the specified Nomad Pack to a configured Nomad cluster.
 
+	If the argument is a plan file saved by "nomad-pack plan --out", the jobs
+	of the plan are registered exactly as they were planned. Each job is only
+	registered if its job modify index is unchanged since it was planned.
+
 ` + c.GetExample() + c.Flags().Help())
 }
 
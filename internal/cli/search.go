@@ -0,0 +1,121 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/search"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// SearchCommand searches the packs of the registries in the cache.
+type SearchCommand struct {
+	*baseCommand
+	registry string
+}
+
+func (c *SearchCommand) Run(args []string) int {
+	c.cmdKey = "search" // Add cmdKey here to print out helpUsageMessage on Init error
+	flagSet := c.Flags()
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := c.Init(
+		WithMinimumNArgs(1, args),
+		WithFlags(flagSet),
+		WithNoConfig(),
+		WithClient(false),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	query := strings.Join(c.args, " ")
+
+	globalCache, err := caching.NewCache(&caching.CacheConfig{
+		Path:   caching.DefaultCachePath(),
+		Logger: c.ui,
+	})
+	if err != nil {
+		return 1
+	}
+
+	if err = globalCache.Load(); err != nil {
+		return 1
+	}
+
+	var registries []*caching.Registry
+	for _, cachedRegistry := range globalCache.Registries() {
+		if c.registry == "" || cachedRegistry.Name == c.registry {
+			registries = append(registries, cachedRegistry)
+		}
+	}
+
+	results := search.Search(registries, query)
+	if len(results) == 0 {
+		c.ui.Output(fmt.Sprintf("No packs matching %q found in the cache.", query))
+		return 0
+	}
+
+	table := terminal.NewTable("PACK NAME", "METADATA VERSION", "REGISTRY NAME", "MATCHED")
+	for _, result := range results {
+		row := append(packRow(result.Registry, result.Pack), strings.Join(result.Matches, ", "))
+		table.Rows = append(table.Rows, row)
+	}
+	c.ui.Table(table)
+	return 0
+}
+
+func (c *SearchCommand) Flags() *flag.Sets {
+	return c.flagSet(0, func(set *flag.Sets) {
+		f := set.NewSet("Search Options")
+
+		f.StringVar(&flag.StringVar{
+			Name:    "registry",
+			Target:  &c.registry,
+			Default: "",
+			Usage:   `Registry name to search the packs of.`,
+		})
+	})
+}
+
+func (c *SearchCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *SearchCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *SearchCommand) Synopsis() string {
+	return "Search the packs of the registries in the cache."
+}
+
+func (c *SearchCommand) Help() string {
+	c.Example = `
+	# Search for packs related to Traefik
+	nomad-pack search traefik
+
+	# Search the packs of the community registry with a variable about consul
+	nomad-pack search consul service --registry=community
+	`
+	return formatHelp(`
+	Usage: nomad-pack search <term> [<term>...] [options]
+
+	Search the packs of the registries in the cache. A pack matches when every
+	term is found, ignoring case, in its name, description, application URL,
+	integration flags, or the names and descriptions of its variables.
+
+	Results are ranked by where the terms matched, with matches on the pack
+	name first, followed by integration flags, descriptions, variables and
+	application URLs. The fields which matched are shown alongside each pack.
+
+` + c.GetExample() + c.Flags().Help())
+}
@@ -0,0 +1,123 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// newStageWaiter returns the runner.StageWaitFunc used between the stages of
+// an ordered pack deployment. It monitors the stage's evaluations and
+// deployments in the same way as the final stage is monitored, and then waits
+// for the allocations of any batch jobs to complete, since jobs in later
+// stages such as an API depending on a schema migration rely on the batch
+// work having finished rather than just being placed. A stage which has not
+// completed within timeout fails, unless timeout is zero.
+func newStageWaiter(ctx context.Context, ui terminal.UI, client *api.Client, length int, timeout time.Duration) runner.StageWaitFunc {
+	return func(evalIDs []string) error {
+		stageCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			stageCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		defer cancel()
+
+		err := waitForStage(stageCtx, ui, client, evalIDs, length)
+		if err != nil && errors.Is(stageCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("stage did not complete within %s", timeout)
+		}
+		return err
+	}
+}
+
+// waitForStage monitors the evaluations of a stage, and waits for the
+// allocations of its batch jobs to complete.
+func waitForStage(ctx context.Context, ui terminal.UI, client *api.Client, evalIDs []string, length int) error {
+	mon := newMonitor(ctx, ui, client, length)
+	if exitCode := mon.monitor(evalIDs); exitCode != 0 {
+		return fmt.Errorf("monitoring exited with code %d", exitCode)
+	}
+
+	for _, evalID := range evalIDs {
+		if err := waitForBatchEval(ctx, ui, client, evalID, length); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForBatchEval waits for every allocation placed by the evaluation to
+// complete when the evaluation is for a batch or sysbatch job. Evaluations of
+// other job types return immediately.
+func waitForBatchEval(ctx context.Context, ui terminal.UI, client *api.Client, evalID string, length int) error {
+	eval, _, err := client.Evaluations().Info(evalID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read evaluation %q: %w", limit(evalID, length), err)
+	}
+	if eval.Type != api.JobTypeBatch && eval.Type != api.JobTypeSysbatch {
+		return nil
+	}
+
+	allocs, _, err := client.Evaluations().Allocations(evalID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read allocations of evaluation %q: %w", limit(evalID, length), err)
+	}
+
+	ui.Info(fmt.Sprintf("%s: Waiting for %d allocation(s) of batch job %q to complete",
+		formatTime(time.Now()), len(allocs), eval.JobID))
+
+	for _, alloc := range allocs {
+		if err := waitForAllocCompletion(ctx, ui, client, alloc.ID, length); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForAllocCompletion polls the allocation until it completes. Failed
+// allocations which Nomad reschedules are followed to their replacement, so
+// a batch job only fails the stage once it has exhausted its reschedule
+// policy.
+func waitForAllocCompletion(ctx context.Context, ui terminal.UI, client *api.Client, allocID string, length int) error {
+	for {
+		alloc, _, err := client.Allocations().Info(allocID, nil)
+		if err != nil {
+			return fmt.Errorf("failed to read allocation %q: %w", limit(allocID, length), err)
+		}
+
+		switch alloc.ClientStatus {
+		case api.AllocClientStatusComplete:
+			ui.Info(fmt.Sprintf("%s: Allocation %q of job %q completed",
+				formatTime(time.Now()), limit(alloc.ID, length), alloc.JobID))
+			return nil
+
+		case api.AllocClientStatusFailed, api.AllocClientStatusLost:
+			if alloc.NextAllocation != "" {
+				ui.Info(fmt.Sprintf("%s: Allocation %q of job %q %s, following replacement %q",
+					formatTime(time.Now()), limit(alloc.ID, length), alloc.JobID, alloc.ClientStatus,
+					limit(alloc.NextAllocation, length)))
+				allocID = alloc.NextAllocation
+				continue
+			}
+
+			// A follow-up evaluation means a reschedule is still pending.
+			if alloc.FollowupEvalID == "" {
+				return fmt.Errorf("allocation %q of job %q %s", limit(alloc.ID, length), alloc.JobID, alloc.ClientStatus)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(updateWait):
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/testui"
+)
+
+func TestStageWaiter_Timeout(t *testing.T) {
+	// The evaluation of a batch job places an allocation which never
+	// completes.
+	responses := map[string]any{
+		"/v1/evaluation/eval1": &api.Evaluation{
+			ID: "eval1", Type: api.JobTypeBatch, JobID: "migrate", Status: api.EvalStatusComplete,
+		},
+		"/v1/evaluation/eval1/allocations": []*api.AllocationListStub{
+			{ID: "alloc1", JobID: "migrate", ClientStatus: api.AllocClientStatusPending},
+		},
+		"/v1/allocation/alloc1": &api.Allocation{
+			ID: "alloc1", JobID: "migrate", ClientStatus: api.AllocClientStatusRunning,
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		must.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := api.NewClient(&api.Config{Address: server.URL})
+	must.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &stdout, &stderr)
+
+	wait := newStageWaiter(context.Background(), ui, client, shortId, 100*time.Millisecond)
+	err = wait([]string{"eval1"})
+	must.EqError(t, err, "stage did not complete within 100ms")
+	must.StrContains(t, stdout.String(), `Waiting for 1 allocation(s) of batch job "migrate" to complete`)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
 	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
 	"github.com/hashicorp/nomad-pack/internal/pkg/renderer"
+	"github.com/hashicorp/nomad-pack/terminal"
 )
 
 type StopCommand struct {
@@ -28,7 +29,7 @@ type StopCommand struct {
 	Validation ValidationFn
 }
 
-func (c *StopCommand) Run(args []string) int {
+func (c *StopCommand) Run(args []string) (exitCode int) {
 	var err error
 
 	c.cmdKey = "stop" // Add cmd key here so help text is available in Init
@@ -56,6 +57,10 @@ func (c *StopCommand) Run(args []string) int {
 		stoppedOrDestroyed = "destroyed"
 	}
 
+	defer func() {
+		emitResult(c.baseCommand, stopOrDestroy, exitCode, exitCode == 0)
+	}()
+
 	c.packConfig.Name = c.args[0]
 
 	// Set the packConfig defaults if necessary and generate our UI error context.
@@ -186,6 +191,13 @@ func (c *StopCommand) Run(args []string) int {
 			c.ui.Info(fmt.Sprintf("Evaluation %q submitted for job %q", evalID, *job.ID))
 			evalIDs = append(evalIDs, evalID)
 		}
+		terminal.Event(c.ui, terminal.EventJobDeregistered, terminal.LevelInfo,
+			fmt.Sprintf("Job %q deregistered", *job.ID), &jobDeregisteredEvent{
+				Deployment: c.deploymentName,
+				JobID:      *job.ID,
+				EvalID:     evalID,
+				Purged:     c.purge,
+			})
 
 		stoppedJobs = append(stoppedJobs, *job.Name)
 	}
@@ -261,6 +273,15 @@ func (c *StopCommand) checkForConflicts(client *api.Client, job *api.Job) error
 	return nil
 }
 
+// jobDeregisteredEvent is the data of the event emitted for each job stopped
+// or destroyed.
+type jobDeregisteredEvent struct {
+	Deployment string `json:"deployment"`
+	JobID      string `json:"job_id"`
+	EvalID     string `json:"eval_id,omitempty"`
+	Purged     bool   `json:"purged"`
+}
+
 // TODO: Add interactive support
 func (c *StopCommand) confirmStop() bool {
 	// TODO: Confirm the stop if the job was a prefix match
@@ -277,7 +298,7 @@ func (c *StopCommand) lengthForVerbose() int {
 }
 
 func (c *StopCommand) Flags() *flag.Sets {
-	return c.flagSet(flagSetOperation|flagSetNomadClient, func(set *flag.Sets) {
+	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetOutput, func(set *flag.Sets) {
 		c.packConfig = &caching.PackConfig{}
 
 		f := set.NewSet("Stop Options")
@@ -0,0 +1,588 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/posener/complete"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/flag"
+	"github.com/hashicorp/nomad-pack/internal/pkg/manager"
+	"github.com/hashicorp/nomad-pack/internal/pkg/packtest"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/runner/job"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+type TestCommand struct {
+	*baseCommand
+	packConfig   *caching.PackConfig
+	jobConfig    *job.CLIConfig
+	unit         bool
+	updateGolden bool
+}
+
+func (c *TestCommand) Run(args []string) int {
+	c.cmdKey = "test" // Add cmdKey here to print out helpUsageMessage on Init error
+
+	// Initialize. If we fail, we just exit since Init handles the UI.
+	if err := c.Init(
+		WithExactArgs(1, args),
+		WithFlags(c.Flags()),
+		WithNoConfig(),
+	); err != nil {
+		c.ui.ErrorWithContext(err, ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	c.packConfig.Name = c.args[0]
+
+	// Set the packConfig defaults if necessary and generate our UI error context.
+	errorContext := initPackCommand(c.packConfig)
+
+	if err := caching.VerifyPackExists(c.packConfig, errorContext, c.ui); err != nil {
+		return 1
+	}
+
+	c.deploymentName = getDeploymentName(c.baseCommand, c.packConfig)
+	errorContext.Add(errors.UIContextPrefixDeploymentName, c.deploymentName)
+
+	if c.unit {
+		return c.runUnitTests(errorContext)
+	}
+	if c.updateGolden {
+		c.ui.ErrorWithContext(errors.New("--update-golden can only be used with --unit"), ErrParsingArgsOrFlags)
+		c.ui.Info(c.helpUsageMessage())
+		return 1
+	}
+
+	client, err := c.getAPIClient()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to initialize client", errorContext.GetAll()...)
+		return 1
+	}
+
+	// Tests exercise a running deployment, so there is nothing to test until
+	// the pack has been run.
+	if err := c.checkDeploymentRunning(client); err != nil {
+		c.ui.ErrorWithContext(err, "failed to find running deployment", errorContext.GetAll()...)
+		return 1
+	}
+
+	packManager, err := generatePackManager(c.baseCommand, client, c.packConfig)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to generate pack manager", errorContext.GetAll()...)
+		return 1
+	}
+
+	r, err := renderPack(
+		packManager,
+		c.ui,
+		false,
+		false,
+		c.ignoreMissingVars,
+		errorContext,
+	)
+	if err != nil {
+		return 255
+	}
+
+	depConfig := runner.Config{
+		PackName:       c.packConfig.Name,
+		PathPath:       c.packConfig.Path,
+		PackRef:        c.packConfig.Ref,
+		DeploymentName: c.deploymentName,
+		RegistryName:   c.packConfig.Registry,
+	}
+
+	testDeployer, err := generateRunner(client, "job", c.jobConfig, &depConfig)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to generate deployer", errorContext.GetAll()...)
+		return 1
+	}
+
+	testDeployer.SetTemplates(deploymentTemplates(r))
+
+	if validateErrs := testDeployer.ParseTemplates(); validateErrs != nil {
+		for _, validateErr := range validateErrs {
+			validateErr.Context.Append(errorContext)
+			c.ui.ErrorWithContext(validateErr.Err, validateErr.Subject, validateErr.Context.GetAll()...)
+		}
+		return 1
+	}
+
+	testRunner, ok := testDeployer.(*job.Runner)
+	if !ok || len(testRunner.TestTemplateNames()) == 0 {
+		c.ui.ErrorWithContext(errors.New("the pack has no templates within templates/tests"),
+			"no test templates found", errorContext.GetAll()...)
+		return 1
+	}
+
+	if conflictErrs := testDeployer.CheckForConflicts(errorContext); conflictErrs != nil {
+		for _, conflictErr := range conflictErrs {
+			c.ui.ErrorWithContext(conflictErr.Err, conflictErr.Subject, conflictErr.Context.GetAll()...)
+		}
+		return 1
+	}
+
+	tests, dispatchErr := testRunner.DispatchTests(c.ui, errorContext)
+	if dispatchErr != nil {
+		c.ui.ErrorWithContext(dispatchErr.Err, dispatchErr.Subject, dispatchErr.Context.GetAll()...)
+		for _, test := range tests {
+			if err := testRunner.CleanupTest(test, true); err != nil {
+				c.ui.Warning(fmt.Sprintf("Failed to purge test job '%s': %s", test.JobID, err))
+			}
+		}
+		return 1
+	}
+
+	length := shortId
+	if c.jobConfig.RunConfig.Verbose {
+		length = fullId
+	}
+
+	evalIDs := make([]string, len(tests))
+	for i, test := range tests {
+		evalIDs[i] = test.EvalID
+	}
+	if exitCode := newMonitor(c.Ctx, c.ui, client, length).monitor(evalIDs); exitCode != 0 {
+		c.ui.ErrorWithContext(fmt.Errorf("monitoring exited with code %d", exitCode),
+			"failed to place test jobs", errorContext.GetAll()...)
+		return exitCode
+	}
+
+	tbl := terminal.NewTable("Test", "Dispatched Job", "Result")
+	failed := 0
+
+	for _, test := range tests {
+		result := "passed"
+		if err := runTest(c.Ctx, c.ui, client, test.EvalID, length); err != nil {
+			c.ui.Error(fmt.Sprintf("Test job '%s' failed: %s", test.JobID, err))
+			result = "failed"
+			failed++
+		}
+
+		if err := testRunner.CleanupTest(test, result == "passed"); err != nil {
+			c.ui.Warning(fmt.Sprintf("Failed to purge test job '%s': %s", test.JobID, err))
+		}
+
+		tbl.Rows = append(tbl.Rows, []string{test.JobID, test.DispatchedJobID, result})
+	}
+
+	c.ui.Table(tbl)
+
+	if failed > 0 {
+		c.ui.Error(fmt.Sprintf("%d of %d tests of pack deployment '%s' failed", failed, len(tests), c.deploymentName))
+		return 1
+	}
+
+	c.ui.Success(fmt.Sprintf("All tests of pack deployment '%s' passed", c.deploymentName))
+	return 0
+}
+
+// checkDeploymentRunning returns an error unless at least one job of the
+// deployment is running.
+func (c *TestCommand) checkDeploymentRunning(client *api.Client) error {
+	packJobs, _, err := getDeployedPackJobs(client, c.packConfig, c.deploymentName)
+	if err != nil {
+		return err
+	}
+	for _, packJob := range packJobs {
+		if packJob.status == "running" {
+			return nil
+		}
+	}
+	return fmt.Errorf("no running jobs found for deployment %q", c.deploymentName)
+}
+
+// runUnitTests runs the test cases found within the pack's tests directory.
+// They are rendered and parsed without a Nomad client, so no cluster is
+// needed.
+func (c *TestCommand) runUnitTests(errorContext *errors.UIErrorContext) int {
+	cases, err := packtest.LoadCases(c.packConfig.Path)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to load test cases", errorContext.GetAll()...)
+		return 1
+	}
+
+	tbl := terminal.NewTable("Test Case", "Assertions", "Golden Files", "Result")
+	failed := 0
+
+	for _, tc := range cases {
+		caseErrorContext := errorContext.Copy()
+		caseErrorContext.Add(errors.UIContextPrefixTestCase, tc.Name)
+
+		// A case without checks can only be used to create golden files.
+		if !tc.HasChecks() && !c.updateGolden {
+			c.ui.ErrorWithContext(errors.New("test case has neither assertions nor golden files"),
+				"invalid test case", caseErrorContext.GetAll()...)
+			return 1
+		}
+
+		result := "passed"
+		if !c.runUnitTest(tc, caseErrorContext) {
+			result = "failed"
+			failed++
+		}
+
+		golden := "no"
+		if tc.GoldenPath != "" || c.updateGolden {
+			golden = "yes"
+		}
+		tbl.Rows = append(tbl.Rows, []string{tc.Name, strconv.Itoa(len(tc.Assertions)), golden, result})
+	}
+
+	c.ui.Table(tbl)
+
+	if failed > 0 {
+		c.ui.Error(fmt.Sprintf("%d of %d test cases of pack '%s' failed", failed, len(cases), c.packConfig.Name))
+		return 1
+	}
+
+	c.ui.Success(fmt.Sprintf("All test cases of pack '%s' passed", c.packConfig.Name))
+	return 0
+}
+
+// runUnitTest renders and parses the pack using the variables of the test
+// case, and checks the result against the case's golden files and
+// assertions. Failures are output as they are found, and the return value
+// reports whether the case passed.
+func (c *TestCommand) runUnitTest(tc *packtest.Case, errorContext *errors.UIErrorContext) bool {
+	cfg, err := packManagerConfig(c.baseCommand, c.packConfig)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to generate pack manager", errorContext.GetAll()...)
+		return false
+	}
+
+	// The case's variable file is applied last, so it overrides any
+	// variables passed to the command.
+	if tc.VarFile != "" {
+		cfg.VariableFiles = append(slices.Clone(cfg.VariableFiles), tc.VarFile)
+	}
+	packManager := manager.NewPackManager(cfg, nil)
+
+	r, err := renderPack(packManager, c.ui, false, false, c.ignoreMissingVars, errorContext)
+	if err != nil {
+		return false
+	}
+
+	templates := deploymentTemplates(r)
+
+	passed := true
+
+	switch {
+	case c.updateGolden:
+		goldenPath := filepath.Join(tc.Path, packtest.GoldenDirName)
+		if err := packtest.WriteGolden(goldenPath, templates); err != nil {
+			c.ui.ErrorWithContext(err, "failed to update golden files", errorContext.GetAll()...)
+			passed = false
+		}
+	case tc.GoldenPath != "":
+		diffs, err := packtest.CompareGolden(tc.GoldenPath, templates)
+		if err != nil {
+			c.ui.ErrorWithContext(err, "failed to compare golden files", errorContext.GetAll()...)
+			passed = false
+		}
+		if len(diffs) > 0 {
+			c.ui.ErrorWithContext(errors.New("rendered templates do not match the golden files"),
+				"golden file mismatch", errorContext.GetAll()...)
+			for _, diff := range diffs {
+				c.ui.Output("%s", diff)
+			}
+			passed = false
+		}
+	}
+
+	depConfig := runner.Config{
+		PackName:       c.packConfig.Name,
+		PathPath:       c.packConfig.Path,
+		PackRef:        c.packConfig.Ref,
+		DeploymentName: c.deploymentName,
+		RegistryName:   c.packConfig.Registry,
+	}
+
+	// Without a client, the runner parses the templates locally.
+	parser, err := generateRunner(nil, "job", c.jobConfig, &depConfig)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to generate deployer", errorContext.GetAll()...)
+		return false
+	}
+	parser.SetTemplates(templates)
+
+	if validateErrs := parser.ParseTemplates(); validateErrs != nil {
+		for _, validateErr := range validateErrs {
+			validateErr.Context.Append(errorContext)
+			c.ui.ErrorWithContext(validateErr.Err, validateErr.Subject, validateErr.Context.GetAll()...)
+		}
+		return false
+	}
+
+	// Assertions target jobs by ID, which may differ from the job's name.
+	parsed := parser.ParsedTemplates().(map[string]job.ParsedTemplate)
+	jobs := make(map[string]*api.Job, len(parsed))
+	for _, tpl := range parsed {
+		jobs[*tpl.Job().ID] = tpl.Job()
+	}
+
+	for _, assertion := range tc.Assertions {
+		diags := assertion.Check(jobs)
+		if !diags.HasErrors() {
+			continue
+		}
+		for _, wErr := range errors.HCLDiagsToWrappedUIContext(diags) {
+			wErr.Context.Append(errorContext)
+			c.ui.ErrorWithContext(wErr.Err, wErr.Subject, wErr.Context.GetAll()...)
+		}
+		passed = false
+	}
+
+	return passed
+}
+
+// runTest streams the logs of every allocation placed by the test's
+// evaluation, and returns an error if any of them fail.
+func runTest(ctx context.Context, ui terminal.UI, client *api.Client, evalID string, length int) error {
+	allocs, _, err := client.Evaluations().Allocations(evalID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read allocations of evaluation %q: %w", limit(evalID, length), err)
+	}
+	if len(allocs) == 0 {
+		return fmt.Errorf("evaluation %q placed no allocations", limit(evalID, length))
+	}
+
+	for _, stub := range allocs {
+		alloc, err := waitForAllocStart(ctx, client, stub.ID, length)
+		if err != nil {
+			return err
+		}
+		streamAllocLogs(ctx, ui, client, alloc)
+
+		if err := waitForAllocCompletion(ctx, ui, client, alloc.ID, length); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForAllocStart polls the allocation until it is no longer pending, so its
+// logs can be read.
+func waitForAllocStart(ctx context.Context, client *api.Client, allocID string, length int) (*api.Allocation, error) {
+	for {
+		alloc, _, err := client.Allocations().Info(allocID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read allocation %q: %w", limit(allocID, length), err)
+		}
+		if alloc.ClientStatus != api.AllocClientStatusPending {
+			return alloc, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(updateWait):
+		}
+	}
+}
+
+// streamAllocLogs follows the stdout and stderr logs of every task of the
+// allocation, writing each line prefixed with the job and task name. It
+// returns once all the tasks have stopped and their logs have been read.
+func streamAllocLogs(ctx context.Context, ui terminal.UI, client *api.Client, alloc *api.Allocation) {
+	var tasks []string
+	if alloc.Job != nil {
+		if tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup); tg != nil {
+			for _, task := range tg.Tasks {
+				tasks = append(tasks, task.Name)
+			}
+		}
+	}
+	sort.Strings(tasks)
+
+	cancel := make(chan struct{})
+	defer close(cancel)
+
+	// The UI is not safe for concurrent use, so the streams take turns.
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, task := range tasks {
+		for _, logType := range []string{"stdout", "stderr"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				prefix := fmt.Sprintf("[%s/%s] ", alloc.JobID, task)
+				style := terminal.DefaultStyle
+				if logType == "stderr" {
+					style = terminal.ErrorStyle
+				}
+
+				output := func(line []byte) {
+					mu.Lock()
+					defer mu.Unlock()
+					ui.Output("%s", prefix+string(line), terminal.WithStyle(style))
+				}
+
+				frames, errCh := client.AllocFS().Logs(alloc, true, task, logType, api.OriginStart, 0, cancel, nil)
+
+				var buf []byte
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case err, ok := <-errCh:
+						if ok && err != nil {
+							output([]byte(fmt.Sprintf("failed to read %s: %s", logType, err)))
+						}
+						if len(buf) > 0 {
+							output(buf)
+						}
+						return
+					case frame, ok := <-frames:
+						if !ok {
+							if len(buf) > 0 {
+								output(buf)
+							}
+							return
+						}
+						buf = append(buf, frame.Data...)
+						for {
+							idx := bytes.IndexByte(buf, '\n')
+							if idx < 0 {
+								break
+							}
+							output(buf[:idx])
+							buf = buf[idx+1:]
+						}
+					}
+				}
+			}()
+		}
+	}
+
+	wg.Wait()
+}
+
+// Flags defines the flag.Sets for the operation.
+func (c *TestCommand) Flags() *flag.Sets {
+	return c.flagSet(flagSetOperation|flagSetNomadClient|flagSetExternalVarSources, func(set *flag.Sets) {
+		f := set.NewSet("Test Options")
+
+		c.packConfig = &caching.PackConfig{}
+
+		c.jobConfig = &job.CLIConfig{
+			RunConfig: &job.RunCLIConfig{},
+		}
+
+		f.StringVar(&flag.StringVar{
+			Name:    "registry",
+			Target:  &c.packConfig.Registry,
+			Default: "",
+			Usage:   `Specific registry name containing the pack to be tested.`,
+		})
+
+		f.StringVar(&flag.StringVar{
+			Name:    "ref",
+			Target:  &c.packConfig.Ref,
+			Default: "",
+			Usage: `Specific git ref of the pack to be tested.
+					Supports tags, SHA, and latest. If no ref is specified,
+					defaults to latest.
+
+					Using ref with a file path is not supported.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "policy-override",
+			Target:  &c.jobConfig.RunConfig.PolicyOverride,
+			Default: false,
+			Usage: `Sets the flag to force override any soft mandatory Sentinel
+					policies when registering the test jobs.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "verbose",
+			Target:  &c.jobConfig.RunConfig.Verbose,
+			Default: false,
+			Usage:   `If set, monitoring of the test jobs will show full IDs.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "unit",
+			Target:  &c.unit,
+			Default: false,
+			Usage: `If set, the test cases within the pack's tests directory are
+					run instead of the test jobs. Each case renders the pack
+					with its own variable file and checks the result against
+					its assertions and golden files, without a Nomad cluster.`,
+		})
+
+		f.BoolVar(&flag.BoolVar{
+			Name:    "update-golden",
+			Target:  &c.updateGolden,
+			Default: false,
+			Usage: `If set with --unit, the golden files of every test case are
+					replaced with the current rendered output.`,
+		})
+	})
+}
+
+func (c *TestCommand) AutocompleteArgs() complete.Predictor {
+	return predictPackName
+}
+
+func (c *TestCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *TestCommand) Help() string {
+	c.Example = `
+	# Run the tests of the example pack against the deployment "example"
+	nomad-pack test example
+
+	# Run the tests against the deployment named "dev", rendered with the
+	# same variables the deployment was run with
+	nomad-pack test example --name=dev --var-file="./dev.hcl"
+
+	# Run the unit test cases of a pack under development, without a cluster
+	nomad-pack test . --unit
+	`
+
+	return formatHelp(`
+	Usage: nomad-pack test <pack-name> [options]
+
+	Run the test jobs of a pack against a running deployment of it.
+
+	Test jobs are the batch job templates within the pack's templates/tests
+	directory. Each is dispatched as a parameterized job, and the logs of its
+	tasks are streamed until it completes. The command exits non-zero if any
+	test allocation fails. Failed test jobs are left in Nomad for inspection.
+
+	With --unit, the test cases within the pack's tests directory are run
+	instead. Each case is a directory which may contain a vars.hcl variable
+	file, an assertions.hcl file of assert blocks evaluated against the parsed
+	jobs, and a golden directory of the expected rendered templates.
+
+` + c.GetExample() + c.Flags().Help())
+}
+
+// Synopsis satisfies the Synopsis function of the cli.Command interface.
+func (c *TestCommand) Synopsis() string {
+	return "Run the test jobs of a pack against a deployment"
+}
@@ -7,17 +7,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
+	"maps"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	gg "github.com/hashicorp/go-getter"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci"
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature"
 )
 
 const tmpDir = "nomad-pack-tmp"
@@ -54,11 +59,11 @@ func (c *Cache) Add(opts *AddOpts) (*Registry, error) {
 	return c.addFromURI(opts)
 }
 
-// addFromURI loads a registry from a remote git repository. If addToCache is
-// true, the registry will also be added to the global cache. The cache directory
-// must be specified to allow user customization of cache location. If a name is
-// specified, the registry will be added with that alias, otherwise the registry
-// URL slug will be used.
+// addFromURI loads a registry from a remote git repository, or from an OCI
+// registry for "oci://" sources. If addToCache is true, the registry will also
+// be added to the global cache. The cache directory must be specified to allow
+// user customization of cache location. If a name is specified, the registry
+// will be added with that alias, otherwise the registry URL slug will be used.
 func (c *Cache) addFromURI(opts *AddOpts) (cachedRegistry *Registry, err error) {
 	// Set the logger instance to reduce boilerplate.
 	logger := c.cfg.Logger
@@ -75,22 +80,36 @@ func (c *Cache) addFromURI(opts *AddOpts) (cachedRegistry *Registry, err error)
 			return // there's nothing to clean up
 		}
 
-		err = os.RemoveAll(c.clonePath())
-		if err != nil {
-			logger.Debug(fmt.Sprintf("add completed with errors - %s directory not deleted: %s", c.clonePath(), err.Error()))
+		// Don't overwrite the error of the add itself, which would let a
+		// registry failing verification be reported as added.
+		if rErr := os.RemoveAll(c.clonePath()); rErr != nil {
+			logger.Debug(fmt.Sprintf("add completed with errors - %s directory not deleted: %s", c.clonePath(), rErr.Error()))
 		}
 		logger.Info("temp directory deleted")
 	}()
 
-	// keep the SHA of the clone operation (if any)
-	c.latestSHA, err = c.cloneRemoteGitRegistry(opts)
+	// Packs from an OCI registry are pulled to the same location a git
+	// registry is cloned to, keeping the manifest digest of each pack in place
+	// of the SHA of the clone.
+	var digests map[string]string
+	if oci.IsReference(opts.Source) {
+		digests, err = c.pullOCIRegistry(opts)
+	} else {
+		// keep the SHA of the clone operation (if any)
+		c.latestSHA, err = c.cloneRemoteGitRegistry(opts)
+		if err == nil && len(opts.TrustedKeys) > 0 {
+			err = c.verifyGitRegistry(opts)
+		}
+	}
 	if err != nil {
 		return
 	}
 
 	logger.Debug(fmt.Sprintf("Processing pack entries at %s", c.clonePath()))
 
-	// Move the cloned registry packs to the global cache.
+	// Move the cloned registry packs to the global cache, keeping the
+	// checksum of each pack as it was served by the registry.
+	checksums := make(map[string]string)
 	packEntries, err := os.ReadDir(c.clonedPacksPath())
 	for _, packEntry := range packEntries {
 		// Don't process the .git folder or any files
@@ -110,13 +129,33 @@ func (c *Cache) addFromURI(opts *AddOpts) (cachedRegistry *Registry, err error)
 			Ref:          opts.Ref,
 		}
 
-		err = c.processPackEntry(packOpts, packEntry)
+		if digest, ok := digests[packEntry.Name()]; ok {
+			c.latestSHA = digest
+		}
+
+		checksums[packEntry.Name()], err = HashPack(packOpts.clonedPackPath(c))
+		if err != nil {
+			logger.ErrorWithContext(err, "error calculating pack checksum", c.ErrorContext.GetAll()...)
+			return
+		}
+
+		err = c.processPackEntry(packOpts, packEntry, checksums[packEntry.Name()])
 		if err != nil {
 			logger.ErrorWithContext(err, "error processing pack entry", c.ErrorContext.GetAll()...)
 			return
 		}
 	}
 
+	if err = c.updateIndex(opts, checksums); err != nil {
+		logger.ErrorWithContext(err, "error updating the index of the registry", c.ErrorContext.GetAll()...)
+		return
+	}
+
+	// A registry of several OCI packs has no single digest to record.
+	if len(digests) > 1 {
+		c.latestSHA = ""
+	}
+
 	cachedRegistry, err = c.Get(&GetOpts{
 		RegistryName: opts.RegistryName,
 		PackName:     opts.PackName,
@@ -128,6 +167,10 @@ func (c *Cache) addFromURI(opts *AddOpts) (cachedRegistry *Registry, err error)
 		logger.ErrorWithContext(err, "error getting registry after add", c.ErrorContext.GetAll()...)
 		return
 	}
+	if cachedRegistry.Checksums == nil {
+		cachedRegistry.Checksums = make(map[string]string)
+	}
+	maps.Copy(cachedRegistry.Checksums, checksums)
 
 	// Store a metadata JSON file for the cached registry
 	b, _ := json.MarshalIndent(cachedRegistry, "", "  ")
@@ -140,6 +183,59 @@ func (c *Cache) addFromURI(opts *AddOpts) (cachedRegistry *Registry, err error)
 	return
 }
 
+// updateIndex keeps the index of the cached registry in line with the packs
+// which have just been added to it, given with their checksums. When the
+// whole registry was added, the index published by the registry, if any, is
+// copied to the cache, leaving out the entries which do not match the
+// checksum of their pack. Otherwise the entries of the added packs are
+// dropped from the cached index, as they may no longer describe the packs,
+// which are loaded from disk instead.
+func (c *Cache) updateIndex(opts *AddOpts, checksums map[string]string) error {
+	logger := c.cfg.Logger
+	registryPath := filepath.Join(c.cfg.Path, opts.RegistryName, EscapeRef(opts.Ref))
+
+	if opts.PackName == "" {
+		index, err := ReadIndex(c.clonePath())
+		if err != nil {
+			// A broken index should not prevent the registry from being used,
+			// so the packs are loaded from disk as if it was not published.
+			logger.Warning(fmt.Sprintf("ignoring %s of the registry: %s", IndexFileName, err))
+		}
+		if index != nil {
+			var stale []string
+			for _, entry := range index.Packs {
+				if checksum, ok := checksums[entry.Name]; ok && checksum != entry.Checksum {
+					logger.Warning(fmt.Sprintf("ignoring outdated %s entry of pack %q", IndexFileName, entry.Name))
+					stale = append(stale, entry.Name)
+				}
+			}
+			index.remove(stale...)
+
+			logger.Debug(fmt.Sprintf("Copying %s to %s", IndexFileName, registryPath))
+			return WriteIndex(registryPath, index)
+		}
+	}
+
+	index, err := ReadIndex(registryPath)
+	if err != nil || index == nil {
+		return err
+	}
+	index.remove(slices.Collect(maps.Keys(checksums))...)
+	return WriteIndex(registryPath, index)
+}
+
+// verifyGitRegistry verifies the signature of the commit a git registry was
+// cloned at against the trusted keys of the options.
+func (c *Cache) verifyGitRegistry(opts *AddOpts) error {
+	signer, err := signature.VerifyCommit(opts.TrustedKeys, c.clonePath())
+	if err != nil {
+		c.cfg.Logger.ErrorWithContext(err, "could not verify registry signature", c.ErrorContext.GetAll()...)
+		return fmt.Errorf("failed to verify registry signature: %w", err)
+	}
+	c.cfg.Logger.Debug(fmt.Sprintf("Registry commit signed by %s", signature.Identity(signer)))
+	return nil
+}
+
 // cloneRemoteGitRegistry clones a remote git repository to the cache. Returns
 // the SHA of the HEAD of the cloned repository.
 func (c *Cache) cloneRemoteGitRegistry(opts *AddOpts) (string, error) {
@@ -153,7 +249,7 @@ func (c *Cache) cloneRemoteGitRegistry(opts *AddOpts) (string, error) {
 
 	clonePath := c.clonePath()
 	// If pack name is set, add an intermediary "packs" and pack dir manually.
-	if opts.PackName != "" {
+	if opts.PackName != "" && !opts.cloneRegistry() {
 		clonePath = path.Join(clonePath, "packs", opts.PackName)
 	}
 	if err := gg.Get(clonePath, fmt.Sprintf("git::%s", url)); err != nil {
@@ -175,7 +271,7 @@ func (c *Cache) cloneRemoteGitRegistry(opts *AddOpts) (string, error) {
 func buildGoGetterGitURL(opts *AddOpts) string {
 	urlStr := opts.Source
 
-	if opts.PackName != "" {
+	if opts.PackName != "" && !opts.cloneRegistry() {
 		src := strings.TrimSuffix(opts.Source, ".git")
 		urlStr = fmt.Sprintf("%s.git//packs/%s", src, opts.PackName)
 	}
@@ -190,7 +286,9 @@ func buildGoGetterGitURL(opts *AddOpts) string {
 	return urlStr
 }
 
-func (c *Cache) processPackEntry(opts *AddOpts, packEntry os.DirEntry) error {
+// processPackEntry copies the cloned pack to the cache. checksum is the hash
+// of the cloned pack, which is recorded in the metadata of the registry.
+func (c *Cache) processPackEntry(opts *AddOpts, packEntry os.DirEntry, checksum string) error {
 	logger := c.cfg.Logger
 	logger.Debug(fmt.Sprintf("Processing pack %s@%s", packEntry.Name(), opts.Ref))
 
@@ -204,9 +302,25 @@ func (c *Cache) processPackEntry(opts *AddOpts, packEntry os.DirEntry) error {
 	// Here we could have err=fs.ErrNotExist or err=nil
 	// Only look for latest when the pack path is found.
 	if err == nil && !opts.IsLatest() {
-		// If ref target is not latest, continue to next entry because ref already exists
-		logger.Debug("Pack already exists at specified ref - skipping")
-		return nil
+		// If ref target is not latest, continue to next entry because ref
+		// already exists, unless the cached pack no longer matches the one
+		// served at the ref, as when a branch has moved or the cached files
+		// were modified. It would otherwise fail checksum verification.
+		cached, err := HashPack(opts.PackPath())
+		if err != nil {
+			logger.ErrorWithContext(err, "error calculating cached pack checksum", c.ErrorContext.GetAll()...)
+			return err
+		}
+		if cached == checksum {
+			logger.Debug("Pack already exists at specified ref - skipping")
+			return nil
+		}
+
+		logger.Debug("Pack at specified ref has changed - replacing")
+		if err := os.RemoveAll(opts.PackPath()); err != nil {
+			logger.ErrorWithContext(err, "error removing changed pack directory", c.ErrorContext.GetAll()...)
+			return err
+		}
 	}
 
 	logger.Debug("Updating pack")
@@ -338,6 +452,16 @@ type AddOpts struct {
 	Username string
 	// Optional password for basic auth to a registry that requires authentication.
 	Password string
+	// Optional OpenPGP public keys, one of which must have signed the commit a
+	// git registry is cloned at, or each pack pulled from an OCI registry.
+	TrustedKeys openpgp.EntityList
+}
+
+// cloneRegistry reports whether the whole git repository of a registry must
+// be cloned even when a single pack is targeted, which is the case when its
+// commit is verified.
+func (opts *AddOpts) cloneRegistry() bool {
+	return len(opts.TrustedKeys) > 0
 }
 
 // RegistryPath fulfills the cacheOperationProvider interface for AddOpts
@@ -4,10 +4,15 @@
 package caching
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/shoenig/test/must"
 
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci"
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci/ocitest"
+	"github.com/hashicorp/nomad-pack/internal/pkg/testfixture"
 	"github.com/hashicorp/nomad/ci"
 )
 
@@ -112,3 +117,72 @@ func TestBuildGoGetterGitURL(t *testing.T) {
 		})
 	}
 }
+
+func TestAddRegistryFromOCI(t *testing.T) {
+	ci.Parallel(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	registry := ocitest.NewRegistry(t)
+	registry.Username, registry.Password = "ci", "secret"
+	client := &oci.Client{Username: "ci", Password: "secret"}
+	source := "oci://" + registry.Host() + "/packs"
+
+	namespace, err := oci.ParseReference(source)
+	must.NoError(t, err)
+	digests := make(map[string]string)
+	for _, name := range []string{"simple_raw_exec", "my_alias_test"} {
+		for _, tag := range []string{"latest", "v1.0.0"} {
+			ref, err := namespace.Pack(name, tag)
+			must.NoError(t, err)
+			digests[name], err = client.Push(ctx, ref, testfixture.MustAbsPath("v2/test_registry/packs/"+name), nil)
+			must.NoError(t, err)
+		}
+	}
+
+	cache, err := NewCache(&CacheConfig{
+		Path:   t.TempDir(),
+		Logger: NewTestLogger(t),
+	})
+	must.NoError(t, err)
+
+	// Every pack in the namespace is added.
+	cachedRegistry, err := cache.Add(&AddOpts{
+		RegistryName: "oci",
+		Source:       source,
+		Username:     "ci",
+		Password:     "secret",
+	})
+	must.NoError(t, err)
+	must.Len(t, 2, cachedRegistry.Packs)
+	must.Eq(t, source, cachedRegistry.Source)
+	must.Eq(t, "", cachedRegistry.LocalRef)
+
+	// A targeted pack records the digest of its manifest.
+	cachedRegistry, err = cache.Add(&AddOpts{
+		RegistryName: "oci",
+		Source:       source,
+		PackName:     "simple_raw_exec",
+		Ref:          "v1.0.0",
+		Username:     "ci",
+		Password:     "secret",
+	})
+	must.NoError(t, err)
+	must.Len(t, 1, cachedRegistry.Packs)
+	must.Eq(t, "v1.0.0", cachedRegistry.Packs[0].Ref)
+	must.Eq(t, "simple_raw_exec", cachedRegistry.Packs[0].Name())
+	must.Eq(t, digests["simple_raw_exec"], cachedRegistry.LocalRef)
+
+	_, err = cache.Add(&AddOpts{
+		RegistryName: "oci",
+		Source:       source,
+		PackName:     "simple_raw_exec",
+		Ref:          "v2.0.0",
+		Username:     "ci",
+		Password:     "secret",
+	})
+	must.ErrorContains(t, err, "MANIFEST_UNKNOWN")
+
+	_, err = cache.Add(&AddOpts{RegistryName: "oci", Source: source + ":v1.0.0"})
+	must.ErrorContains(t, err, "cannot include a tag")
+}
@@ -4,6 +4,7 @@
 package caching
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
@@ -208,6 +209,42 @@ func VerifyPackExists(cfg *PackConfig, errCtx *errors.UIErrorContext, logger log
 	return
 }
 
+// VerifyPackChecksum verifies that a pack added from a registry has not been
+// modified since, by hashing its content and comparing it to the checksum
+// recorded in the metadata of the registry when the pack was added. Packs
+// from a local directory, and packs added before checksums were recorded, are
+// not verified.
+func VerifyPackChecksum(cfg *PackConfig) error {
+	if cfg.Registry == DevRegistryName {
+		return nil
+	}
+
+	b, err := os.ReadFile(path.Join(path.Dir(cfg.Path), "metadata.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var registry Registry
+	if err := json.Unmarshal(b, &registry); err != nil {
+		return err
+	}
+
+	expected := registry.Checksums[cfg.Name]
+	if expected == "" {
+		return nil
+	}
+	actual, err := HashPack(cfg.Path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("%w: checksum is %s, expected %s", errors.ErrPackModified, actual, expected)
+	}
+	return nil
+}
+
 // AppendRef is a utility function to format a pack name at a specific ref.
 func AppendRef(name, ref string) string {
 	if ref == "" || ref == DevRef {
@@ -172,11 +172,19 @@ func TestAddRegistryWithSHA(t *testing.T) {
 	must.NoError(t, err)
 	r := &Registry{}
 	must.NoError(t, json.Unmarshal(f, r))
+	// The checksums are keyed by the directory names of the packs.
+	checksums := make(map[string]string)
+	for _, p := range registry.Packs {
+		name := strings.TrimSuffix(path.Base(p.Path), "@"+registry.Ref)
+		checksums[name], err = HashPack(p.Path)
+		must.NoError(t, err)
+	}
 	expectedRegistryMetadata := &Registry{
-		Name:     "with-sha",
-		Source:   tReg.SourceURL(),
-		Ref:      tReg.Ref1(),
-		LocalRef: tReg.Ref1(),
+		Name:      "with-sha",
+		Source:    tReg.SourceURL(),
+		Ref:       tReg.Ref1(),
+		LocalRef:  tReg.Ref1(),
+		Checksums: checksums,
 	}
 	must.Eq(t, expectedRegistryMetadata, r)
 }
@@ -0,0 +1,303 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package caching
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	gg "github.com/hashicorp/go-getter"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
+	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+const (
+	// IndexFileName is the name of the index file a registry may publish at
+	// its root, next to the packs directory.
+	IndexFileName = "index.json"
+
+	// IndexVersion is the version of the index file format written by
+	// GenerateIndex. Version 2 added the flags and variables of each pack.
+	IndexVersion = 2
+
+	// minIndexVersion is the oldest version of the index file format which
+	// can still be read.
+	minIndexVersion = 1
+
+	// latestLogFileName is the name of the file the cache writes into packs
+	// added at the latest ref, which is not part of the pack content.
+	latestLogFileName = "latest.log"
+)
+
+// Index lists the packs of a registry along with the metadata needed to
+// display them, so the packs do not have to be loaded to be listed.
+type Index struct {
+	Version int           `json:"version"`
+	Packs   []*IndexEntry `json:"packs"`
+}
+
+// IndexEntry describes a single pack of a registry Index.
+type IndexEntry struct {
+	// Name is the name of the directory of the pack within the packs
+	// directory of the registry.
+	Name string `json:"name"`
+	// Version, Description, URL and Flags are copied from the metadata.hcl
+	// of the pack.
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Flags       []string `json:"flags,omitempty"`
+	// Variables are the variables declared by the pack, so that packs can be
+	// searched by variable without being loaded.
+	Variables []*PackVariable `json:"variables,omitempty"`
+	// Refs are the git tags of the registry which contain the pack.
+	Refs []string `json:"refs,omitempty"`
+	// Checksum is the hash of the content of the pack, as returned by
+	// HashPack.
+	Checksum string `json:"checksum"`
+}
+
+// Get returns the entry of the named pack, or nil if the index has none.
+func (i *Index) Get(name string) *IndexEntry {
+	for _, entry := range i.Packs {
+		if entry.Name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// remove drops the entries of the named packs from the index.
+func (i *Index) remove(names ...string) {
+	packs := i.Packs[:0]
+	for _, entry := range i.Packs {
+		keep := true
+		for _, name := range names {
+			if entry.Name == name {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			packs = append(packs, entry)
+		}
+	}
+	i.Packs = packs
+}
+
+// pack returns a pack holding the metadata of the entry, which is enough to
+// list the pack but not to render it.
+func (e *IndexEntry) pack() *pack.Pack {
+	return &pack.Pack{
+		Metadata: &pack.Metadata{
+			App: &pack.MetadataApp{URL: e.URL},
+			Pack: &pack.MetadataPack{
+				Name:        e.Name,
+				Description: e.Description,
+				Version:     e.Version,
+			},
+			Integration: &pack.MetadataIntegration{Flags: e.Flags},
+		},
+	}
+}
+
+// GenerateIndex builds the index of the registry at registryPath by loading
+// every pack within its packs directory and parsing its variables. If the
+// registry is a git repository, the tags containing each pack are listed as
+// its refs.
+func GenerateIndex(registryPath string) (*Index, error) {
+	packsPath := filepath.Join(registryPath, "packs")
+	entries, err := os.ReadDir(packsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packs directory: %w", err)
+	}
+
+	tags, err := packTags(registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{Version: IndexVersion, Packs: []*IndexEntry{}}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		packPath := filepath.Join(packsPath, entry.Name())
+		p, err := loader.Load(packPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pack %q: %w", entry.Name(), err)
+		}
+
+		checksum, err := HashPack(packPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash pack %q: %w", entry.Name(), err)
+		}
+
+		variables, err := packVariables(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse variables of pack %q: %w", entry.Name(), err)
+		}
+
+		indexEntry := &IndexEntry{
+			Name:      entry.Name(),
+			Refs:      tags[entry.Name()],
+			Variables: variables,
+			Checksum:  checksum,
+		}
+		if p.Metadata.Pack != nil {
+			indexEntry.Version = p.Metadata.Pack.Version
+			indexEntry.Description = p.Metadata.Pack.Description
+		}
+		if p.Metadata.App != nil {
+			indexEntry.URL = p.Metadata.App.URL
+		}
+		if p.Metadata.Integration != nil {
+			indexEntry.Flags = p.Metadata.Integration.Flags
+		}
+		index.Packs = append(index.Packs, indexEntry)
+	}
+
+	return index, nil
+}
+
+// packTags returns the tags of the git repository at registryPath which
+// contain each pack, by pack directory name. A registry which is not a git
+// repository has no tags.
+func packTags(registryPath string) (map[string][]string, error) {
+	repo, err := git.PlainOpen(registryPath)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry repository: %w", err)
+	}
+
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registry tags: %w", err)
+	}
+
+	tags := make(map[string][]string)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tag := ref.Name().Short()
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to resolve tag %q: %w", tag, err)
+		}
+		commit, err := repo.CommitObject(*hash)
+		if err != nil {
+			return fmt.Errorf("failed to read commit of tag %q: %w", tag, err)
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return fmt.Errorf("failed to read tree of tag %q: %w", tag, err)
+		}
+		packs, err := tree.Tree("packs")
+		if err != nil {
+			// A tag from before the registry had any packs.
+			return nil
+		}
+		for _, entry := range packs.Entries {
+			if entry.Mode == filemode.Dir {
+				tags[entry.Name] = append(tags[entry.Name], tag)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, refs := range tags {
+		sort.Strings(refs)
+	}
+	return tags, nil
+}
+
+// ReadIndex reads the index file within dir. If there is none, a nil index
+// is returned without error.
+func ReadIndex(dir string) (*Index, error) {
+	b, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseIndex(b)
+}
+
+// FetchIndex reads the index at source, which is either the path of a
+// registry directory or index file, or a URL supported by go-getter, such as
+// the raw URL of the index file of a registry hosted on GitHub. It allows the
+// packs of a registry to be listed before the registry is added.
+func FetchIndex(source string) (*Index, error) {
+	if info, err := os.Stat(source); err == nil {
+		if info.IsDir() {
+			source = filepath.Join(source, IndexFileName)
+		}
+		b, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return ParseIndex(b)
+	}
+
+	tmp, err := os.MkdirTemp("", "nomad-pack-index-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	dst := filepath.Join(tmp, IndexFileName)
+	if err := gg.GetFile(dst, source); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", IndexFileName, err)
+	}
+	b, err := os.ReadFile(dst)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIndex(b)
+}
+
+// ParseIndex decodes the content of an index file. An index of an older
+// version is returned as it is, without the fields added since.
+func ParseIndex(b []byte) (*Index, error) {
+	var index Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", IndexFileName, err)
+	}
+	if index.Version < minIndexVersion || index.Version > IndexVersion {
+		return nil, fmt.Errorf("unsupported %s version %d", IndexFileName, index.Version)
+	}
+	return &index, nil
+}
+
+// WriteIndex writes the index file within dir.
+func WriteIndex(dir string, index *Index) error {
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, IndexFileName), append(b, '\n'), 0644)
+}
+
+// HashPack returns the hash of the files within the pack at dir, in the
+// "h1:" format of go.sum. Any .git directory and the latest.log written by
+// the cache are ignored, so a pack hashes the same in a registry and once it
+// has been added to the cache.
+func HashPack(dir string) (string, error) {
+	return filesystem.HashDir(dir, latestLogFileName)
+}
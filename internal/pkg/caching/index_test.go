@@ -0,0 +1,215 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package caching
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
+	"github.com/hashicorp/nomad-pack/internal/pkg/testfixture"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	ci.Parallel(t)
+
+	registryPath := filepath.Join(t.TempDir(), "registry")
+	must.NoError(t, filesystem.CopyDir(testfixture.MustAbsPath("v2/test_registry"), registryPath, false, NoopLogger{}))
+
+	// Without a git repository, the packs have no refs.
+	index, err := GenerateIndex(registryPath)
+	must.NoError(t, err)
+	must.Eq(t, IndexVersion, index.Version)
+	must.Len(t, len(dirEntries(t, filepath.Join(registryPath, "packs"))), index.Packs)
+
+	entry := index.Get("simple_raw_exec")
+	must.NotNil(t, entry)
+	must.Eq(t, "0.0.1", entry.Version)
+	must.Nil(t, entry.Refs)
+	must.SliceContains(t, entry.Variables, &PackVariable{Name: "count", Description: "The number of app instances to deploy"})
+	checksum, err := HashPack(filepath.Join(registryPath, "packs", "simple_raw_exec"))
+	must.NoError(t, err)
+	must.Eq(t, checksum, entry.Checksum)
+	must.Nil(t, index.Get("missing"))
+
+	// The tags of the registry are listed as refs of the packs they contain.
+	repo, err := git.PlainInit(registryPath, false)
+	must.NoError(t, err)
+	w, err := repo.Worktree()
+	must.NoError(t, err)
+	_, err = w.Add(".")
+	must.NoError(t, err)
+	commit, err := w.Commit("Initial Commit", &git.CommitOptions{Author: &object.Signature{
+		Name:  "Test User",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}})
+	must.NoError(t, err)
+	_, err = repo.CreateTag("v0.0.1", commit, nil)
+	must.NoError(t, err)
+
+	index, err = GenerateIndex(registryPath)
+	must.NoError(t, err)
+	must.Eq(t, []string{"v0.0.1"}, index.Get("simple_raw_exec").Refs)
+
+	must.NoError(t, WriteIndex(registryPath, index))
+	read, err := ReadIndex(registryPath)
+	must.NoError(t, err)
+	must.Eq(t, index, read)
+
+	_, err = GenerateIndex(t.TempDir())
+	must.ErrorContains(t, err, "failed to read packs directory")
+}
+
+func TestReadIndex(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	index, err := ReadIndex(dir)
+	must.NoError(t, err)
+	must.Nil(t, index)
+
+	// An index of an older version can still be read.
+	must.NoError(t, os.WriteFile(filepath.Join(dir, IndexFileName), []byte(`{"version": 1, "packs": [{"name": "example"}]}`), 0644))
+	index, err = ReadIndex(dir)
+	must.NoError(t, err)
+	must.Eq(t, 1, index.Version)
+	must.NotNil(t, index.Get("example"))
+
+	must.NoError(t, os.WriteFile(filepath.Join(dir, IndexFileName), []byte(`{"version": 3}`), 0644))
+	_, err = ReadIndex(dir)
+	must.ErrorContains(t, err, "unsupported index.json version 3")
+
+	index, err = FetchIndex(dir)
+	must.Nil(t, index)
+	must.ErrorContains(t, err, "unsupported index.json version 3")
+}
+
+func TestHashPack(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := filepath.Join(t.TempDir(), "simple_raw_exec")
+	must.NoError(t, filesystem.CopyDir(testfixture.MustAbsPath("v2/test_registry/packs/simple_raw_exec"), dir, false, NoopLogger{}))
+
+	hash, err := HashPack(dir)
+	must.NoError(t, err)
+
+	// The latest.log written by the cache is not part of the pack.
+	must.NoError(t, os.WriteFile(filepath.Join(dir, latestLogFileName), []byte("SHA"), 0644))
+	again, err := HashPack(dir)
+	must.NoError(t, err)
+	must.Eq(t, hash, again)
+
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed"), 0644))
+	changed, err := HashPack(dir)
+	must.NoError(t, err)
+	must.NotEq(t, hash, changed)
+}
+
+func TestAddRegistryWithIndex(t *testing.T) {
+	ci.Parallel(t)
+
+	// Publish an index whose version for one of the packs differs from its
+	// metadata, to tell whether the pack was listed from the index.
+	registryPath := filepath.Join(t.TempDir(), "registry.git")
+	must.NoError(t, filesystem.CopyDir(testfixture.MustAbsPath("v2/test_registry"), registryPath, false, NoopLogger{}))
+	index, err := GenerateIndex(registryPath)
+	must.NoError(t, err)
+	index.Get("simple_raw_exec").Version = "indexed"
+	must.NoError(t, WriteIndex(registryPath, index))
+
+	repo, err := git.PlainInit(registryPath, false)
+	must.NoError(t, err)
+	w, err := repo.Worktree()
+	must.NoError(t, err)
+	_, err = w.Add(".")
+	must.NoError(t, err)
+	_, err = w.Commit("Initial Commit", &git.CommitOptions{Author: &object.Signature{
+		Name:  "Test User",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}})
+	must.NoError(t, err)
+
+	cacheDir := t.TempDir()
+	cache, err := NewCache(&CacheConfig{
+		Path:   cacheDir,
+		Logger: NewTestLogger(t),
+	})
+	must.NoError(t, err)
+
+	versions := func(r *Registry) map[string]string {
+		out := make(map[string]string)
+		for _, p := range r.Packs {
+			out[p.Name()] = p.Metadata.Pack.Version
+		}
+		return out
+	}
+
+	cachedRegistry, err := cache.Add(&AddOpts{RegistryName: "indexed", Source: registryPath})
+	must.NoError(t, err)
+	must.Eq(t, "indexed", versions(cachedRegistry)["simple_raw_exec"])
+	must.FileExists(t, filepath.Join(cacheDir, "indexed", DefaultRef, IndexFileName))
+
+	cachedRegistry, err = cache.Get(&GetOpts{RegistryName: "indexed", Ref: DefaultRef})
+	must.NoError(t, err)
+	must.Len(t, len(index.Packs), cachedRegistry.Packs)
+	must.Eq(t, "indexed", versions(cachedRegistry)["simple_raw_exec"])
+
+	// The variables of a pack listed from the index are read from it.
+	for _, p := range cachedRegistry.Packs {
+		must.StrHasPrefix(t, filepath.Join(cacheDir, "indexed", DefaultRef), p.Path)
+		if p.Name() != "simple_raw_exec" {
+			continue
+		}
+		must.NotNil(t, p.indexEntry)
+		variables, err := p.Variables()
+		must.NoError(t, err)
+		must.Eq(t, index.Get("simple_raw_exec").Variables, variables)
+	}
+
+	// Adding a single pack drops it from the cached index, so it is loaded
+	// from disk again.
+	_, err = cache.Add(&AddOpts{RegistryName: "indexed", Source: registryPath, PackName: "simple_raw_exec"})
+	must.NoError(t, err)
+	cachedIndex, err := ReadIndex(filepath.Join(cacheDir, "indexed", DefaultRef))
+	must.NoError(t, err)
+	must.Nil(t, cachedIndex.Get("simple_raw_exec"))
+	must.NotNil(t, cachedIndex.Get("my_alias_test"))
+
+	cachedRegistry, err = cache.Get(&GetOpts{RegistryName: "indexed", Ref: DefaultRef})
+	must.NoError(t, err)
+	must.Eq(t, "0.0.1", versions(cachedRegistry)["simple_raw_exec"])
+
+	// Otherwise they are parsed from the pack.
+	for _, p := range cachedRegistry.Packs {
+		if p.Name() != "simple_raw_exec" {
+			continue
+		}
+		must.Nil(t, p.indexEntry)
+		variables, err := p.Variables()
+		must.NoError(t, err)
+		must.Eq(t, index.Get("simple_raw_exec").Variables, variables)
+	}
+
+	// An index of an older version lacks the variables of the packs, so it
+	// is ignored.
+	cachedIndex.Version = 1
+	cachedIndex.Get("my_alias_test").Version = "indexed"
+	must.NoError(t, WriteIndex(filepath.Join(cacheDir, "indexed", DefaultRef), cachedIndex))
+
+	cachedRegistry, err = cache.Get(&GetOpts{RegistryName: "indexed", Ref: DefaultRef})
+	must.NoError(t, err)
+	must.NotEq(t, "indexed", versions(cachedRegistry)["my_alias_test"])
+	for _, p := range cachedRegistry.Packs {
+		must.Nil(t, p.indexEntry)
+	}
+}
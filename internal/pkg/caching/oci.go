@@ -0,0 +1,82 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package caching
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci"
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature"
+)
+
+// pullOCIRegistry pulls the packs of an OCI registry into the clone path and
+// returns the digest of the manifest of each pack, by pack name. The source is
+// a namespace holding a repository per pack, and every pack within it is
+// pulled unless the options target a single pack.
+func (c *Cache) pullOCIRegistry(opts *AddOpts) (map[string]string, error) {
+	logger := c.cfg.Logger
+	ctx := context.Background()
+
+	namespace, err := oci.ParseReference(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	if namespace.Tag != "" {
+		return nil, fmt.Errorf("OCI registry source %q cannot include a tag, use a ref instead", opts.Source)
+	}
+
+	client := &oci.Client{Username: opts.Username, Password: opts.Password}
+	if len(opts.TrustedKeys) > 0 {
+		client.Verify = func(digest []byte, sig string) error {
+			signer, err := signature.Verify(opts.TrustedKeys, digest, sig)
+			if err != nil {
+				return err
+			}
+			logger.Debug(fmt.Sprintf("Pack %s signed by %s", digest, signature.Identity(signer)))
+			return nil
+		}
+	}
+
+	names := []string{opts.PackName}
+	if opts.PackName == "" {
+		repos, err := client.Repositories(ctx, namespace)
+		if err != nil {
+			logger.ErrorWithContext(err, "could not list packs of OCI registry", c.ErrorContext.GetAll()...)
+			return nil, err
+		}
+		if len(repos) == 0 {
+			return nil, fmt.Errorf("no packs found in %s, target a single pack to add it without listing the registry", opts.Source)
+		}
+		names = names[:0]
+		for _, repo := range repos {
+			names = append(names, path.Base(repo))
+		}
+	}
+
+	tag := opts.Ref
+	if opts.IsLatest() {
+		tag = oci.DefaultTag
+	}
+
+	digests := make(map[string]string, len(names))
+	for _, name := range names {
+		ref, err := namespace.Pack(name, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Debug(fmt.Sprintf("Pulling pack %s", ref))
+		digest, err := client.Pull(ctx, ref, path.Join(c.clonedPacksPath(), name))
+		if err != nil {
+			logger.ErrorWithContext(err, "could not pull pack", c.ErrorContext.GetAll()...)
+			return nil, err
+		}
+		digests[name] = digest
+	}
+
+	logger.Debug(fmt.Sprintf("Registry successfully pulled to %s", c.clonePath()))
+	return digests, nil
+}
@@ -8,8 +8,11 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser"
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser/config"
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 )
 
@@ -77,9 +80,60 @@ func (cfg *PackConfig) initFromArgs() {
 // showing the registry in the global cache differentiated from the pack metadata.
 type Pack struct {
 	Ref string
+	// Path is the directory of the pack within the cache.
+	Path string
+	// indexEntry is the entry of the registry index the pack was listed
+	// from, if any, in which case the pack only holds its metadata.
+	indexEntry *IndexEntry
 	*pack.Pack
 }
 
+// PackVariable is the name and description of a variable declared by a
+// pack.
+type PackVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Variables returns the variables declared by the pack, not including those
+// of its dependencies. They are read from the registry index when the pack
+// was listed from one, and parsed from the variables file of the pack
+// otherwise.
+func (p *Pack) Variables() ([]*PackVariable, error) {
+	if p.indexEntry != nil {
+		return p.indexEntry.Variables, nil
+	}
+	if p.Pack == nil || p.RootVariableFile == nil {
+		return nil, nil
+	}
+	return packVariables(p.Pack)
+}
+
+// packVariables parses the variables declared by the root variable file of
+// p, sorted by name.
+func packVariables(p *pack.Pack) ([]*PackVariable, error) {
+	varParser, err := parser.NewParserV2(&config.ParserConfig{
+		Version:           config.V2,
+		ParentPack:        p,
+		RootVariableFiles: p.RootVariableFiles(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parsedVars, diags := varParser.Parse()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var out []*PackVariable
+	for _, v := range parsedVars.GetVars()[p.ID()] {
+		out = append(out, &PackVariable{Name: v.Name.String(), Description: v.Description})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
 func invalidPackDefinition(provider cacheOperationProvider) *Pack {
 	return &Pack{
 		Ref: provider.AtRef(),
@@ -26,8 +26,12 @@ type Registry struct {
 	// or an actual git ref)
 	Ref string `json:"ref,omitempty"`
 	// LocalRef is a reference to the git SHA that we have available locally
-	LocalRef string  `json:"local_ref,omitempty"`
-	Packs    []*Pack `json:"-"`
+	LocalRef string `json:"local_ref,omitempty"`
+	// Checksums are the hashes of the packs as they were served by the
+	// registry, by pack name, as returned by HashPack. They are used to
+	// detect cached packs which have been modified since they were added.
+	Checksums map[string]string `json:"checksums,omitempty"`
+	Packs     []*Pack           `json:"-"`
 }
 
 // get will attempt to load the specified packs from a path, and then append them
@@ -61,6 +65,20 @@ func (r *Registry) get(opts *GetOpts, cache *Cache) error {
 		r.LocalRef = cachedRegistry.LocalRef
 		r.Source = cachedRegistry.Source
 		r.Ref = cachedRegistry.Ref
+		r.Checksums = cachedRegistry.Checksums
+	}
+
+	// Read the index of the registry if there is one, so that the packs it
+	// lists do not need to be loaded.
+	// An index of an older version lacks the flags and variables of the
+	// packs, so they are loaded instead until the index is regenerated.
+	index, err := ReadIndex(opts.RegistryPath())
+	if err != nil {
+		cache.cfg.Logger.Debug(fmt.Sprintf("ignoring %s of registry %s: %s", IndexFileName, opts.RegistryName, err))
+		index = nil
+	} else if index != nil && index.Version < IndexVersion {
+		cache.cfg.Logger.Debug(fmt.Sprintf("ignoring %s of registry %s: version %d is outdated", IndexFileName, opts.RegistryName, index.Version))
+		index = nil
 	}
 
 	// Iterate over the packs in the registry and load each pack so that
@@ -103,6 +121,19 @@ func (r *Registry) get(opts *GetOpts, cache *Cache) error {
 			continue
 		}
 
+		// Use the metadata from the index when the pack is listed in it.
+		if index != nil {
+			if entry := index.Get(nameFromPackEntry(packEntry)); entry != nil {
+				r.add(&Pack{
+					Ref:        refFromPackEntry(packEntry),
+					Path:       opts.toPackDir(packEntry),
+					indexEntry: entry,
+					Pack:       entry.pack(),
+				})
+				continue
+			}
+		}
+
 		// Attempt to load the pack.
 		loadedPack, err = loader.Load(opts.toPackDir(packEntry))
 		if err != nil {
@@ -119,6 +150,7 @@ func (r *Registry) get(opts *GetOpts, cache *Cache) error {
 		} else {
 			cachedPack = &Pack{
 				Ref:  refFromPackEntry(packEntry),
+				Path: opts.toPackDir(packEntry),
 				Pack: loadedPack,
 			}
 		}
@@ -0,0 +1,194 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package caching
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci"
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci/ocitest"
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature"
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature/signaturetest"
+	"github.com/hashicorp/nomad-pack/internal/pkg/testfixture"
+)
+
+// signedTestRegistry copies the test registry into a git repository whose
+// single commit is signed by key, or left unsigned if key is nil.
+func signedTestRegistry(t *testing.T, key *openpgp.Entity) string {
+	t.Helper()
+	registryPath := filepath.Join(t.TempDir(), "registry.git")
+	must.NoError(t, filesystem.CopyDir(testfixture.MustAbsPath("v2/test_registry"), registryPath, false, NoopLogger{}))
+
+	repo, err := git.PlainInit(registryPath, false)
+	must.NoError(t, err)
+	w, err := repo.Worktree()
+	must.NoError(t, err)
+	_, err = w.Add(".")
+	must.NoError(t, err)
+	_, err = w.Commit("Initial Commit", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Test User",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+		SignKey: key,
+	})
+	must.NoError(t, err)
+	return registryPath
+}
+
+func TestAddRegistryWithTrustedKeys(t *testing.T) {
+	ci.Parallel(t)
+
+	trusted := signaturetest.NewKey(t, "trusted")
+	untrusted := signaturetest.NewKey(t, "untrusted")
+	keys := openpgp.EntityList{trusted}
+
+	cacheDir := t.TempDir()
+	cache, err := NewCache(&CacheConfig{
+		Path:   cacheDir,
+		Logger: NewTestLogger(t),
+	})
+	must.NoError(t, err)
+
+	signed := signedTestRegistry(t, trusted)
+	cachedRegistry, err := cache.Add(&AddOpts{RegistryName: "signed", Source: signed, TrustedKeys: keys})
+	must.NoError(t, err)
+	must.SliceNotEmpty(t, cachedRegistry.Packs)
+
+	// A single pack is verified against the commit of the whole registry.
+	cachedRegistry, err = cache.Add(&AddOpts{
+		RegistryName: "signed-pack",
+		Source:       signed,
+		PackName:     "simple_raw_exec",
+		TrustedKeys:  keys,
+	})
+	must.NoError(t, err)
+	must.Len(t, 1, cachedRegistry.Packs)
+
+	_, err = cache.Add(&AddOpts{RegistryName: "unsigned", Source: signedTestRegistry(t, nil), TrustedKeys: keys})
+	must.ErrorIs(t, err, signature.ErrNotSigned)
+	must.DirNotExists(t, filepath.Join(cacheDir, "unsigned", DefaultRef))
+
+	_, err = cache.Add(&AddOpts{RegistryName: "untrusted", Source: signedTestRegistry(t, untrusted), TrustedKeys: keys})
+	must.ErrorContains(t, err, "failed to verify registry signature")
+	must.DirNotExists(t, filepath.Join(cacheDir, "untrusted", DefaultRef))
+}
+
+func TestAddRegistryFromOCIWithTrustedKeys(t *testing.T) {
+	ci.Parallel(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	trusted := signaturetest.NewKey(t, "trusted")
+	registry := ocitest.NewRegistry(t)
+	source := "oci://" + registry.Host() + "/packs"
+	namespace, err := oci.ParseReference(source)
+	must.NoError(t, err)
+
+	signer := &oci.Client{Sign: func(digest []byte) (string, error) {
+		return signature.Sign(trusted, digest)
+	}}
+	for name, client := range map[string]*oci.Client{"simple_raw_exec": signer, "my_alias_test": {}} {
+		ref, err := namespace.Pack(name, "")
+		must.NoError(t, err)
+		_, err = client.Push(ctx, ref, testfixture.MustAbsPath("v2/test_registry/packs/"+name), nil)
+		must.NoError(t, err)
+	}
+
+	cache, err := NewCache(&CacheConfig{
+		Path:   t.TempDir(),
+		Logger: NewTestLogger(t),
+	})
+	must.NoError(t, err)
+
+	keys := openpgp.EntityList{trusted}
+	cachedRegistry, err := cache.Add(&AddOpts{RegistryName: "oci", Source: source, PackName: "simple_raw_exec", TrustedKeys: keys})
+	must.NoError(t, err)
+	must.Len(t, 1, cachedRegistry.Packs)
+
+	_, err = cache.Add(&AddOpts{RegistryName: "oci", Source: source, PackName: "my_alias_test", TrustedKeys: keys})
+	must.ErrorIs(t, err, signature.ErrNotSigned)
+
+	_, err = cache.Add(&AddOpts{
+		RegistryName: "oci",
+		Source:       source,
+		PackName:     "simple_raw_exec",
+		TrustedKeys:  openpgp.EntityList{signaturetest.NewKey(t, "other")},
+	})
+	must.ErrorContains(t, err, "invalid signature")
+}
+
+func TestVerifyPackChecksum(t *testing.T) {
+	ci.Parallel(t)
+
+	cacheDir := t.TempDir()
+	cache, err := NewCache(&CacheConfig{
+		Path:   cacheDir,
+		Logger: NewTestLogger(t),
+	})
+	must.NoError(t, err)
+
+	cachedRegistry, err := cache.Add(&AddOpts{RegistryName: "checksums", Source: signedTestRegistry(t, nil)})
+	must.NoError(t, err)
+	must.MapLen(t, len(cachedRegistry.Packs), cachedRegistry.Checksums)
+
+	packPath := filepath.Join(cacheDir, "checksums", DefaultRef, AppendRef("simple_raw_exec", DefaultRef))
+	expected, err := HashPack(packPath)
+	must.NoError(t, err)
+	must.Eq(t, expected, cachedRegistry.Checksums["simple_raw_exec"])
+
+	cfg := &PackConfig{Registry: "checksums", Name: "simple_raw_exec", Ref: DefaultRef, Path: packPath}
+	must.NoError(t, VerifyPackChecksum(cfg))
+
+	// The checksums are read back along with the registry.
+	cachedRegistry, err = cache.Get(&GetOpts{RegistryName: "checksums", Ref: DefaultRef})
+	must.NoError(t, err)
+	must.Eq(t, expected, cachedRegistry.Checksums["simple_raw_exec"])
+
+	must.NoError(t, os.WriteFile(filepath.Join(packPath, "templates", "injected.nomad.tpl"), []byte("job {}"), 0644))
+	err = VerifyPackChecksum(cfg)
+	must.ErrorIs(t, err, errors.ErrPackModified)
+
+	// Updating the registry restores the pack.
+	_, err = cache.Add(&AddOpts{RegistryName: "checksums", Source: signedTestRegistry(t, nil)})
+	must.NoError(t, err)
+	must.NoError(t, VerifyPackChecksum(cfg))
+
+	// A ref other than latest is restored too, although it is already cached.
+	registryPath := signedTestRegistry(t, nil)
+	_, err = cache.Add(&AddOpts{RegistryName: "checksums", Source: registryPath, Ref: "master"})
+	must.NoError(t, err)
+	refCfg := &PackConfig{
+		Registry: "checksums",
+		Name:     "simple_raw_exec",
+		Ref:      "master",
+		Path:     filepath.Join(cacheDir, "checksums", "master", AppendRef("simple_raw_exec", "master")),
+	}
+	must.NoError(t, VerifyPackChecksum(refCfg))
+
+	must.NoError(t, os.WriteFile(filepath.Join(refCfg.Path, "templates", "injected.nomad.tpl"), []byte("job {}"), 0644))
+	must.ErrorIs(t, VerifyPackChecksum(refCfg), errors.ErrPackModified)
+
+	_, err = cache.Add(&AddOpts{RegistryName: "checksums", Source: registryPath, Ref: "master"})
+	must.NoError(t, err)
+	must.NoError(t, VerifyPackChecksum(refCfg))
+	must.FileNotExists(t, filepath.Join(refCfg.Path, "templates", "injected.nomad.tpl"))
+
+	// Packs without a recorded checksum are not verified.
+	must.NoError(t, VerifyPackChecksum(&PackConfig{Registry: "checksums", Name: "missing", Path: filepath.Join(cacheDir, "checksums", DefaultRef, "missing")}))
+	must.NoError(t, VerifyPackChecksum(&PackConfig{Registry: DevRegistryName, Name: "local", Path: t.TempDir()}))
+}
@@ -0,0 +1,215 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+// LockFileName is the name of the file, alongside metadata.hcl, which records
+// the resolved version of each vendored dependency.
+const LockFileName = "pack.lock.hcl"
+
+const lockFileHeader = `# This file is maintained automatically by "nomad-pack deps vendor".
+# Manual edits may be lost in future updates.
+`
+
+// LockFile is the decoded content of a pack.lock.hcl file.
+type LockFile struct {
+	Dependencies []*LockedDependency `hcl:"dependency,block"`
+}
+
+// LockedDependency records what a dependency block of the pack metadata
+// resolved to when it was vendored.
+type LockedDependency struct {
+	// Name is the name of the dependency. The name of a transitive dependency
+	// is prefixed by the names of the dependencies it is nested within,
+	// separated by slashes, such as "helpers/base".
+	Name string `hcl:"name,label"`
+
+	// Source, Ref and Constraints are copied from the dependency block, so a
+	// lock entry which no longer matches the metadata can be detected.
+	Source      string `hcl:"source"`
+	Ref         string `hcl:"ref,optional"`
+	Constraints string `hcl:"constraints,optional"`
+
+	// Version is the version the constraints of the dependency resolved to,
+	// from either a git tag or the pack metadata.
+	Version string `hcl:"version,optional"`
+
+	// Commit is the git commit the dependency resolved to. It is empty for
+	// sources which are not git repositories.
+	Commit string `hcl:"commit,optional"`
+
+	// Hash is the hash of the content of the vendored dependency, in the
+	// same "h1:" form used by Go modules.
+	Hash string `hcl:"hash"`
+}
+
+// Get returns the locked dependency with the given name, or nil if there is
+// none.
+func (l *LockFile) Get(name string) *LockedDependency {
+	if l == nil {
+		return nil
+	}
+	for _, d := range l.Dependencies {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// matches returns an error if the dependency block has changed since it was
+// locked.
+func (d *LockedDependency) matches(dep *pack.Dependency) error {
+	if d.Source != dep.Source || d.Ref != dep.Ref || d.Constraints != dep.Version {
+		return fmt.Errorf("dependency %q has changed since it was locked in %s, vendor it with --upgrade to update the lock", d.Name, LockFileName)
+	}
+	return nil
+}
+
+// ReadLockFile reads the lock file of the pack at packPath. A nil LockFile
+// and no error are returned if the pack has no lock file.
+func ReadLockFile(packPath string) (*LockFile, error) {
+	lockPath := path.Join(packPath, LockFileName)
+	if _, err := os.Stat(lockPath); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	var lock LockFile
+	if err := hclsimple.DecodeFile(lockPath, nil, &lock); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", LockFileName, err)
+	}
+	return &lock, nil
+}
+
+// WriteLockFile writes the lock file of the pack at packPath, replacing any
+// existing one.
+func WriteLockFile(packPath string, lock *LockFile) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	for i, d := range lock.Dependencies {
+		if i > 0 {
+			body.AppendNewline()
+		}
+		block := body.AppendNewBlock("dependency", []string{d.Name}).Body()
+		block.SetAttributeValue("source", cty.StringVal(d.Source))
+		if d.Ref != "" {
+			block.SetAttributeValue("ref", cty.StringVal(d.Ref))
+		}
+		if d.Constraints != "" {
+			block.SetAttributeValue("constraints", cty.StringVal(d.Constraints))
+		}
+		if d.Version != "" {
+			block.SetAttributeValue("version", cty.StringVal(d.Version))
+		}
+		if d.Commit != "" {
+			block.SetAttributeValue("commit", cty.StringVal(d.Commit))
+		}
+		block.SetAttributeValue("hash", cty.StringVal(d.Hash))
+	}
+
+	content := append([]byte(lockFileHeader+"\n"), f.Bytes()...)
+	if err := os.WriteFile(path.Join(packPath, LockFileName), content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LockFileName, err)
+	}
+	return nil
+}
+
+// Verify checks the vendored dependencies of the pack at packPath, and their
+// own dependencies in turn, against the lock file of the pack, if it has one.
+// Dependencies without a source are not vendored, so are not checked, though
+// their dependencies are.
+func Verify(packPath string) error {
+	lock, err := ReadLockFile(packPath)
+	if err != nil || lock == nil {
+		return err
+	}
+
+	metadata, err := readMetadata(packPath)
+	if err != nil {
+		return err
+	}
+	return verifyPack(lock, packPath, metadata, "")
+}
+
+func verifyPack(lock *LockFile, packPath string, metadata *pack.Metadata, lockPrefix string) error {
+	for _, dep := range metadata.Dependencies {
+		if dep.Enabled != nil && !*dep.Enabled {
+			continue
+		}
+
+		depPath := path.Join(packPath, "deps", dep.Name)
+		key := lockPrefix + dep.Name
+
+		if dep.Source != "" {
+			locked := lock.Get(key)
+			if locked == nil {
+				return fmt.Errorf("dependency %q is not locked in %s, vendor it with --upgrade to add it", key, LockFileName)
+			}
+			if err := locked.matches(dep); err != nil {
+				return err
+			}
+
+			hash, err := HashDir(depPath)
+			if err != nil {
+				return fmt.Errorf("failed to hash dependency %q: %w", key, err)
+			}
+			if hash != locked.Hash {
+				return fmt.Errorf("dependency %q does not match %s: locked hash %s, vendored hash %s",
+					key, LockFileName, locked.Hash, hash)
+			}
+		}
+
+		// A missing dependency is reported by the loader.
+		depMetadata, err := readMetadata(depPath)
+		if err != nil {
+			continue
+		}
+		if err := verifyPack(lock, depPath, depMetadata, key+"/"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HashDir returns the hash of the files within the pack at dir, ignoring any
+// .git directory. The deps directory of the pack is also ignored, as the
+// dependencies vendored into it are locked separately. A dir which is a
+// symlink is followed.
+func HashDir(dir string) (string, error) {
+	return filesystem.HashDir(dir, "deps")
+}
+
+// resolvedCommit returns the commit checked out in dir, or an empty string if
+// dir is not a git repository.
+func resolvedCommit(dir string) (string, error) {
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
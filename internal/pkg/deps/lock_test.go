@@ -0,0 +1,346 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+func TestLockFile_WriteRead(t *testing.T) {
+	dir := t.TempDir()
+	lock := &LockFile{Dependencies: []*LockedDependency{
+		{Name: "a", Source: "git::https://example.com/a", Ref: "v1.0.0", Commit: "abc123", Hash: "h1:aaa"},
+		{Name: "b", Source: "./b", Hash: "h1:bbb"},
+	}}
+	must.NoError(t, WriteLockFile(dir, lock))
+
+	content, err := os.ReadFile(path.Join(dir, LockFileName))
+	must.NoError(t, err)
+	must.StrHasPrefix(t, lockFileHeader, string(content))
+	must.StrNotContains(t, string(content), `ref    = ""`)
+
+	read, err := ReadLockFile(dir)
+	must.NoError(t, err)
+	must.Eq(t, lock, read)
+	must.Eq(t, "abc123", read.Get("a").Commit)
+	must.Nil(t, read.Get("c"))
+
+	// A pack without a lock file has nothing locked.
+	read, err = ReadLockFile(t.TempDir())
+	must.NoError(t, err)
+	must.Nil(t, read)
+	must.Nil(t, read.Get("a"))
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	must.NoError(t, os.WriteFile(path.Join(dir, "metadata.hcl"), []byte("app {}"), 0o644))
+	must.NoError(t, os.MkdirAll(path.Join(dir, ".git"), 0o755))
+	must.NoError(t, os.WriteFile(path.Join(dir, ".git", "HEAD"), []byte("ref"), 0o644))
+
+	hash, err := HashDir(dir)
+	must.NoError(t, err)
+	must.StrHasPrefix(t, "h1:", hash)
+
+	// Git metadata is not part of the content.
+	must.NoError(t, os.WriteFile(path.Join(dir, ".git", "HEAD"), []byte("other"), 0o644))
+	again, err := HashDir(dir)
+	must.NoError(t, err)
+	must.Eq(t, hash, again)
+
+	// Symlinked directories are hashed by their target.
+	link := path.Join(t.TempDir(), "link")
+	must.NoError(t, os.Symlink(dir, link))
+	linked, err := HashDir(link)
+	must.NoError(t, err)
+	must.Eq(t, hash, linked)
+
+	must.NoError(t, os.WriteFile(path.Join(dir, "metadata.hcl"), []byte("app {}\n"), 0o644))
+	changed, err := HashDir(dir)
+	must.NoError(t, err)
+	must.NotEq(t, hash, changed)
+}
+
+func TestVendor_Lock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	uiCtx, cancel := helper.WithInterrupt(context.Background())
+	defer cancel()
+	ui := testui.NonInteractiveTestUI(uiCtx, new(bytes.Buffer), new(bytes.Buffer))
+
+	srcDir := t.TempDir()
+	must.NoError(t, createTestDepRepo(t, srcDir))
+	firstCommit := headCommit(t, srcDir)
+
+	packDir := t.TempDir()
+	dep := &pack.Dependency{
+		Name:   "simple_raw_exec",
+		Source: fmt.Sprintf("git::file://%s//simple_raw_exec", srcDir),
+	}
+	writeTestMetadata(t, packDir, dep)
+
+	must.NoError(t, Vendor(ctx, ui, packDir, false))
+	lock, err := ReadLockFile(packDir)
+	must.NoError(t, err)
+	locked := lock.Get("simple_raw_exec")
+	must.NotNil(t, locked)
+	must.Eq(t, firstCommit, locked.Commit)
+	must.Eq(t, dep.Source, locked.Source)
+	must.NoError(t, Verify(packDir))
+
+	// A new commit to the dependency is not picked up while it is locked.
+	commitTestChange(t, srcDir, "simple_raw_exec/README.md", "changed\n")
+	must.NoError(t, Vendor(ctx, ui, packDir, false))
+	relocked, err := ReadLockFile(packDir)
+	must.NoError(t, err)
+	must.Eq(t, locked, relocked.Get("simple_raw_exec"))
+
+	// Upgrading resolves the new commit.
+	must.NoError(t, Vendor(ctx, ui, packDir, true))
+	upgraded, err := ReadLockFile(packDir)
+	must.NoError(t, err)
+	must.Eq(t, headCommit(t, srcDir), upgraded.Get("simple_raw_exec").Commit)
+	must.NotEq(t, locked.Hash, upgraded.Get("simple_raw_exec").Hash)
+
+	// Changes to the vendored content are caught.
+	must.NoError(t, os.WriteFile(path.Join(packDir, "deps", "simple_raw_exec", "README.md"), []byte("edited\n"), 0o644))
+	must.ErrorContains(t, Verify(packDir), `dependency "simple_raw_exec" does not match pack.lock.hcl`)
+
+	// Changing the dependency block requires an upgrade.
+	dep.Ref = "v1.0.0"
+	writeTestMetadata(t, packDir, dep)
+	must.ErrorContains(t, Vendor(ctx, ui, packDir, false), "has changed since it was locked")
+	must.ErrorContains(t, Verify(packDir), "has changed since it was locked")
+}
+
+func TestVendor_Version(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	uiCtx, cancel := helper.WithInterrupt(context.Background())
+	defer cancel()
+	uiStdout := new(bytes.Buffer)
+	ui := testui.NonInteractiveTestUI(uiCtx, uiStdout, new(bytes.Buffer))
+
+	srcDir := t.TempDir()
+	must.NoError(t, createTestDepRepo(t, srcDir))
+	tagTestRepo(t, srcDir, "v1.2.0")
+	commitTestChange(t, srcDir, "simple_raw_exec/README.md", "1.3\n")
+	tagTestRepo(t, srcDir, "v1.3.0")
+	v13Commit := headCommit(t, srcDir)
+	commitTestChange(t, srcDir, "simple_raw_exec/README.md", "2.0\n")
+	tagTestRepo(t, srcDir, "v2.0.0")
+	tagTestRepo(t, srcDir, "not-a-version")
+
+	packDir := t.TempDir()
+	dep := &pack.Dependency{
+		Name:    "simple_raw_exec",
+		Source:  fmt.Sprintf("git::file://%s//simple_raw_exec", srcDir),
+		Version: "~> 1.2",
+	}
+	writeTestMetadata(t, packDir, dep)
+
+	// The highest tag satisfying the constraint is used.
+	must.NoError(t, Vendor(ctx, ui, packDir, false))
+	must.StrContains(t, uiStdout.String(), "downloading simple_raw_exec pack version 1.3.0")
+	lock, err := ReadLockFile(packDir)
+	must.NoError(t, err)
+	locked := lock.Get("simple_raw_exec")
+	must.Eq(t, "~> 1.2", locked.Constraints)
+	must.Eq(t, "1.3.0", locked.Version)
+	must.Eq(t, v13Commit, locked.Commit)
+
+	readme, err := os.ReadFile(path.Join(packDir, "deps", "simple_raw_exec", "README.md"))
+	must.NoError(t, err)
+	must.Eq(t, "1.3\n", string(readme))
+
+	// Without a matching tag, the pack version is checked, which for the
+	// fixture pack is 0.0.1.
+	dep.Version = "~> 0.0.1"
+	writeTestMetadata(t, packDir, dep)
+	must.NoError(t, Vendor(ctx, ui, packDir, true))
+	lock, err = ReadLockFile(packDir)
+	must.NoError(t, err)
+	must.Eq(t, "0.0.1", lock.Get("simple_raw_exec").Version)
+
+	dep.Version = "~> 3.0"
+	writeTestMetadata(t, packDir, dep)
+	must.ErrorContains(t, Vendor(ctx, ui, packDir, true),
+		`no version of dependency "simple_raw_exec" satisfies "~> 3.0"`)
+
+	// A dependency cannot be pinned to a ref and a version.
+	dep.Ref = "v1.2.0"
+	writeTestMetadata(t, packDir, dep)
+	must.ErrorContains(t, Vendor(ctx, ui, packDir, true), "cannot set both ref and version")
+}
+
+func TestVendor_Transitive(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	uiCtx, cancel := helper.WithInterrupt(context.Background())
+	defer cancel()
+	uiStdout := new(bytes.Buffer)
+	ui := testui.NonInteractiveTestUI(uiCtx, uiStdout, new(bytes.Buffer))
+
+	// The repository holds three layers of packs: app depends on helpers and
+	// base, and helpers depends on base.
+	srcDir := t.TempDir()
+	source := func(name string) string { return fmt.Sprintf("git::file://%s//%s", srcDir, name) }
+	writeTestPack(t, path.Join(srcDir, "base"), "base")
+	writeTestPack(t, path.Join(srcDir, "helpers"), "helpers", &pack.Dependency{Name: "base", Source: source("base")})
+	initTestRepo(t, srcDir)
+
+	packDir := t.TempDir()
+	writeTestPack(t, packDir, "app",
+		&pack.Dependency{Name: "helpers", Source: source("helpers")},
+		&pack.Dependency{Name: "base", Source: source("base")},
+	)
+
+	must.NoError(t, Vendor(ctx, ui, packDir, false))
+	must.FileExists(t, path.Join(packDir, "deps", "helpers", "deps", "base", "metadata.hcl"))
+	must.FileExists(t, path.Join(packDir, "deps", "base", "metadata.hcl"))
+
+	lock, err := ReadLockFile(packDir)
+	must.NoError(t, err)
+	must.Len(t, 3, lock.Dependencies)
+	must.NotNil(t, lock.Get("helpers/base"))
+	must.Eq(t, lock.Get("base").Hash, lock.Get("helpers/base").Hash)
+	must.NoError(t, Verify(packDir))
+
+	commit := shortCommit(headCommit(t, srcDir))
+	must.StrContains(t, uiStdout.String(), fmt.Sprintf(`app
+├── helpers (%[1]s)
+│   └── base (%[1]s)
+└── base (%[1]s)`, commit))
+
+	// Transitive dependencies are verified too.
+	must.NoError(t, os.WriteFile(path.Join(packDir, "deps", "helpers", "deps", "base", "README.md"), []byte("edited\n"), 0o644))
+	must.ErrorContains(t, Verify(packDir), `dependency "helpers/base" does not match pack.lock.hcl`)
+}
+
+func TestVendor_Cycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	uiCtx, cancel := helper.WithInterrupt(context.Background())
+	defer cancel()
+	ui := testui.NonInteractiveTestUI(uiCtx, new(bytes.Buffer), new(bytes.Buffer))
+
+	srcDir := t.TempDir()
+	source := func(name string) string { return fmt.Sprintf("git::file://%s//%s", srcDir, name) }
+	writeTestPack(t, path.Join(srcDir, "a"), "a", &pack.Dependency{Name: "b", Source: source("b")})
+	writeTestPack(t, path.Join(srcDir, "b"), "b", &pack.Dependency{Name: "a", Source: source("a")})
+	initTestRepo(t, srcDir)
+
+	packDir := t.TempDir()
+	writeTestPack(t, packDir, "app", &pack.Dependency{Name: "a", Source: source("a")})
+
+	must.EqError(t, Vendor(ctx, ui, packDir, false), "dependency cycle detected: app -> a -> b -> a")
+}
+
+// writeTestPack writes the metadata.hcl of a pack with the dependencies.
+func writeTestPack(t *testing.T, dir, name string, deps ...*pack.Dependency) {
+	t.Helper()
+	must.NoError(t, os.MkdirAll(dir, 0o755))
+
+	content := fmt.Sprintf("app {\n  url = \"\"\n}\n\npack {\n  name    = %q\n  version = \"0.1.0\"\n}\n", name)
+	for _, d := range deps {
+		content += fmt.Sprintf("\ndependency %q {\n  source = %q\n", d.Name, d.Source)
+		if d.Version != "" {
+			content += fmt.Sprintf("  version = %q\n", d.Version)
+		}
+		content += "}\n"
+	}
+	must.NoError(t, os.WriteFile(path.Join(dir, "metadata.hcl"), []byte(content), 0o644))
+}
+
+func initTestRepo(t *testing.T, dir string) {
+	t.Helper()
+	r, err := git.PlainInit(dir, false)
+	must.NoError(t, err)
+	w, err := r.Worktree()
+	must.NoError(t, err)
+	_, err = w.Add(".")
+	must.NoError(t, err)
+	_, err = w.Commit("Initial Commit", &git.CommitOptions{Author: &object.Signature{
+		Name:  "Github Action Test User",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}})
+	must.NoError(t, err)
+}
+
+func tagTestRepo(t *testing.T, repoDir, tag string) {
+	t.Helper()
+	r, err := git.PlainOpen(repoDir)
+	must.NoError(t, err)
+	head, err := r.Head()
+	must.NoError(t, err)
+	_, err = r.CreateTag(tag, head.Hash(), nil)
+	must.NoError(t, err)
+}
+
+func writeTestMetadata(t *testing.T, packDir string, dep *pack.Dependency) {
+	t.Helper()
+	content := fmt.Sprintf(`app {
+  url = ""
+}
+
+pack {
+  name        = "deps_test"
+  description = "This pack tests dependencies"
+  version     = "0.0.1"
+}
+
+dependency %q {
+  source  = %q
+  ref     = %q
+  version = %q
+}
+`, dep.Name, dep.Source, dep.Ref, dep.Version)
+	must.NoError(t, os.WriteFile(path.Join(packDir, "metadata.hcl"), []byte(content), 0o644))
+}
+
+func headCommit(t *testing.T, repoDir string) string {
+	t.Helper()
+	r, err := git.PlainOpen(repoDir)
+	must.NoError(t, err)
+	head, err := r.Head()
+	must.NoError(t, err)
+	return head.Hash().String()
+}
+
+func commitTestChange(t *testing.T, repoDir, file, content string) {
+	t.Helper()
+	must.NoError(t, os.WriteFile(path.Join(repoDir, file), []byte(content), 0o644))
+
+	r, err := git.PlainOpen(repoDir)
+	must.NoError(t, err)
+	w, err := r.Worktree()
+	must.NoError(t, err)
+	_, err = w.Add(file)
+	must.NoError(t, err)
+	_, err = w.Commit("Change", &git.CommitOptions{Author: &object.Signature{
+		Name:  "Github Action Test User",
+		Email: "test@example.com",
+		When:  time.Now(),
+	}})
+	must.NoError(t, err)
+}
@@ -0,0 +1,157 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/hashicorp/go-version"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+// OutdatedDependency is a dependency of a pack for which a newer version is
+// available at its source.
+type OutdatedDependency struct {
+	// Name is the name of the dependency, prefixed by the names of the
+	// dependencies it is nested within in the same way as lock entries.
+	Name   string
+	Source string
+
+	// Current is the version, or short commit, the dependency is vendored
+	// at, and Latest the newest one available at its source.
+	Current string
+	Latest  string
+}
+
+// Outdated checks the dependencies of the pack at packPath, and those of its
+// vendored dependencies in turn, against their git sources. A dependency
+// pinned to a version, through its lock entry or a tag ref, is outdated when
+// a tag with a higher version exists. Otherwise, its locked commit is
+// compared with the commit of its ref, or the default branch. Dependencies
+// which are disabled, not locked, or not fetched with git are skipped.
+func Outdated(ctx context.Context, packPath string) ([]*OutdatedDependency, error) {
+	metadata, err := readMetadata(packPath)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := ReadLockFile(packPath)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &outdatedChecker{ctx: ctx, lock: lock, refs: make(map[string][]*plumbing.Reference)}
+	if err := o.checkPack(packPath, metadata, ""); err != nil {
+		return nil, err
+	}
+	return o.outdated, nil
+}
+
+// outdatedChecker holds the state of a single call to Outdated. The refs of
+// each source are only listed once.
+type outdatedChecker struct {
+	ctx      context.Context
+	lock     *LockFile
+	refs     map[string][]*plumbing.Reference
+	outdated []*OutdatedDependency
+}
+
+func (o *outdatedChecker) checkPack(packPath string, metadata *pack.Metadata, lockPrefix string) error {
+	for _, d := range metadata.Dependencies {
+		if d.Enabled != nil && !*d.Enabled {
+			continue
+		}
+
+		key := lockPrefix + d.Name
+		if d.Source != "" {
+			if err := o.checkDependency(d, key); err != nil {
+				return fmt.Errorf("failed to check dependency %q: %w", key, err)
+			}
+		}
+
+		// Dependencies which have not been vendored have nothing to check.
+		depPath := path.Join(packPath, "deps", d.Name)
+		depMetadata, err := readMetadata(depPath)
+		if err != nil {
+			continue
+		}
+		if err := o.checkPack(depPath, depMetadata, key+"/"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *outdatedChecker) checkDependency(d *pack.Dependency, key string) error {
+	locked := o.lock.Get(key)
+
+	refs, ok := o.refs[d.Source]
+	if !ok {
+		var isGit bool
+		var err error
+		if refs, isGit, err = remoteRefs(o.ctx, d.Source); err != nil {
+			return err
+		}
+		if !isGit {
+			return nil
+		}
+		o.refs[d.Source] = refs
+	}
+
+	// A dependency pinned to a version is compared with the highest version
+	// tag of the source.
+	current := d.Ref
+	if locked != nil && locked.Version != "" {
+		current = locked.Version
+	}
+	if currentVersion, err := version.NewVersion(current); err == nil {
+		if tag, latest := highestTag(refs, nil); latest != nil && latest.GreaterThan(currentVersion) {
+			o.add(d, key, current, tag)
+		}
+		return nil
+	}
+
+	// Otherwise the dependency follows a branch, so the locked commit is
+	// compared with its head.
+	if locked == nil || locked.Commit == "" {
+		return nil
+	}
+	name := plumbing.HEAD
+	if !d.IsLatest() {
+		name = plumbing.NewBranchReferenceName(d.Ref)
+	}
+	head := resolveRef(refs, name)
+	if head != nil && head.Hash().String() != locked.Commit {
+		o.add(d, key, shortCommit(locked.Commit), shortCommit(head.Hash().String()))
+	}
+	return nil
+}
+
+func (o *outdatedChecker) add(d *pack.Dependency, key, current, latest string) {
+	o.outdated = append(o.outdated, &OutdatedDependency{
+		Name:    key,
+		Source:  d.Source,
+		Current: current,
+		Latest:  latest,
+	})
+}
+
+// resolveRef returns the reference with the given name within refs, following
+// a symbolic reference such as HEAD to its target. It returns nil if there is
+// no such reference.
+func resolveRef(refs []*plumbing.Reference, name plumbing.ReferenceName) *plumbing.Reference {
+	for _, ref := range refs {
+		if ref.Name() != name {
+			continue
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			return resolveRef(refs, ref.Target())
+		}
+		return ref
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+func TestOutdated(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	uiCtx, cancel := helper.WithInterrupt(context.Background())
+	defer cancel()
+	ui := testui.NonInteractiveTestUI(uiCtx, new(bytes.Buffer), new(bytes.Buffer))
+
+	// The versioned repository is tagged, while the other one is followed at
+	// its default branch.
+	versionedDir := t.TempDir()
+	writeTestPack(t, path.Join(versionedDir, "base"), "base")
+	initTestRepo(t, versionedDir)
+	tagTestRepo(t, versionedDir, "v1.0.0")
+
+	branchDir := t.TempDir()
+	writeTestPack(t, path.Join(branchDir, "helpers"), "helpers")
+	initTestRepo(t, branchDir)
+
+	baseSource := fmt.Sprintf("git::file://%s//base", versionedDir)
+	helpersSource := fmt.Sprintf("git::file://%s//helpers", branchDir)
+
+	packDir := t.TempDir()
+	writeTestPack(t, packDir, "app",
+		&pack.Dependency{Name: "base", Source: baseSource, Version: ">= 1.0"},
+		&pack.Dependency{Name: "helpers", Source: helpersSource},
+	)
+	must.NoError(t, Vendor(ctx, ui, packDir, false))
+
+	outdated, err := Outdated(ctx, packDir)
+	must.NoError(t, err)
+	must.SliceEmpty(t, outdated)
+
+	// A dependency pinned to a version is compared with the highest tag
+	// which parses as a version.
+	commitTestChange(t, versionedDir, "base/README.md", "1.1\n")
+	tagTestRepo(t, versionedDir, "v1.1.0")
+	tagTestRepo(t, versionedDir, "not-a-version")
+
+	outdated, err = Outdated(ctx, packDir)
+	must.NoError(t, err)
+	must.Eq(t, []*OutdatedDependency{
+		{Name: "base", Source: baseSource, Current: "1.0.0", Latest: "v1.1.0"},
+	}, outdated)
+
+	// A dependency following a branch is compared with its head commit.
+	lockedCommit := headCommit(t, branchDir)
+	commitTestChange(t, branchDir, "helpers/README.md", "changed\n")
+
+	outdated, err = Outdated(ctx, packDir)
+	must.NoError(t, err)
+	must.Eq(t, []*OutdatedDependency{
+		{Name: "base", Source: baseSource, Current: "1.0.0", Latest: "v1.1.0"},
+		{Name: "helpers", Source: helpersSource, Current: shortCommit(lockedCommit), Latest: shortCommit(headCommit(t, branchDir))},
+	}, outdated)
+}
@@ -0,0 +1,114 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"strings"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+// Node is a pack within the tree of dependencies of a pack.
+type Node struct {
+	Name string
+
+	// Pack is the name of the pack when the dependency is referenced by an
+	// alias, which is used as the Name of the node.
+	Pack string
+
+	// Disabled is set for dependencies which are not enabled, so are not
+	// loaded and have no children.
+	Disabled bool
+
+	// Source, Version and Commit describe what a vendored dependency resolved
+	// to. They are empty for the root pack and for dependencies shipped within
+	// their parent.
+	Source  string
+	Version string
+	Commit  string
+
+	Children []*Node
+}
+
+// label returns the text of the node within a formatted tree.
+func (n *Node) label() string {
+	var details []string
+	if n.Pack != "" && n.Pack != n.Name {
+		details = append(details, "alias of "+n.Pack)
+	}
+	if n.Version != "" {
+		details = append(details, n.Version)
+	}
+	if n.Commit != "" {
+		details = append(details, shortCommit(n.Commit))
+	}
+	if n.Disabled {
+		details = append(details, "disabled")
+	}
+	if len(details) == 0 {
+		return n.Name
+	}
+	return n.Name + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Tree returns the tree of dependencies of a loaded pack. Disabled
+// dependencies, which the pack manager does not load, are included without
+// their own dependencies. The version and commit of vendored dependencies are
+// read from lock, which may be nil.
+func Tree(p *pack.Pack, lock *LockFile) *Node {
+	root := &Node{Name: p.Name()}
+	addChildren(root, p, lock, "")
+	return root
+}
+
+func addChildren(node *Node, p *pack.Pack, lock *LockFile, lockPrefix string) {
+	for _, d := range p.Metadata.Dependencies {
+		key := lockPrefix + d.Name
+		child := &Node{Name: d.AliasOrName(), Pack: d.Name, Source: d.Source}
+		if locked := lock.Get(key); locked != nil && d.Source != "" {
+			child.Version, child.Commit = locked.Version, locked.Commit
+		}
+		node.Children = append(node.Children, child)
+
+		if d.Enabled != nil && !*d.Enabled {
+			child.Disabled = true
+			continue
+		}
+		for _, depPack := range p.Dependencies() {
+			if depPack.AliasOrName() == d.AliasOrName() {
+				addChildren(child, depPack, lock, key+"/")
+				break
+			}
+		}
+	}
+}
+
+// FormatTree returns the tree of dependencies below root as text, with one
+// pack per line.
+func FormatTree(root *Node) string {
+	var b strings.Builder
+	b.WriteString(root.label())
+	b.WriteString("\n")
+	formatChildren(&b, root, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func formatChildren(b *strings.Builder, n *Node, indent string) {
+	for i, child := range n.Children {
+		branch, next := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, next = "└── ", "    "
+		}
+		b.WriteString(indent + branch + child.label() + "\n")
+		formatChildren(b, child, indent+next)
+	}
+}
+
+// shortCommit abbreviates a git commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
@@ -0,0 +1,52 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+func TestTree(t *testing.T) {
+	newPack := func(name string, deps ...*pack.Dependency) *pack.Pack {
+		return &pack.Pack{Metadata: &pack.Metadata{
+			Pack:         &pack.MetadataPack{Name: name},
+			Dependencies: deps,
+		}}
+	}
+	enabled, disabled := true, false
+
+	// The root pack uses the "base" pack twice, once through an alias, and
+	// has a disabled dependency which was not loaded.
+	root := newPack("app",
+		&pack.Dependency{Name: "helpers", Source: "git::https://example.com/packs//helpers", Enabled: &enabled},
+		&pack.Dependency{Name: "base", Alias: "other_base", Enabled: &enabled},
+		&pack.Dependency{Name: "extra", Source: "git::https://example.com/packs//extra", Enabled: &disabled},
+	)
+	helpers := newPack("helpers", &pack.Dependency{Name: "base", Source: "git::https://example.com/packs//base", Enabled: &enabled})
+	helpers.AddDependency("base", newPack("base"))
+	root.AddDependency("helpers", helpers)
+	root.AddDependency("other_base", newPack("base"))
+
+	lock := &LockFile{Dependencies: []*LockedDependency{
+		{Name: "helpers", Version: "1.2.0", Commit: "0123456789abcdef"},
+		{Name: "helpers/base", Commit: "fedcba9876543210"},
+	}}
+
+	must.Eq(t, `app
+├── helpers (1.2.0, 01234567)
+│   └── base (fedcba98)
+├── other_base (alias of base)
+└── extra (disabled)`, FormatTree(Tree(root, lock)))
+
+	// Without a lock file, only the names are shown.
+	must.Eq(t, `app
+├── helpers
+│   └── base
+├── other_base (alias of base)
+└── extra (disabled)`, FormatTree(Tree(root, nil)))
+}
@@ -7,21 +7,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	gg "github.com/hashicorp/go-getter"
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2/hclsimple"
 
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper/filesystem"
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 	"github.com/hashicorp/nomad-pack/terminal"
 )
 
 // Vendor reads the metadata.hcl from the provided directory and downloads
-// dependencies
-func Vendor(ctx context.Context, ui terminal.UI, targetPath string) error {
+// dependencies, along with the dependencies declared by each downloaded pack,
+// into nested deps directories. Dependencies found in the pack.lock.hcl of
+// the pack are downloaded at their locked commit and checked against their
+// locked hash, unless upgrade is set, in which case they are resolved again.
+// The lock file is then rewritten with the resolved dependencies, and the
+// tree of dependencies is output.
+func Vendor(ctx context.Context, ui terminal.UI, targetPath string, upgrade bool) error {
 	// attempt to read metadata.hcl
-	metadata := &pack.Metadata{}
-	err := hclsimple.DecodeFile(path.Join(targetPath, "metadata.hcl"), nil, metadata)
+	metadata, err := readMetadata(targetPath)
 	if err != nil {
 		return err
 	}
@@ -30,23 +39,252 @@ func Vendor(ctx context.Context, ui terminal.UI, targetPath string) error {
 		return errors.New("metadata.hcl file does not contain any dependencies")
 	}
 
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	lock, err := ReadLockFile(targetPath)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "nomad-pack-deps-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	v := &vendorer{
+		ctx:     ctx,
+		ui:      ui,
+		lock:    lock,
+		upgrade: upgrade,
+		newLock: &LockFile{},
+		tmpDir:  tmpDir,
+		fetched: make(map[string]*fetchedSource),
+	}
+
+	root := &Node{Name: metadata.Pack.Name}
+	if err := v.vendorPack(targetPath, metadata, "", []string{metadata.Pack.Name}, root); err != nil {
+		return err
+	}
+
+	if err := WriteLockFile(targetPath, v.newLock); err != nil {
+		return err
+	}
+	ui.Info(fmt.Sprintf("dependencies locked in %s", path.Join(targetPath, LockFileName)))
+	ui.Output(FormatTree(root))
+	return nil
+}
+
+// vendorer holds the state of a single call to Vendor as it walks the tree
+// of dependencies.
+type vendorer struct {
+	ctx     context.Context
+	ui      terminal.UI
+	lock    *LockFile
+	upgrade bool
+	newLock *LockFile
+
+	// tmpDir holds the sources fetched so far, which are tracked in fetched
+	// by URL, so a dependency shared by several packs of the tree is only
+	// downloaded once.
+	tmpDir  string
+	fetched map[string]*fetchedSource
+}
+
+// fetchedSource is a source which has been downloaded to a temporary
+// directory.
+type fetchedSource struct {
+	dir    string
+	commit string
+}
+
+// vendorPack vendors the dependencies of the pack at packPath, then those of
+// each vendored pack in turn. lockPrefix is the prefix of the lock entries of
+// the pack's dependencies, and ancestors are the names of the packs from the
+// root to this one, used to detect cycles.
+func (v *vendorer) vendorPack(packPath string, metadata *pack.Metadata, lockPrefix string, ancestors []string, node *Node) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
 	for _, d := range metadata.Dependencies {
-		targetDir := path.Join(targetPath, "deps", d.Name)
-		url := d.Source
+		targetDir := path.Join(packPath, "deps", d.Name)
+
+		for _, ancestor := range ancestors {
+			if ancestor == d.Name {
+				return fmt.Errorf("dependency cycle detected: %s -> %s", strings.Join(ancestors, " -> "), d.Name)
+			}
+		}
+
+		// A dependency without a source is shipped within the deps directory
+		// of its parent, so it is not downloaded, but its own dependencies
+		// still need vendoring.
+		child := &Node{Name: d.Name}
+		if d.Source != "" {
+			key := lockPrefix + d.Name
+
+			var locked *LockedDependency
+			if !v.upgrade {
+				if locked = v.lock.Get(key); locked != nil {
+					if err := locked.matches(d); err != nil {
+						return err
+					}
+				}
+			}
 
-		if !d.IsLatest() {
-			url = fmt.Sprintf("%s?ref=%s", url, d.Ref)
-		} else {
-			// Attempt to shallow clone the constructed url
-			url = fmt.Sprintf("%s?depth=1", url)
+			entry, err := v.vendorDependency(d, key, locked, targetDir)
+			if err != nil {
+				return err
+			}
+			v.newLock.Dependencies = append(v.newLock.Dependencies, entry)
+			child.Source, child.Version, child.Commit = entry.Source, entry.Version, entry.Commit
+		} else if _, err := os.Stat(targetDir); err != nil {
+			return fmt.Errorf("dependency %q has no source and is not present in %s", d.Name, path.Join(packPath, "deps"))
 		}
+		node.Children = append(node.Children, child)
 
-		// download each dependency
-		ui.Info(fmt.Sprintf("downloading %v pack to %v...", d.Name, targetDir))
-		if err := gg.Get(targetDir, url, gg.WithContext(ctx)); err != nil {
-			return fmt.Errorf("error downloading dependency: %v", err)
+		depMetadata, err := readMetadata(targetDir)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata of dependency %q: %w", d.Name, err)
+		}
+		childAncestors := append(ancestors[:len(ancestors):len(ancestors)], d.Name)
+		if err := v.vendorPack(targetDir, depMetadata, lockPrefix+d.Name+"/", childAncestors, child); err != nil {
+			return err
 		}
-		ui.Success("...success!")
 	}
 	return nil
 }
+
+// vendorDependency downloads a single dependency into targetDir and returns
+// its lock entry. If the dependency is locked, the locked commit is
+// downloaded and its content must match the locked hash.
+func (v *vendorer) vendorDependency(d *pack.Dependency, key string, locked *LockedDependency, targetDir string) (*LockedDependency, error) {
+	constraints, err := d.VersionConstraints()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &LockedDependency{
+		Name:        key,
+		Source:      d.Source,
+		Ref:         d.Ref,
+		Constraints: d.Version,
+	}
+
+	// Work out the ref to download. A locked commit always wins, otherwise a
+	// version constraint is resolved against the tags of the source.
+	ref := d.Ref
+	if d.IsLatest() {
+		ref = ""
+	}
+	switch {
+	case locked != nil && locked.Commit != "":
+		ref = locked.Commit
+		entry.Version = locked.Version
+	case constraints != nil:
+		tag, ver, err := resolveTag(v.ctx, d, constraints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve version of dependency %q: %w", key, err)
+		}
+		if tag != "" {
+			ref = tag
+			entry.Version = ver.String()
+		}
+	}
+
+	if entry.Version != "" {
+		v.ui.Info(fmt.Sprintf("downloading %v pack version %v to %v...", d.Name, entry.Version, targetDir))
+	} else {
+		v.ui.Info(fmt.Sprintf("downloading %v pack to %v...", d.Name, targetDir))
+	}
+	if entry.Commit, err = v.download(d.Source, ref, targetDir); err != nil {
+		return nil, fmt.Errorf("error downloading dependency: %v", err)
+	}
+
+	// Without a matching tag, the constraint is checked against the version
+	// in the metadata of the downloaded pack.
+	if constraints != nil && entry.Version == "" {
+		packVer, err := packVersion(targetDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read version of dependency %q: %w", key, err)
+		}
+		ver, err := version.NewVersion(packVer)
+		if err != nil || !constraints.Check(ver) {
+			return nil, fmt.Errorf("no version of dependency %q satisfies %q: no matching git tag, and the pack version is %q",
+				key, d.Version, packVer)
+		}
+		entry.Version = ver.String()
+	}
+
+	if entry.Hash, err = HashDir(targetDir); err != nil {
+		return nil, fmt.Errorf("failed to hash dependency %q: %w", key, err)
+	}
+	if locked != nil && entry.Hash != locked.Hash {
+		return nil, fmt.Errorf("dependency %q does not match %s: locked hash %s, downloaded hash %s",
+			key, LockFileName, locked.Hash, entry.Hash)
+	}
+	v.ui.Success("...success!")
+
+	return entry, nil
+}
+
+// download copies source at ref into targetDir, replacing its content, and
+// returns the git commit it resolved to. The default branch is fetched if ref
+// is empty.
+func (v *vendorer) download(source, ref, targetDir string) (string, error) {
+	url := source
+	if ref != "" {
+		url = fmt.Sprintf("%s?ref=%s", url, ref)
+	} else {
+		// Attempt to shallow clone the constructed url
+		url = fmt.Sprintf("%s?depth=1", url)
+	}
+
+	// The source is fetched whole so the commit can be read from the
+	// repository before any subdirectory is copied out. This also lets
+	// dependencies from different subdirectories of a repository share a
+	// single download.
+	src, subDir := gg.SourceDirSubdir(url)
+
+	fetched, ok := v.fetched[src]
+	if !ok {
+		fetchDir := filepath.Join(v.tmpDir, fmt.Sprintf("src%d", len(v.fetched)))
+		if err := gg.Get(fetchDir, src, gg.WithContext(v.ctx)); err != nil {
+			return "", err
+		}
+
+		commit, err := resolvedCommit(fetchDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve commit: %w", err)
+		}
+		fetched = &fetchedSource{dir: fetchDir, commit: commit}
+		v.fetched[src] = fetched
+	}
+
+	packDir := fetched.dir
+	if subDir != "" {
+		var err error
+		if packDir, err = gg.SubdirGlob(fetched.dir, subDir); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.RemoveAll(targetDir); err != nil {
+		return "", err
+	}
+	if err := filesystem.CopyDir(packDir, targetDir, false, v.ui); err != nil {
+		return "", err
+	}
+	return fetched.commit, nil
+}
+
+// readMetadata decodes the metadata.hcl of the pack at packPath.
+func readMetadata(packPath string) (*pack.Metadata, error) {
+	metadata := &pack.Metadata{}
+	if err := hclsimple.DecodeFile(path.Join(packPath, "metadata.hcl"), nil, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -61,7 +61,7 @@ func TestVendor(t *testing.T) {
 
 	// first run against an empty directory
 	tmpDir1 := t.TempDir()
-	err := Vendor(ctx, ui, tmpDir1)
+	err := Vendor(ctx, ui, tmpDir1, false)
 	must.NotNil(t, err)
 	must.ErrorContains(t, err, "does not exist")
 
@@ -79,7 +79,7 @@ func TestVendor(t *testing.T) {
 		t.Error(err)
 	}
 
-	err = Vendor(ctx, ui, tmpDir2)
+	err = Vendor(ctx, ui, tmpDir2, false)
 	must.NotNil(t, err)
 	must.ErrorContains(t, err, "does not contain any dependencies")
 
@@ -104,7 +104,7 @@ func TestVendor(t *testing.T) {
 		t.Error(err)
 	}
 
-	err = Vendor(ctx, ui, tmpPackDir2)
+	err = Vendor(ctx, ui, tmpPackDir2, false)
 	must.Nil(t, err, must.Sprintf("vendoring failure: %v", err))
 	must.StrContains(t, uiStdout.String(), "success")
 
@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package deps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	gg "github.com/hashicorp/go-getter"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2/hclsimple"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+// resolveTag returns the highest git tag of the dependency source which
+// satisfies its version constraint, along with the version it was parsed as.
+// An empty tag is returned if the source is not a git repository, or none of
+// its tags satisfy the constraint.
+func resolveTag(ctx context.Context, d *pack.Dependency, constraints version.Constraints) (string, *version.Version, error) {
+	refs, ok, err := remoteRefs(ctx, d.Source)
+	if err != nil || !ok {
+		return "", nil, err
+	}
+	tag, v := highestTag(refs, constraints)
+	return tag, v, nil
+}
+
+// remoteRefs lists the references of the git repository of a go-getter
+// source. The bool is false if the source is not fetched with git.
+func remoteRefs(ctx context.Context, source string) ([]*plumbing.Reference, bool, error) {
+	repoURL, ok, err := gitRepoURL(source)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repoURL},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list tags of %s: %w", repoURL, err)
+	}
+	return refs, true, nil
+}
+
+// highestTag returns the highest tag within refs which parses as a version
+// and satisfies constraints, if they are not nil.
+func highestTag(refs []*plumbing.Reference, constraints version.Constraints) (string, *version.Version) {
+	var (
+		bestTag     string
+		bestVersion *version.Version
+	)
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+		tag := ref.Name().Short()
+		v, err := version.NewVersion(tag)
+		if err != nil || (constraints != nil && !constraints.Check(v)) {
+			continue
+		}
+		if bestVersion == nil || v.GreaterThan(bestVersion) {
+			bestTag, bestVersion = tag, v
+		}
+	}
+	return bestTag, bestVersion
+}
+
+// gitRepoURL returns the URL of the git repository of a go-getter source,
+// without any subdirectory or query. The bool is false if the source is not
+// fetched with git.
+func gitRepoURL(source string) (string, bool, error) {
+	src, _ := gg.SourceDirSubdir(source)
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", false, err
+	}
+	detected, err := gg.Detect(src, pwd, gg.Detectors)
+	if err != nil {
+		return "", false, err
+	}
+
+	repoURL, ok := strings.CutPrefix(detected, "git::")
+	if !ok {
+		return "", false, nil
+	}
+	repoURL, _, _ = strings.Cut(repoURL, "?")
+	return repoURL, true, nil
+}
+
+// packVersion returns the pack.version of the pack at dir.
+func packVersion(dir string) (string, error) {
+	metadata := &pack.Metadata{}
+	if err := hclsimple.DecodeFile(path.Join(dir, "metadata.hcl"), nil, metadata); err != nil {
+		return "", err
+	}
+	if metadata.Pack == nil {
+		return "", nil
+	}
+	return metadata.Pack.Version, nil
+}
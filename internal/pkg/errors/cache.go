@@ -9,6 +9,7 @@ var (
 	ErrInvalidRegistryRevision = newError("invalid revision")
 	ErrInvalidRegistrySource   = newError("invalid registry source")
 	ErrNoRegistriesAdded       = newError("no registries were added to the cache")
+	ErrPackModified            = newError("pack has been modified since it was added to the cache")
 	ErrPackNameRequired        = newError("pack name is required")
 	ErrPackNotFound            = newError("pack not found")
 	ErrRegistryNameRequired    = newError("registry name is required")
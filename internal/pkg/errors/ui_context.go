@@ -32,7 +32,12 @@ const (
 	UIContextPrefixRegistryName   = "Registry Name: "
 	UIContextPrefixRegistryPath   = "Registry Path: "
 	UIContextPrefixRegistryTarget = "Registry Target: "
+	UIContextPrefixRegistryIndex  = "Registry Index: "
 	UIContextPrefixOutputPath     = "Output Path: "
+	UIContextPrefixRevision       = "Revision: "
+	UIContextPrefixTestCase       = "Test Case: "
+	UIContextPrefixPlanFile       = "Plan File: "
+	UIContextPrefixOCIReference   = "OCI Reference: "
 )
 
 // UIErrorContext is used to store and manipulate error context strings used
@@ -10,6 +10,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+
+	"golang.org/x/mod/sumdb/dirhash"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/logging"
 )
@@ -212,3 +216,47 @@ type createOpts struct {
 	errOnExists bool
 	perms       fs.FileMode
 }
+
+// HashDir returns the hash of the regular files within dir, in the "h1:"
+// format of go.sum. Any .git directory is ignored, along with the files and
+// directories whose slash-separated paths relative to dir are listed in skip.
+// A dir which is a symlink is followed.
+func HashDir(dir string, skip ...string) (string, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		skipped := slices.Contains(skip, rel)
+
+		if d.IsDir() {
+			if d.Name() == ".git" || skipped {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || skipped {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(root, filepath.FromSlash(name)))
+	})
+}
@@ -69,3 +69,29 @@ func TestCopyDirSkipsGitDir(t *testing.T) {
 	_, err = os.Stat(path.Join(dstDir, ".git"))
 	must.True(t, os.IsNotExist(err))
 }
+
+func TestHashDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	must.NoError(t, os.WriteFile(path.Join(dir, "metadata.hcl"), []byte("app {}"), 0o644))
+	must.NoError(t, os.MkdirAll(path.Join(dir, "deps", "child"), 0o755))
+	must.NoError(t, os.WriteFile(path.Join(dir, "deps", "child", "metadata.hcl"), []byte("app {}"), 0o644))
+	must.NoError(t, os.WriteFile(path.Join(dir, "latest.log"), []byte("one"), 0o644))
+
+	hash, err := HashDir(dir, "deps", "latest.log")
+	must.NoError(t, err)
+	must.StrHasPrefix(t, "h1:", hash)
+
+	// Skipped files and directories are not part of the content.
+	must.NoError(t, os.WriteFile(path.Join(dir, "deps", "child", "metadata.hcl"), []byte("other {}"), 0o644))
+	must.NoError(t, os.WriteFile(path.Join(dir, "latest.log"), []byte("two"), 0o644))
+	again, err := HashDir(dir, "deps", "latest.log")
+	must.NoError(t, err)
+	must.Eq(t, hash, again)
+
+	// Without the skip list, they are.
+	all, err := HashDir(dir)
+	must.NoError(t, err)
+	must.NotEq(t, hash, all)
+}
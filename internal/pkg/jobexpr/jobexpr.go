@@ -0,0 +1,99 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jobexpr evaluates HCL expressions against parsed Nomad jobs, so pack
+// tooling can express checks such as "task web has 512 MB memory" as
+// conditions rather than Go code.
+package jobexpr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/nomad/api"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// Value converts the job into a cty.Value using its JSON encoding, so the
+// attributes of the value are the api.Job field names, for example
+// job.TaskGroups[0].Tasks[0].Resources.MemoryMB.
+func Value(job *api.Job) (cty.Value, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	ty, err := ctyjson.ImpliedType(data)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to determine job type: %w", err)
+	}
+
+	val, err := ctyjson.Unmarshal(data, ty)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return val, nil
+}
+
+// EvalContext returns the context used to evaluate expressions against the
+// job. Alongside the job itself as "job", the task groups and tasks are made
+// available as "groups" and "tasks" objects keyed by name, so they can be
+// looked up without relying on their position within the job. A task name
+// used in more than one group refers to the task of the last such group. The
+// functions are those available to variable validation conditions.
+func EvalContext(job *api.Job) (*hcl.EvalContext, error) {
+	jobVal, err := Value(job)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]cty.Value{}
+	tasks := map[string]cty.Value{}
+
+	for i, tg := range job.TaskGroups {
+		if tg == nil || tg.Name == nil {
+			continue
+		}
+		tgVal := jobVal.GetAttr("TaskGroups").Index(cty.NumberIntVal(int64(i)))
+		groups[*tg.Name] = tgVal
+
+		for j, task := range tg.Tasks {
+			if task == nil {
+				continue
+			}
+			tasks[task.Name] = tgVal.GetAttr("Tasks").Index(cty.NumberIntVal(int64(j)))
+		}
+	}
+
+	return &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"job":    jobVal,
+			"groups": cty.ObjectVal(groups),
+			"tasks":  cty.ObjectVal(tasks),
+		},
+		Functions: variables.ValidationFunctions(),
+	}, nil
+}
+
+// EvalCondition evaluates the expression within the context, and returns its
+// result, which must be a known boolean.
+func EvalCondition(expr hcl.Expression, ctx *hcl.EvalContext) (bool, hcl.Diagnostics) {
+	result, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return false, diags
+	}
+
+	if result.IsNull() || !result.IsKnown() || result.Type() != cty.Bool {
+		return false, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid condition result",
+			Detail:   "The condition must return a boolean.",
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+	return result.True(), diags
+}
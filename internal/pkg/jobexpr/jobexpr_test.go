@@ -0,0 +1,50 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jobexpr
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+)
+
+func TestEvalCondition(t *testing.T) {
+	job := api.NewBatchJob("example", "example", "global", 50)
+	memory := 512
+	job.AddTaskGroup(api.NewTaskGroup("app", 2).AddTask(&api.Task{
+		Name:      "web",
+		Driver:    "docker",
+		Resources: &api.Resources{MemoryMB: &memory},
+	}))
+
+	ctx, err := EvalContext(job)
+	must.NoError(t, err)
+
+	testCases := []struct {
+		expr     string
+		expected bool
+		err      bool
+	}{
+		{`tasks.web.Resources.MemoryMB == 512`, true, false},
+		{`job.TaskGroups[0].Tasks[0].Resources.MemoryMB == 256`, false, false},
+		{`groups.app.Count == 2 && job.Type == "batch"`, true, false},
+		{`contains([for t in groups.app.Tasks : t.Driver], "docker")`, true, false},
+		{`tasks.web.Driver`, false, true},
+		{`tasks.missing.Driver == "docker"`, false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tc.expr), "test.hcl", hcl.InitialPos)
+			must.False(t, diags.HasErrors())
+
+			ok, diags := EvalCondition(expr, ctx)
+			must.Eq(t, tc.err, diags.HasErrors())
+			must.Eq(t, tc.expected, ok)
+		})
+	}
+}
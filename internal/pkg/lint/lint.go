@@ -0,0 +1,345 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package lint checks a pack for problems which do not stop it from being
+// rendered, but which make it harder to use or maintain, such as variables
+// which are declared but never used. The templates are also rendered with
+// representative variable values and checked to be valid HCL.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/nomad/jobspec2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/hashicorp/nomad-pack/internal/config"
+	"github.com/hashicorp/nomad-pack/internal/pkg/renderer"
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser"
+	parserconfig "github.com/hashicorp/nomad-pack/internal/pkg/variable/parser/config"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// Severity is the severity of a Problem.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// The checks performed on a pack, which identify the kind of each Problem.
+const (
+	CheckMissingFile        = "missing-file"
+	CheckDeprecatedMetadata = "deprecated-metadata"
+	CheckMissingDescription = "missing-description"
+	CheckUnusedVariable     = "unused-variable"
+	CheckUndeclaredVariable = "undeclared-variable"
+	CheckTemplateSyntax     = "template-syntax"
+	CheckInvalidVariables   = "invalid-variables"
+	CheckRenderFailed       = "render-failed"
+	CheckInvalidRenderedJob = "invalid-rendered-job"
+)
+
+// Problem is a single problem found within a pack.
+type Problem struct {
+	Severity Severity
+	Check    string
+
+	// File is the path of the file containing the problem, relative to the
+	// root of the pack. Line is the line of the problem within the file, or
+	// zero if the problem is not specific to a line.
+	File string
+	Line int
+
+	Message string
+}
+
+// Location returns the file and line of the problem.
+func (p *Problem) Location() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return p.File
+}
+
+// Lint checks the pack, which should be loaded along with its dependencies,
+// and returns the problems found sorted by location. Only the files of the
+// pack itself are checked, rather than those of its dependencies.
+func Lint(p *pack.Pack) []*Problem {
+	var problems []*Problem
+
+	problems = append(problems, checkFiles(p)...)
+	problems = append(problems, checkMetadata(p)...)
+
+	parsedVars, varProblems := parseVariables(p)
+	problems = append(problems, varProblems...)
+
+	refs, refProblems := findReferences(p)
+	problems = append(problems, refProblems...)
+
+	if parsedVars != nil {
+		declared := parsedVars.GetVars()[p.VariablesPath()]
+		problems = append(problems, checkVariables(declared, refs)...)
+
+		// A template which cannot be parsed cannot be rendered either, so
+		// only render the pack if the templates are sound.
+		if len(refProblems) == 0 {
+			problems = append(problems, checkRender(p, parsedVars)...)
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		return a.Message < b.Message
+	})
+	return problems
+}
+
+// HasErrors returns whether any of the problems is an error.
+func HasErrors(problems []*Problem) bool {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func checkFiles(p *pack.Pack) []*Problem {
+	var problems []*Problem
+	for _, name := range []string{config.FileNameReadme, config.FileNameChangelog} {
+		if _, err := os.Stat(filepath.Join(p.Path, name)); err != nil {
+			problems = append(problems, &Problem{
+				Severity: SeverityWarning,
+				Check:    CheckMissingFile,
+				File:     name,
+				Message:  fmt.Sprintf("The pack has no %s file.", name),
+			})
+		}
+	}
+	return problems
+}
+
+func checkMetadata(p *pack.Pack) []*Problem {
+	var deprecated []string
+	if p.Metadata.App != nil && p.Metadata.App.Author != "" {
+		deprecated = append(deprecated, "app.author")
+	}
+	if p.Metadata.Pack != nil && p.Metadata.Pack.URL != "" {
+		deprecated = append(deprecated, "pack.url")
+	}
+
+	problems := make([]*Problem, 0, len(deprecated))
+	for _, field := range deprecated {
+		problems = append(problems, &Problem{
+			Severity: SeverityWarning,
+			Check:    CheckDeprecatedMetadata,
+			File:     config.FileNameMetadata,
+			Message:  fmt.Sprintf("The %s field is deprecated and should be removed.", field),
+		})
+	}
+	return problems
+}
+
+// parseVariables parses the variables of the pack and its dependencies. Any
+// variable without a value is given a representative one, so the pack can be
+// rendered without the user supplying values.
+func parseVariables(p *pack.Pack) (*parser.ParsedVariables, []*Problem) {
+	varParser, err := parser.NewParserV2(&parserconfig.ParserConfig{
+		Version:           parserconfig.V2,
+		ParentPack:        p,
+		RootVariableFiles: p.RootVariableFiles(),
+	})
+	if err != nil {
+		return nil, []*Problem{{
+			Severity: SeverityError,
+			Check:    CheckInvalidVariables,
+			File:     config.FileNameVariables,
+			Message:  err.Error(),
+		}}
+	}
+
+	parsedVars, diags := varParser.Parse()
+	if diags.HasErrors() {
+		var problems []*Problem
+		for _, diag := range diags.Errs() {
+			problem := &Problem{
+				Severity: SeverityError,
+				Check:    CheckInvalidVariables,
+				File:     config.FileNameVariables,
+				Message:  diag.Error(),
+			}
+			if d, ok := diag.(*hcl.Diagnostic); ok {
+				problem.Message = fmt.Sprintf("%s: %s", d.Summary, d.Detail)
+				if d.Subject != nil {
+					problem.Line = d.Subject.Start.Line
+				}
+			}
+			problems = append(problems, problem)
+		}
+		return nil, problems
+	}
+
+	for _, packVars := range parsedVars.GetVars() {
+		for _, v := range packVars {
+			if v.Value.IsNull() {
+				v.Value = representativeValue(v.Type)
+			}
+		}
+	}
+	return parsedVars, nil
+}
+
+// representativeValue returns a value of the type to render a variable with
+// when it has no default.
+func representativeValue(ty cty.Type) cty.Value {
+	switch {
+	case ty == cty.Number:
+		return cty.NumberIntVal(1)
+	case ty == cty.Bool:
+		return cty.False
+	case ty.IsListType():
+		return cty.ListValEmpty(ty.ElementType())
+	case ty.IsSetType():
+		return cty.SetValEmpty(ty.ElementType())
+	case ty.IsMapType():
+		return cty.MapValEmpty(ty.ElementType())
+	case ty.IsObjectType():
+		attrs := make(map[string]cty.Value, len(ty.AttributeTypes()))
+		for name, attrTy := range ty.AttributeTypes() {
+			attrs[name] = representativeValue(attrTy)
+		}
+		return cty.ObjectVal(attrs)
+	case ty.IsTupleType():
+		elems := make([]cty.Value, len(ty.TupleElementTypes()))
+		for i, elemTy := range ty.TupleElementTypes() {
+			elems[i] = representativeValue(elemTy)
+		}
+		return cty.TupleVal(elems)
+	default:
+		return cty.StringVal("example")
+	}
+}
+
+func checkVariables(declared map[variables.ID]*variables.Variable, refs *references) []*Problem {
+	var problems []*Problem
+
+	for name, v := range declared {
+		line := v.DeclRange.Start.Line
+
+		if v.Description == "" {
+			problems = append(problems, &Problem{
+				Severity: SeverityWarning,
+				Check:    CheckMissingDescription,
+				File:     config.FileNameVariables,
+				Line:     line,
+				Message:  fmt.Sprintf("Variable %q has no description.", name),
+			})
+		}
+
+		// When a template uses the vars function, or looks up a variable by
+		// a name which is not a literal, any variable may be in use.
+		if !refs.dynamic && len(refs.byName[name.String()]) == 0 {
+			problems = append(problems, &Problem{
+				Severity: SeverityWarning,
+				Check:    CheckUnusedVariable,
+				File:     config.FileNameVariables,
+				Line:     line,
+				Message:  fmt.Sprintf("Variable %q is not used by any template.", name),
+			})
+		}
+	}
+
+	for name, locations := range refs.byName {
+		if _, ok := declared[variables.ID(name)]; ok {
+			continue
+		}
+		for _, loc := range locations {
+			problems = append(problems, &Problem{
+				Severity: SeverityError,
+				Check:    CheckUndeclaredVariable,
+				File:     loc.file,
+				Line:     loc.line,
+				Message:  fmt.Sprintf("Variable %q is not declared in %s.", name, config.FileNameVariables),
+			})
+		}
+	}
+	return problems
+}
+
+// checkRender renders the templates of the pack and checks that each job
+// template renders to a valid job specification. The jobs are parsed locally,
+// in the same way as the runner does without a Nomad client.
+func checkRender(p *pack.Pack, parsedVars *parser.ParsedVariables) []*Problem {
+	r := &renderer.Renderer{PackPath: p.Path}
+
+	rendered, err := r.Render(p, parsedVars)
+	if err != nil {
+		return []*Problem{{
+			Severity: SeverityError,
+			Check:    CheckRenderFailed,
+			File:     config.FolderNameTemplates,
+			Message:  err.Error(),
+		}}
+	}
+
+	// The renders of dependencies are keyed beneath the name of the parent
+	// pack too, so select the pack's own templates by their prefix.
+	prefix := path.Join(p.Name(), config.FolderNameTemplates) + "/"
+
+	var problems []*Problem
+	for name, content := range rendered.ParentRenders() {
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".nomad.tpl") {
+			continue
+		}
+
+		file := strings.TrimPrefix(name, p.Name()+"/")
+
+		_, err := jobspec2.ParseWithConfig(&jobspec2.ParseConfig{
+			Path:    file,
+			Body:    []byte(content),
+			AllowFS: false,
+		})
+		if err == nil {
+			continue
+		}
+
+		var diags hcl.Diagnostics
+		if !errors.As(err, &diags) {
+			problems = append(problems, &Problem{
+				Severity: SeverityError,
+				Check:    CheckInvalidRenderedJob,
+				File:     file,
+				Message:  err.Error(),
+			})
+			continue
+		}
+		for _, diag := range diags.Errs() {
+			problems = append(problems, &Problem{
+				Severity: SeverityError,
+				Check:    CheckInvalidRenderedJob,
+				File:     file,
+				Message:  fmt.Sprintf("%s (in the rendered template)", diag),
+			})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,213 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
+)
+
+func writePack(t *testing.T, files map[string]string) string {
+	t.Helper()
+	packPath := filepath.Join(t.TempDir(), "example")
+	for name, content := range files {
+		path := filepath.Join(packPath, name)
+		must.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		must.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return packPath
+}
+
+func lintPack(t *testing.T, files map[string]string) []*Problem {
+	t.Helper()
+	p, err := loader.Load(writePack(t, files))
+	must.NoError(t, err)
+	return Lint(p)
+}
+
+func checks(problems []*Problem) []string {
+	out := make([]string, 0, len(problems))
+	for _, p := range problems {
+		out = append(out, p.Check+" "+p.Location())
+	}
+	return out
+}
+
+const testMetadata = `
+app {
+  url = "https://example.com"
+}
+
+pack {
+  name        = "example"
+  description = "An example pack."
+  version     = "0.1.0"
+}
+`
+
+func TestLint_Clean(t *testing.T) {
+	problems := lintPack(t, map[string]string{
+		"metadata.hcl": testMetadata,
+		"README.md":    "# example\n",
+		"CHANGELOG.md": "# 0.1.0\n",
+		"variables.hcl": `
+variable "image" {
+  description = "The image to run."
+  type        = string
+}
+
+variable "count" {
+  description = "The number of instances."
+  type        = number
+  default     = 1
+}
+`,
+		"templates/_helpers.tpl": `[[ define "image" ]][[ var "image" . | quote ]][[ end ]]`,
+		"templates/app.nomad.tpl": `
+job "app" {
+  group "app" {
+    count = [[ var "count" . ]]
+    task "app" {
+      driver = "docker"
+      config {
+        image = [[ template "image" . ]]
+      }
+    }
+  }
+}
+`,
+	})
+	must.Eq(t, []string{}, checks(problems))
+	must.False(t, HasErrors(problems))
+}
+
+func TestLint_Problems(t *testing.T) {
+	problems := lintPack(t, map[string]string{
+		"metadata.hcl": `
+app {
+  url    = "https://example.com"
+  author = "Example"
+}
+
+pack {
+  name    = "example"
+  version = "0.1.0"
+}
+`,
+		"variables.hcl": `
+variable "image" {
+  type = string
+}
+
+variable "unused" {
+  description = "Not used by any template."
+  default     = "x"
+}
+`,
+		"templates/app.nomad.tpl": `
+job "app" {
+  [[- range $i, $v := list 1 ]]
+  datacenters = [[ var "datacenters" $ | toJson ]]
+  [[- end ]]
+  group "app" {
+    task "app" {
+      driver = "docker"
+      config {
+        image = [[ var "image" . ]]
+      }
+    }
+  }
+}
+`,
+	})
+
+	must.Eq(t, []string{
+		"missing-file CHANGELOG.md",
+		"missing-file README.md",
+		"deprecated-metadata metadata.hcl",
+		"invalid-rendered-job templates/app.nomad.tpl",
+		"undeclared-variable templates/app.nomad.tpl:4",
+		"missing-description variables.hcl:2",
+		"unused-variable variables.hcl:6",
+	}, checks(problems))
+	must.True(t, HasErrors(problems))
+
+	// The undeclared variable renders as an empty string, which is not a
+	// valid list of datacenters.
+	must.StrContains(t, problems[3].Message, "list of string required")
+}
+
+func TestLint_TemplateSyntax(t *testing.T) {
+	problems := lintPack(t, map[string]string{
+		"metadata.hcl":            testMetadata,
+		"README.md":               "# example\n",
+		"CHANGELOG.md":            "# 0.1.0\n",
+		"variables.hcl":           "",
+		"templates/app.nomad.tpl": `job "app" { [[ if ]] }`,
+	})
+	must.Eq(t, []string{"template-syntax templates/app.nomad.tpl"}, checks(problems))
+}
+
+func TestLint_DynamicReferences(t *testing.T) {
+	problems := lintPack(t, map[string]string{
+		"metadata.hcl": testMetadata,
+		"README.md":    "# example\n",
+		"CHANGELOG.md": "# 0.1.0\n",
+		"variables.hcl": `
+variable "env" {
+  description = "Environment variables of the task."
+  type        = map(string)
+  default     = {}
+}
+`,
+		"templates/app.nomad.tpl": `
+job "app" {
+  meta {
+    vars = "[[ len (vars .) ]]"
+  }
+}
+`,
+	})
+	must.Eq(t, []string{}, checks(problems))
+}
+
+func TestLint_ReboundDot(t *testing.T) {
+	problems := lintPack(t, map[string]string{
+		"metadata.hcl": testMetadata,
+		"README.md":    "# example\n",
+		"CHANGELOG.md": "# 0.1.0\n",
+		"variables.hcl": `
+variable "image" {
+  description = "The image to run."
+  type        = string
+}
+`,
+		"templates/app.nomad.tpl": `
+job "app" {
+  [[- range list ]]
+  datacenters = [[ var "datacenters" . | toJson ]]
+  [[- end ]]
+  group "app" {
+    task "app" {
+      driver = "docker"
+      config {
+        [[- with $ ]]
+        image = [[ var "image" . | quote ]]
+        [[- end ]]
+      }
+    }
+  }
+}
+`,
+	})
+
+	// Within range, the dot is not the pack, so the lookup is not of one of
+	// its variables. Within with $, it is.
+	must.Eq(t, []string{}, checks(problems))
+}
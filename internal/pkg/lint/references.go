@@ -0,0 +1,186 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"strings"
+	"text/template/parse"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+const (
+	leftTemplateDelim  = "[["
+	rightTemplateDelim = "]]"
+)
+
+// location is a position within a file of the pack.
+type location struct {
+	file string
+	line int
+}
+
+// references are the variables of the pack referred to by its templates.
+type references struct {
+
+	// byName maps the top-level name of each variable looked up using the
+	// var or must_var functions to the locations of the lookups.
+	byName map[string][]location
+
+	// dynamic is true when any template could refer to any variable, as it
+	// uses the vars function or looks up a variable with a computed name.
+	dynamic bool
+}
+
+// findReferences parses the templates, helpers, auxiliary files and output
+// template of the pack to find the variables they refer to. Only lookups
+// within the pack's own context are found, so lookups of a dependency's
+// variables such as `var "name" .dep` are ignored.
+func findReferences(p *pack.Pack) (*references, []*Problem) {
+	refs := &references{byName: map[string][]location{}}
+
+	files := make([]*pack.File, 0, len(p.TemplateFiles)+len(p.AuxiliaryFiles)+1)
+	files = append(files, p.TemplateFiles...)
+	files = append(files, p.AuxiliaryFiles...)
+	if p.OutputTemplateFile != nil {
+		files = append(files, p.OutputTemplateFile)
+	}
+
+	var problems []*Problem
+	for _, f := range files {
+		if err := refs.addFile(f); err != nil {
+			problems = append(problems, &Problem{
+				Severity: SeverityError,
+				Check:    CheckTemplateSyntax,
+				File:     f.Name,
+				Message:  err.Error(),
+			})
+		}
+	}
+	return refs, problems
+}
+
+func (r *references) addFile(f *pack.File) error {
+	content := string(f.Content)
+
+	// The functions are those of the renderer, so rather than keeping a copy
+	// of their names, skip checking they exist.
+	tree := parse.New(f.Name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(content, leftTemplateDelim, rightTemplateDelim, trees); err != nil {
+		return err
+	}
+
+	for _, t := range trees {
+		walk(t.Root, true, func(cmd *parse.CommandNode, dotIsPack bool) {
+			r.addCommand(cmd, dotIsPack, f.Name, content)
+		})
+	}
+	return nil
+}
+
+// addCommand records the variable looked up by the command, if it calls one
+// of the variable template functions. dotIsPack is whether the dot refers to
+// the context of the pack where the command is run.
+func (r *references) addCommand(cmd *parse.CommandNode, dotIsPack bool, file, content string) {
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return
+	}
+
+	switch ident.Ident {
+	case "vars":
+		if len(cmd.Args) < 2 || isCurrentPack(cmd.Args[1], dotIsPack) {
+			r.dynamic = true
+		}
+
+	case "var", "must_var":
+		// The pack context may be the final argument, or piped in.
+		if len(cmd.Args) < 2 || len(cmd.Args) > 2 && !isCurrentPack(cmd.Args[2], dotIsPack) {
+			return
+		}
+
+		key, ok := cmd.Args[1].(*parse.StringNode)
+		if !ok {
+			r.dynamic = true
+			return
+		}
+
+		name, _, _ := strings.Cut(key.Text, ".")
+		r.byName[name] = append(r.byName[name], location{
+			file: file,
+			line: 1 + strings.Count(content[:int(cmd.Position())], "\n"),
+		})
+	}
+}
+
+// isCurrentPack returns whether the node refers to the context of the pack
+// being rendered, which is $ anywhere within a template, and the dot unless it
+// has been rebound by range or with.
+func isCurrentPack(node parse.Node, dotIsPack bool) bool {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dotIsPack
+	case *parse.VariableNode:
+		return len(n.Ident) == 1 && n.Ident[0] == "$"
+	}
+	return false
+}
+
+// walk calls fn for every command within the node, along with whether the
+// dot refers to the context of the pack where the command is run. Within the
+// body of a range or with, the dot is rebound to the value of its pipeline,
+// so it only still refers to the pack for a pipeline of the pack itself, such
+// as {{ with . }}.
+func walk(node parse.Node, dotIsPack bool, fn func(*parse.CommandNode, bool)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walk(child, dotIsPack, fn)
+		}
+	case *parse.ActionNode:
+		walk(n.Pipe, dotIsPack, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walk(cmd, dotIsPack, fn)
+		}
+	case *parse.CommandNode:
+		fn(n, dotIsPack)
+		for _, arg := range n.Args {
+			walk(arg, dotIsPack, fn)
+		}
+	case *parse.ChainNode:
+		walk(n.Node, dotIsPack, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, dotIsPack, dotIsPack, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, dotIsPack, false, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, dotIsPack, isPackPipe(n.Pipe, dotIsPack), fn)
+	case *parse.TemplateNode:
+		walk(n.Pipe, dotIsPack, fn)
+	}
+}
+
+// walkBranch walks the pipeline and else branch of n with the dot of the
+// enclosing node, and its body with bodyDotIsPack.
+func walkBranch(n *parse.BranchNode, dotIsPack, bodyDotIsPack bool, fn func(*parse.CommandNode, bool)) {
+	walk(n.Pipe, dotIsPack, fn)
+	walk(n.List, bodyDotIsPack, fn)
+	walk(n.ElseList, dotIsPack, fn)
+}
+
+// isPackPipe returns whether the value of the pipeline is the context of the
+// pack, as in {{ with $ }}.
+func isPackPipe(pipe *parse.PipeNode, dotIsPack bool) bool {
+	return pipe != nil && len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 1 &&
+		isCurrentPack(pipe.Cmds[0].Args[0], dotIsPack)
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/nomad-pack/internal/pkg/deps"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
 	"github.com/hashicorp/nomad-pack/internal/pkg/renderer"
@@ -29,6 +31,7 @@ type Config struct {
 	UseParserV1           bool
 	AllowUnsetVars        bool
 	ExternalSourceConfigs []source.SourceConfig // Lazily-built configs for external sources (Consul, Vault, Nomad)
+	ExternalSourceTimeout time.Duration         // Maximum time each external source may take; zero for the default
 }
 
 // PackManager is responsible for loading, parsing, and rendering a Pack and
@@ -76,6 +79,7 @@ func (pm *PackManager) ProcessVariableFiles() (*parser.ParsedVariables, []*error
 		FileOverrides:         pm.cfg.VariableFiles,
 		FlagOverrides:         pm.cfg.VariableCLIArgs,
 		ExternalSourceConfigs: pm.cfg.ExternalSourceConfigs,
+		ExternalSourceTimeout: pm.cfg.ExternalSourceTimeout,
 	}
 
 	if pm.cfg.UseParserV1 {
@@ -168,6 +172,12 @@ func (pm *PackManager) ProcessOutputTemplate() (string, error) {
 	return pm.renderer.RenderOutput()
 }
 
+// LoadPack loads and validates the pack along with its dependencies, without
+// parsing its variables or rendering its templates.
+func (pm *PackManager) LoadPack() (*pack.Pack, error) {
+	return pm.loadAndValidatePacks()
+}
+
 // loadAndValidatePacks triggers the initial parent load and then starts the
 // dependent pack loader. The returned pack will therefore be fully populated.
 func (pm *PackManager) loadAndValidatePacks() (*pack.Pack, error) {
@@ -181,6 +191,12 @@ func (pm *PackManager) loadAndValidatePacks() (*pack.Pack, error) {
 		return nil, fmt.Errorf("failed to validate pack: %v", err)
 	}
 
+	// Check the vendored dependencies have not changed since they were
+	// locked.
+	if err := deps.Verify(pm.cfg.Path); err != nil {
+		return nil, fmt.Errorf("failed to verify pack dependencies: %v", err)
+	}
+
 	// Using the input path to the parent pack, define the path where
 	// dependencies are stored.
 	depsPath := path.Join(pm.cfg.Path, "deps")
@@ -0,0 +1,51 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package oci
+
+import (
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+// annotationPrefix is the prefix of the annotations which carry the fields of
+// the metadata.hcl of a pack.
+const annotationPrefix = "com.hashicorp.nomad.pack."
+
+// PackAnnotations returns the annotations of the manifest of a pack with the
+// metadata. The fields of metadata.hcl are set under their block and
+// attribute names, such as "com.hashicorp.nomad.pack.pack.version", and the
+// pre-defined OCI annotations are also set where there is an equivalent.
+func PackAnnotations(metadata *pack.Metadata, created time.Time) map[string]string {
+	a := map[string]string{
+		ocispec.AnnotationCreated: created.UTC().Format(time.RFC3339),
+	}
+	set := func(key, value string) {
+		if value != "" {
+			a[key] = value
+		}
+	}
+
+	if metadata.App != nil {
+		set(annotationPrefix+"app.url", metadata.App.URL)
+		set(ocispec.AnnotationURL, metadata.App.URL)
+	}
+	if metadata.Pack != nil {
+		set(annotationPrefix+"pack.name", metadata.Pack.Name)
+		set(annotationPrefix+"pack.description", metadata.Pack.Description)
+		set(annotationPrefix+"pack.version", metadata.Pack.Version)
+		set(ocispec.AnnotationTitle, metadata.Pack.Name)
+		set(ocispec.AnnotationDescription, metadata.Pack.Description)
+		set(ocispec.AnnotationVersion, metadata.Pack.Version)
+	}
+	if metadata.Integration != nil {
+		set(annotationPrefix+"integration.identifier", metadata.Integration.Identifier)
+		set(annotationPrefix+"integration.name", metadata.Integration.Name)
+		set(annotationPrefix+"integration.flags", strings.Join(metadata.Integration.Flags, ","))
+	}
+	return a
+}
@@ -0,0 +1,495 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// PackArtifactType is the artifact type of the manifest of a pack.
+	PackArtifactType = "application/vnd.hashicorp.nomad.pack.v1"
+
+	// PackLayerMediaType is the media type of the layer holding the gzipped
+	// tar of the pack directory.
+	PackLayerMediaType = "application/vnd.hashicorp.nomad.pack.layer.v1.tar+gzip"
+
+	// SignatureAnnotation is the annotation of the layer of a pack holding
+	// the signature of the digest of the layer.
+	SignatureAnnotation = annotationPrefix + "signature"
+)
+
+// Client is a client of the OCI distribution API, implementing the parts of
+// it needed to push and pull packs. Registries which require authentication
+// are supported through both basic auth and bearer token challenges.
+type Client struct {
+	// HTTPClient is used for all requests. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Username and Password are the credentials presented to the registry,
+	// or its token service, when it challenges a request.
+	Username string
+	Password string
+
+	// Sign, if set, is called with the digest of the layer of each pushed
+	// pack, and the signature it returns is set as the SignatureAnnotation
+	// of the layer.
+	Sign func(digest []byte) (string, error)
+
+	// Verify, if set, is called with the digest of the layer of each pulled
+	// pack and its SignatureAnnotation, which may be empty, before the layer
+	// is downloaded. The pull fails if it returns an error.
+	Verify func(digest []byte, signature string) error
+
+	// authMu guards auth, the Authorization header to send for each scope,
+	// as learnt from challenges.
+	authMu sync.Mutex
+	auth   map[string]string
+}
+
+// Pull downloads the pack at ref and extracts it into dir, returning
+// the digest of its manifest.
+func (c *Client) Pull(ctx context.Context, ref *Reference, dir string) (string, error) {
+	manifestDigest, manifest, err := c.manifest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if manifest.ArtifactType != PackArtifactType {
+		return "", fmt.Errorf("%s is not a pack: artifact type is %q, expected %q", ref, manifest.ArtifactType, PackArtifactType)
+	}
+
+	var layer *ocispec.Descriptor
+	for i, l := range manifest.Layers {
+		if l.MediaType == PackLayerMediaType {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return "", fmt.Errorf("%s has no layer of type %q", ref, PackLayerMediaType)
+	}
+	if c.Verify != nil {
+		if err := c.Verify([]byte(layer.Digest), layer.Annotations[SignatureAnnotation]); err != nil {
+			return "", fmt.Errorf("failed to verify %s: %w", ref, err)
+		}
+	}
+
+	data, err := c.blob(ctx, ref, *layer)
+	if err != nil {
+		return "", err
+	}
+	if err := extractArchive(data, dir); err != nil {
+		return "", fmt.Errorf("failed to extract %s: %w", ref, err)
+	}
+	return manifestDigest.String(), nil
+}
+
+// Push uploads the pack directory dir to ref, with the annotations set on its
+// manifest, and returns the digest of the manifest.
+func (c *Client) Push(ctx context.Context, ref *Reference, dir string, annotations map[string]string) (string, error) {
+	data, err := archiveDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to archive pack: %w", err)
+	}
+
+	layer := ocispec.Descriptor{
+		MediaType: PackLayerMediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	layer.Annotations = make(map[string]string)
+	if name, ok := annotations[ocispec.AnnotationTitle]; ok {
+		layer.Annotations[ocispec.AnnotationTitle] = name + ".tar.gz"
+	}
+	if c.Sign != nil {
+		signature, err := c.Sign([]byte(layer.Digest))
+		if err != nil {
+			return "", fmt.Errorf("failed to sign pack: %w", err)
+		}
+		layer.Annotations[SignatureAnnotation] = signature
+	}
+	if len(layer.Annotations) == 0 {
+		layer.Annotations = nil
+	}
+
+	if err := c.uploadBlob(ctx, ref, ocispec.DescriptorEmptyJSON, ocispec.DescriptorEmptyJSON.Data); err != nil {
+		return "", err
+	}
+	if err := c.uploadBlob(ctx, ref, layer, data); err != nil {
+		return "", err
+	}
+
+	manifest, err := json.Marshal(&ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: PackArtifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{layer},
+		Annotations:  annotations,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, ref, http.MethodPut, "manifests/"+ref.Tag, manifest,
+		http.Header{"Content-Type": {ocispec.MediaTypeImageManifest}}, "pull,push")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", responseError(resp, "push manifest to "+ref.String())
+	}
+	return digest.FromBytes(manifest).String(), nil
+}
+
+// Repositories returns the names of the repositories of the registry of ref
+// which are directly within its repository, such as "packs/hello_world" for
+// "oci://registry.example.com/packs". It requires the registry to serve the
+// catalog API.
+func (c *Client) Repositories(ctx context.Context, ref *Reference) ([]string, error) {
+	prefix := ref.Repository + "/"
+	catalogRef := &Reference{Host: ref.Host}
+
+	var repos []string
+	next := "_catalog?n=1000"
+	for next != "" {
+		resp, err := c.do(ctx, catalogRef, http.MethodGet, next, nil, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := responseError(resp, "list repositories of "+ref.Host)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var catalog struct {
+			Repositories []string `json:"repositories"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&catalog)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode catalog of %s: %w", ref.Host, err)
+		}
+
+		for _, repo := range catalog.Repositories {
+			if name, ok := strings.CutPrefix(repo, prefix); ok && name != "" && !strings.Contains(name, "/") {
+				repos = append(repos, repo)
+			}
+		}
+		next = nextLink(resp.Header.Get("Link"))
+	}
+	return repos, nil
+}
+
+// manifest fetches and decodes the manifest at ref, checking it against the
+// digest reported by the registry.
+func (c *Client) manifest(ctx context.Context, ref *Reference) (digest.Digest, *ocispec.Manifest, error) {
+	resp, err := c.do(ctx, ref, http.MethodGet, "manifests/"+ref.Tag, nil,
+		http.Header{"Accept": {ocispec.MediaTypeImageManifest}}, "pull")
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, responseError(resp, "fetch manifest of "+ref.String())
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	d := digest.FromBytes(body)
+	if reported := resp.Header.Get("Docker-Content-Digest"); reported != "" && reported != d.String() {
+		return "", nil, fmt.Errorf("manifest of %s does not match its digest %s", ref, reported)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return "", nil, fmt.Errorf("failed to decode manifest of %s: %w", ref, err)
+	}
+	return d, &manifest, nil
+}
+
+// blob fetches the blob described by desc from the repository of ref, and
+// verifies its digest.
+func (c *Client) blob(ctx context.Context, ref *Reference, desc ocispec.Descriptor) ([]byte, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid digest of blob in %s: %w", ref, err)
+	}
+
+	resp, err := c.do(ctx, ref, http.MethodGet, "blobs/"+desc.Digest.String(), nil, nil, "pull")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "fetch blob "+desc.Digest.String())
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, desc.Size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != desc.Size || digest.FromBytes(data) != desc.Digest {
+		return nil, fmt.Errorf("blob %s of %s does not match its digest", desc.Digest, ref)
+	}
+	return data, nil
+}
+
+// uploadBlob uploads a blob to the repository of ref, unless the repository
+// already has it.
+func (c *Client) uploadBlob(ctx context.Context, ref *Reference, desc ocispec.Descriptor, data []byte) error {
+	resp, err := c.do(ctx, ref, http.MethodHead, "blobs/"+desc.Digest.String(), nil, nil, "pull,push")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, ref, http.MethodPost, "blobs/uploads/", nil, nil, "pull,push")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return responseError(resp, "start upload to "+ref.String())
+	}
+
+	// The location of the upload may be relative to the registry, and may
+	// already have a query.
+	location, err := url.Parse(ref.baseURL())
+	if err == nil {
+		location, err = location.Parse(resp.Header.Get("Location"))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid upload location for %s: %w", ref, err)
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest.String())
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, ref, http.MethodPut, location.String(), data,
+		http.Header{"Content-Type": {"application/octet-stream"}}, "pull,push")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return responseError(resp, "upload blob "+desc.Digest.String())
+	}
+	return nil
+}
+
+// do sends a request to the registry of ref. A relative path is resolved
+// against the repository of ref, or the API root for a reference without a
+// repository. If the registry challenges the request, it is authorized for
+// the actions in the repository, and sent again. An absolute URL on another
+// host, such as an upload location the registry hands off to storage, is
+// sent without the credentials of the registry.
+func (c *Client) do(ctx context.Context, ref *Reference, method, path string, body []byte, header http.Header, actions string) (*http.Response, error) {
+	reqURL := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		reqURL = ref.baseURL()
+		if ref.Repository != "" {
+			reqURL += ref.Repository + "/"
+		}
+		reqURL += path
+	} else if u, err := url.Parse(reqURL); err != nil || u.Host != ref.Host {
+		return c.send(ctx, method, reqURL, body, header, "")
+	}
+
+	scope := "registry:catalog:*"
+	if ref.Repository != "" {
+		scope = "repository:" + ref.Repository + ":" + actions
+	}
+
+	c.authMu.Lock()
+	auth := c.auth[scope]
+	c.authMu.Unlock()
+
+	resp, err := c.send(ctx, method, reqURL, body, header, auth)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if auth, err = c.authorize(ctx, challenge, scope); err != nil {
+		return nil, fmt.Errorf("failed to authenticate with %s: %w", ref.Host, err)
+	}
+
+	c.authMu.Lock()
+	if c.auth == nil {
+		c.auth = make(map[string]string)
+	}
+	c.auth[scope] = auth
+	c.authMu.Unlock()
+
+	return c.send(ctx, method, reqURL, body, header, auth)
+}
+
+func (c *Client) send(ctx context.Context, method, reqURL string, body []byte, header http.Header, auth string) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(req)
+}
+
+// authorize returns the Authorization header which answers a WWW-Authenticate
+// challenge, fetching a token from the token service of a bearer challenge.
+func (c *Client) authorize(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.Username == "" {
+			return "", errors.New("registry requires credentials")
+		}
+		return basic, nil
+
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || realm.Host == "" {
+			return "", fmt.Errorf("invalid token realm %q", params["realm"])
+		}
+		query := realm.Query()
+		if service := params["service"]; service != "" {
+			query.Set("service", service)
+		}
+		query.Set("scope", scope)
+		realm.RawQuery = query.Encode()
+
+		auth := ""
+		if c.Username != "" {
+			auth = basic
+		}
+		resp, err := c.send(ctx, http.MethodGet, realm.String(), nil, nil, auth)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", responseError(resp, "fetch token")
+		}
+
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("failed to decode token: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		if token.Token == "" {
+			return "", errors.New("token service returned no token")
+		}
+		return "Bearer " + token.Token, nil
+
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and
+// parameters. Parameter values may be quoted, and quoted values may contain
+// commas, such as the scopes of a bearer challenge.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(value)
+
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				params[key] = rest[1:]
+				break
+			}
+			params[key] = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			params[key] = strings.TrimSpace(value)
+		}
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+		rest = strings.TrimSpace(rest)
+	}
+	return scheme, params
+}
+
+// nextLink returns the target of the "next" relation of a Link header, as
+// used by the catalog API to paginate.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, _ := strings.Cut(strings.TrimSpace(link), ";")
+		if strings.Contains(params, `rel="next"`) {
+			return strings.TrimPrefix(strings.Trim(strings.TrimSpace(target), "<>"), "/v2/")
+		}
+	}
+	return ""
+}
+
+// responseError returns an error for an unexpected response, including the
+// errors reported by the registry, if any.
+func responseError(resp *http.Response, action string) error {
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && len(body.Errors) > 0 {
+		var msgs []string
+		for _, e := range body.Errors {
+			msgs = append(msgs, e.Code+": "+e.Message)
+		}
+		return fmt.Errorf("failed to %s: %s: %s", action, resp.Status, strings.Join(msgs, ", "))
+	}
+	return fmt.Errorf("failed to %s: %s", action, resp.Status)
+}
@@ -0,0 +1,217 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/oci/ocitest"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+func TestParseReference(t *testing.T) {
+	testCases := []struct {
+		source string
+		expect *Reference
+		err    string
+	}{
+		{
+			source: "oci://registry.example.com/packs",
+			expect: &Reference{Host: "registry.example.com", Repository: "packs"},
+		},
+		{
+			source: "oci://localhost:5000/team/packs/hello_world:v1.0.0",
+			expect: &Reference{Host: "localhost:5000", Repository: "team/packs/hello_world", Tag: "v1.0.0"},
+		},
+		{source: "registry.example.com/packs", err: "must begin with oci://"},
+		{source: "oci:///packs", err: "has no registry host"},
+		{source: "oci://registry.example.com", err: "no repository"},
+		{source: "oci://registry.example.com/Packs", err: `repository "Packs" must be lowercase`},
+		{source: "oci://registry.example.com/packs:feature/x", err: `tag "feature/x" must be alphanumeric`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			ref, err := ParseReference(tc.source)
+			if tc.err != "" {
+				must.ErrorContains(t, err, tc.err)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expect, ref)
+			must.Eq(t, tc.source, ref.String())
+		})
+	}
+}
+
+func TestReference_baseURL(t *testing.T) {
+	must.Eq(t, "http://127.0.0.1:5000/v2/", (&Reference{Host: "127.0.0.1:5000"}).baseURL())
+	must.Eq(t, "http://localhost/v2/", (&Reference{Host: "localhost"}).baseURL())
+	must.Eq(t, "https://registry.example.com/v2/", (&Reference{Host: "registry.example.com"}).baseURL())
+}
+
+func TestClient_PushPull(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	registry := ocitest.NewRegistry(t)
+	registry.Username, registry.Password = "ci", "secret"
+
+	packDir := t.TempDir()
+	must.NoError(t, os.MkdirAll(filepath.Join(packDir, "templates"), 0o755))
+	must.NoError(t, os.MkdirAll(filepath.Join(packDir, ".git"), 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(packDir, "metadata.hcl"), []byte("pack {}\n"), 0o644))
+	must.NoError(t, os.WriteFile(filepath.Join(packDir, "templates", "app.nomad.tpl"), []byte("job {}\n"), 0o644))
+	must.NoError(t, os.WriteFile(filepath.Join(packDir, ".git", "HEAD"), []byte("ref\n"), 0o644))
+
+	namespace, err := ParseReference("oci://" + registry.Host() + "/packs")
+	must.NoError(t, err)
+	ref, err := namespace.Pack("hello_world", "")
+	must.NoError(t, err)
+	must.Eq(t, "packs/hello_world", ref.Repository)
+	must.Eq(t, DefaultTag, ref.Tag)
+
+	// Without credentials, the token service rejects the client.
+	_, err = (&Client{}).Push(ctx, ref, packDir, nil)
+	must.ErrorContains(t, err, "failed to authenticate")
+
+	client := &Client{Username: "ci", Password: "secret"}
+	annotations := PackAnnotations(&pack.Metadata{
+		App:  &pack.MetadataApp{URL: "https://example.com"},
+		Pack: &pack.MetadataPack{Name: "hello_world", Version: "1.0.0"},
+	}, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	pushed, err := client.Push(ctx, ref, packDir, annotations)
+	must.NoError(t, err)
+
+	var manifest ocispec.Manifest
+	must.NoError(t, json.Unmarshal(registry.Manifest("packs/hello_world", "latest"), &manifest))
+	must.Eq(t, PackArtifactType, manifest.ArtifactType)
+	must.Eq(t, "1.0.0", manifest.Annotations["com.hashicorp.nomad.pack.pack.version"])
+	must.Eq(t, "hello_world", manifest.Annotations[ocispec.AnnotationTitle])
+	must.Eq(t, "https://example.com", manifest.Annotations[ocispec.AnnotationURL])
+	must.Eq(t, "2026-01-02T03:04:05Z", manifest.Annotations[ocispec.AnnotationCreated])
+
+	// Pushing the same content again produces the same layer.
+	again, err := client.Push(ctx, ref, packDir, annotations)
+	must.NoError(t, err)
+	must.Eq(t, pushed, again)
+
+	repos, err := client.Repositories(ctx, namespace)
+	must.NoError(t, err)
+	must.Eq(t, []string{"packs/hello_world"}, repos)
+
+	pullDir := filepath.Join(t.TempDir(), "hello_world")
+	pulled, err := client.Pull(ctx, ref, pullDir)
+	must.NoError(t, err)
+	must.Eq(t, pushed, pulled)
+	must.FileContains(t, filepath.Join(pullDir, "templates", "app.nomad.tpl"), "job {}")
+	must.FileNotExists(t, filepath.Join(pullDir, ".git", "HEAD"))
+
+	// Content which does not match its digest is rejected.
+	layer := manifest.Layers[0]
+	registry.SetBlob(layer.Digest, []byte("tampered"))
+	_, err = client.Pull(ctx, ref, filepath.Join(t.TempDir(), "hello_world"))
+	must.ErrorContains(t, err, "does not match its digest")
+
+	missing, err := namespace.Pack("missing", "v1")
+	must.NoError(t, err)
+	_, err = client.Pull(ctx, missing, t.TempDir())
+	must.ErrorContains(t, err, "MANIFEST_UNKNOWN")
+}
+
+func TestClient_do_Location(t *testing.T) {
+	var gotAuth []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+	})
+	registry := httptest.NewServer(handler)
+	t.Cleanup(registry.Close)
+	storage := httptest.NewServer(handler)
+	t.Cleanup(storage.Close)
+
+	ref := &Reference{Host: registry.Listener.Addr().String(), Repository: "packs/hello_world", Tag: DefaultTag}
+	client := &Client{auth: map[string]string{"repository:packs/hello_world:pull,push": "Bearer token"}}
+
+	// A location on the registry is sent with its credentials, while one
+	// on another host is not.
+	for _, loc := range []string{registry.URL + "/v2/uploads/1", storage.URL + "/uploads/1"} {
+		resp, err := client.do(context.Background(), ref, http.MethodPut, loc, nil, nil, "pull,push")
+		must.NoError(t, err)
+		resp.Body.Close()
+	}
+	must.Eq(t, []string{"Bearer token", ""}, gotAuth)
+}
+
+func TestClient_Signature(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	registry := ocitest.NewRegistry(t)
+	packDir := t.TempDir()
+	must.NoError(t, os.WriteFile(filepath.Join(packDir, "metadata.hcl"), []byte("pack {}\n"), 0o644))
+
+	namespace, err := ParseReference("oci://" + registry.Host() + "/packs")
+	must.NoError(t, err)
+	signed, err := namespace.Pack("signed", "")
+	must.NoError(t, err)
+	unsigned, err := namespace.Pack("unsigned", "")
+	must.NoError(t, err)
+
+	client := &Client{Sign: func(digest []byte) (string, error) {
+		return "signed " + string(digest), nil
+	}}
+	_, err = client.Push(ctx, signed, packDir, nil)
+	must.NoError(t, err)
+	_, err = (&Client{}).Push(ctx, unsigned, packDir, nil)
+	must.NoError(t, err)
+
+	var manifest ocispec.Manifest
+	must.NoError(t, json.Unmarshal(registry.Manifest("packs/signed", "latest"), &manifest))
+	layer := manifest.Layers[0]
+	must.Eq(t, "signed "+layer.Digest.String(), layer.Annotations[SignatureAnnotation])
+
+	client.Sign = func([]byte) (string, error) { return "", errors.New("no key") }
+	_, err = client.Push(ctx, signed, packDir, nil)
+	must.ErrorContains(t, err, "failed to sign pack: no key")
+
+	client.Verify = func(digest []byte, signature string) error {
+		if signature != "signed "+string(digest) {
+			return errors.New("bad signature")
+		}
+		return nil
+	}
+	_, err = client.Pull(ctx, signed, filepath.Join(t.TempDir(), "signed"))
+	must.NoError(t, err)
+
+	// The signature is verified before the layer is downloaded.
+	pullDir := filepath.Join(t.TempDir(), "unsigned")
+	_, err = client.Pull(ctx, unsigned, pullDir)
+	must.ErrorContains(t, err, "bad signature")
+	must.FileNotExists(t, filepath.Join(pullDir, "metadata.hcl"))
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:packs/a:pull,push"`)
+	must.Eq(t, "Bearer", scheme)
+	must.Eq(t, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:packs/a:pull,push",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	must.Eq(t, "Basic", scheme)
+	must.Eq(t, map[string]string{"realm": "registry"}, params)
+}
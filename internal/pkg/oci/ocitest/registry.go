@@ -0,0 +1,214 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package ocitest provides an in-process stand-in for an OCI registry, for
+// testing code which pushes and pulls packs.
+package ocitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// token is the bearer token issued by the token service of a Registry which
+// requires authentication.
+const token = "ocitest-token"
+
+// Registry is an in-memory OCI registry serving the parts of the
+// distribution API used by nomad-pack: the catalog, manifests by tag, and
+// monolithic blob uploads. It listens on a loopback address, so it is
+// accessed over plain HTTP.
+type Registry struct {
+	server *httptest.Server
+
+	// Username and Password, if set, are required by the token service of
+	// the registry, which must be used to obtain a bearer token before any
+	// other request is accepted.
+	Username string
+	Password string
+
+	mu        sync.Mutex
+	blobs     map[digest.Digest][]byte
+	manifests map[string]map[string][]byte
+	uploads   int
+}
+
+// NewRegistry starts a Registry which is closed when the test finishes.
+func NewRegistry(t testing.TB) *Registry {
+	r := &Registry{
+		blobs:     make(map[digest.Digest][]byte),
+		manifests: make(map[string]map[string][]byte),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// Host returns the host and port of the registry, for use in references.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// Manifest returns the manifest tagged tag in repository, or nil if there is
+// none.
+func (r *Registry) Manifest(repository, tag string) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.manifests[repository][tag]
+}
+
+// Blob returns the blob with the digest, or nil if there is none.
+func (r *Registry) Blob(d digest.Digest) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.blobs[d]
+}
+
+// SetBlob replaces the content of the blob with the digest, so tests can
+// serve tampered content.
+func (r *Registry) SetBlob(d digest.Digest, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blobs[d] = data
+}
+
+func (r *Registry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		r.serveToken(w, req)
+		return
+	}
+
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/")
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	if r.Username != "" && req.Header.Get("Authorization") != "Bearer "+token {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="ocitest"`, r.server.URL))
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case path == "_catalog":
+		r.serveCatalog(w)
+	case strings.Contains(path, "/blobs/uploads/"):
+		repo, _, _ := strings.Cut(path, "/blobs/uploads/")
+		r.serveUpload(w, req, repo)
+	case strings.Contains(path, "/blobs/"):
+		i := strings.LastIndex(path, "/blobs/")
+		r.serveBlob(w, req, digest.Digest(path[i+len("/blobs/"):]))
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		r.serveManifest(w, req, path[:i], path[i+len("/manifests/"):])
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *Registry) serveToken(w http.ResponseWriter, req *http.Request) {
+	user, pass, ok := req.BasicAuth()
+	if !ok || user != r.Username || pass != r.Password {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials")
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+func (r *Registry) serveCatalog(w http.ResponseWriter) {
+	repos := make([]string, 0, len(r.manifests))
+	for repo := range r.manifests {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": repos})
+}
+
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request, repo string) {
+	switch req.Method {
+	case http.MethodPost:
+		r.uploads++
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", repo, r.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BLOB_UPLOAD_INVALID", err.Error())
+			return
+		}
+		d := digest.Digest(req.URL.Query().Get("digest"))
+		if d != digest.FromBytes(data) {
+			writeError(w, http.StatusBadRequest, "DIGEST_INVALID", "digest does not match content")
+			return
+		}
+		r.blobs[d] = data
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, d digest.Digest) {
+	data, ok := r.blobs[d]
+	if !ok {
+		writeError(w, http.StatusNotFound, "BLOB_UNKNOWN", "blob unknown to registry")
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", d.String())
+	if req.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, repo, tag string) {
+	switch req.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+		if r.manifests[repo] == nil {
+			r.manifests[repo] = make(map[string][]byte)
+		}
+		r.manifests[repo][tag] = data
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		data, ok := r.manifests[repo][tag]
+		if !ok {
+			writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest unknown")
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package oci stores packs as artifacts in OCI registries. Each pack is
+// pushed to its own repository as a manifest with a single layer holding the
+// pack directory, and the fields of its metadata.hcl as annotations, so a
+// namespace of repositories within a registry can be used as a pack registry.
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Scheme is the prefix of registry sources which are served from an OCI
+// registry rather than fetched with go-getter.
+const Scheme = "oci://"
+
+// DefaultTag is the tag used when a reference does not specify one.
+const DefaultTag = "latest"
+
+var (
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegexp        = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+)
+
+// IsReference reports whether source refers to an OCI registry.
+func IsReference(source string) bool {
+	return strings.HasPrefix(source, Scheme)
+}
+
+// Reference is the location of a repository within an OCI registry, along
+// with an optional tag.
+type Reference struct {
+	// Host is the host, and optional port, of the registry.
+	Host string
+
+	// Repository is the path of the repository within the registry. For a
+	// pack registry, it is the namespace which holds a repository per pack.
+	Repository string
+
+	// Tag is the tag of a manifest within the repository. It is empty if the
+	// reference did not specify one.
+	Tag string
+}
+
+// ParseReference parses an OCI source of the form
+// "oci://host[:port]/repository[:tag]".
+func ParseReference(source string) (*Reference, error) {
+	rest, ok := strings.CutPrefix(source, Scheme)
+	if !ok {
+		return nil, fmt.Errorf("OCI reference %q must begin with %s", source, Scheme)
+	}
+
+	host, repo, _ := strings.Cut(rest, "/")
+	if host == "" {
+		return nil, fmt.Errorf("OCI reference %q has no registry host", source)
+	}
+
+	ref := &Reference{Host: host}
+	if i := strings.LastIndex(repo, ":"); i != -1 {
+		repo, ref.Tag = repo[:i], repo[i+1:]
+	}
+	ref.Repository = strings.TrimSuffix(repo, "/")
+
+	if err := ref.validate(); err != nil {
+		return nil, fmt.Errorf("invalid OCI reference %q: %w", source, err)
+	}
+	return ref, nil
+}
+
+func (r *Reference) validate() error {
+	if r.Repository == "" {
+		return errors.New("no repository")
+	}
+	if !repositoryRegexp.MatchString(r.Repository) {
+		return fmt.Errorf("repository %q must be lowercase alphanumeric path components separated by '.', '_', '-' or '/'", r.Repository)
+	}
+	if r.Tag != "" && !tagRegexp.MatchString(r.Tag) {
+		return fmt.Errorf("tag %q must be alphanumeric, '.', '_' or '-', and at most 128 characters", r.Tag)
+	}
+	return nil
+}
+
+// String returns the reference in the form accepted by ParseReference.
+func (r *Reference) String() string {
+	s := Scheme + r.Host + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	return s
+}
+
+// Pack returns the reference of the repository of the named pack, within the
+// namespace r, at tag. The DefaultTag is used when tag is empty.
+func (r *Reference) Pack(name, tag string) (*Reference, error) {
+	if tag == "" {
+		tag = DefaultTag
+	}
+	ref := &Reference{Host: r.Host, Repository: r.Repository + "/" + name, Tag: tag}
+	if err := ref.validate(); err != nil {
+		return nil, fmt.Errorf("invalid OCI reference for pack %q: %w", name, err)
+	}
+	return ref, nil
+}
+
+// baseURL returns the URL of the registry API. Registries on a loopback
+// address are accessed over plain HTTP, matching the default of Docker,
+// while every other registry must be served over HTTPS.
+func (r *Reference) baseURL() string {
+	scheme := "https"
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host + "/v2/"
+}
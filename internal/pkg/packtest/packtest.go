@@ -0,0 +1,292 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package packtest loads and checks the unit test cases of a pack. Each case
+// is a directory within the pack's tests directory which may contain:
+//
+//   - vars.hcl: a variable file the pack is rendered with for the case.
+//   - assertions.hcl: assert blocks evaluated against the parsed jobs.
+//   - golden/: the expected rendered output of the pack's templates.
+//
+// Cases run without a Nomad cluster, so they are suited to CI.
+package packtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
+	"github.com/hashicorp/nomad-pack/internal/pkg/jobexpr"
+)
+
+const (
+	// TestsDir is the directory of a pack containing its unit test cases.
+	TestsDir = "tests"
+
+	VarFileName        = "vars.hcl"
+	AssertionsFileName = "assertions.hcl"
+	GoldenDirName      = "golden"
+)
+
+// Case is a single unit test case of a pack.
+type Case struct {
+	Name string
+	Path string
+
+	// VarFile is the path of the case's variable file, or empty if the case
+	// renders the pack with its default variable values.
+	VarFile string
+
+	Assertions []*Assertion
+
+	// GoldenPath is the path of the case's golden directory, or empty if the
+	// case has no golden files.
+	GoldenPath string
+}
+
+// Assertion is a condition which must hold for a job rendered by the pack.
+type Assertion struct {
+	Name         string         `hcl:"name,label"`
+	Job          string         `hcl:"job,optional"`
+	Condition    hcl.Expression `hcl:"condition"`
+	ErrorMessage string         `hcl:"error_message,optional"`
+}
+
+type assertionsFile struct {
+	Assertions []*Assertion `hcl:"assert,block"`
+}
+
+// LoadCases returns the test cases of the pack at packPath, sorted by name.
+func LoadCases(packPath string) ([]*Case, error) {
+	testsPath := filepath.Join(packPath, TestsDir)
+
+	entries, err := os.ReadDir(testsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("pack has no %s directory", TestsDir)
+		}
+		return nil, fmt.Errorf("failed to read %s directory: %w", TestsDir, err)
+	}
+
+	var cases []*Case
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		tc, err := loadCase(entry.Name(), filepath.Join(testsPath, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, tc)
+	}
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("pack has no test cases within its %s directory", TestsDir)
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+func loadCase(name, path string) (*Case, error) {
+	tc := &Case{Name: name, Path: path}
+
+	if varFile := filepath.Join(path, VarFileName); fileExists(varFile) {
+		tc.VarFile = varFile
+	}
+
+	if assertions := filepath.Join(path, AssertionsFileName); fileExists(assertions) {
+		asserts, diags := ParseAssertions(assertions)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("test case %q: %w", name, diags)
+		}
+		tc.Assertions = asserts
+	}
+
+	if golden := filepath.Join(path, GoldenDirName); fileExists(golden) {
+		tc.GoldenPath = golden
+	}
+	return tc, nil
+}
+
+// HasChecks returns whether the case has any assertions or golden files to
+// check the rendered pack against.
+func (c *Case) HasChecks() bool {
+	return len(c.Assertions) > 0 || c.GoldenPath != ""
+}
+
+// ParseAssertions parses the assert blocks of the file.
+func ParseAssertions(path string) ([]*Assertion, hcl.Diagnostics) {
+	f, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var file assertionsFile
+	if diags := gohcl.DecodeBody(f.Body, nil, &file); diags.HasErrors() {
+		return nil, diags
+	}
+
+	return file.Assertions, nil
+}
+
+// Check evaluates the assertion against the job it targets within jobs, which
+// are keyed by job ID. An assertion without a job may only be used when there
+// is a single job.
+func (a *Assertion) Check(jobs map[string]*api.Job) hcl.Diagnostics {
+	job, diags := a.targetJob(jobs)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	ctx, err := jobexpr.EvalContext(job)
+	if err != nil {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to evaluate assertion",
+			Detail:   err.Error(),
+			Subject:  a.Condition.Range().Ptr(),
+		}}
+	}
+
+	ok, diags := jobexpr.EvalCondition(a.Condition, ctx)
+	if diags.HasErrors() || ok {
+		return diags
+	}
+
+	detail := fmt.Sprintf("Assertion %q failed for job %q.", a.Name, *job.ID)
+	if a.ErrorMessage != "" {
+		detail = fmt.Sprintf("%s %s", detail, a.ErrorMessage)
+	}
+	return append(diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Assertion failed",
+		Detail:   detail,
+		Subject:  a.Condition.Range().Ptr(),
+	})
+}
+
+func (a *Assertion) targetJob(jobs map[string]*api.Job) (*api.Job, hcl.Diagnostics) {
+	if a.Job != "" {
+		if job, ok := jobs[a.Job]; ok {
+			return job, nil
+		}
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unknown job",
+			Detail:   fmt.Sprintf("Assertion %q targets job %q which the pack did not render.", a.Name, a.Job),
+			Subject:  a.Condition.Range().Ptr(),
+		}}
+	}
+
+	if len(jobs) != 1 {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Missing job",
+			Detail:   fmt.Sprintf("Assertion %q must set job, as the pack rendered %d jobs.", a.Name, len(jobs)),
+			Subject:  a.Condition.Range().Ptr(),
+		}}
+	}
+	for _, job := range jobs {
+		return job, nil
+	}
+	return nil, nil
+}
+
+// GoldenFileName returns the name of the golden file for the rendered
+// template, relative to the golden directory.
+func GoldenFileName(tplName string) string {
+	return filepath.FromSlash(strings.TrimSuffix(tplName, ".tpl"))
+}
+
+// CompareGolden compares the rendered templates, keyed by template name, with
+// the files of the golden directory. A unified diff is returned for each
+// template which does not match, and a mismatch is also reported for each
+// rendered template without a golden file, and each golden file without a
+// rendered template, so that a pack gaining or losing a job fails the test.
+func CompareGolden(goldenPath string, rendered map[string]string) ([]string, error) {
+	expected := map[string]string{}
+
+	err := filepath.WalkDir(goldenPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(goldenPath, path)
+		if err != nil {
+			return err
+		}
+		expected[rel] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden files: %w", err)
+	}
+
+	actual := make(map[string]string, len(rendered))
+	for tplName, content := range rendered {
+		actual[GoldenFileName(tplName)] = content
+	}
+
+	names := make([]string, 0, len(expected)+len(actual))
+	for name := range expected {
+		names = append(names, name)
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []string
+	for _, name := range names {
+		content, ok := actual[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("golden file %s has no rendered template", name))
+			continue
+		}
+		if _, ok := expected[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("rendered template %s has no golden file", name))
+			continue
+		}
+		if diff := helper.UnifiedDiff(filepath.Join(GoldenDirName, name), "rendered", expected[name], content); diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// WriteGolden replaces the golden directory with the rendered templates.
+func WriteGolden(goldenPath string, rendered map[string]string) error {
+	if err := os.RemoveAll(goldenPath); err != nil {
+		return fmt.Errorf("failed to remove golden files: %w", err)
+	}
+
+	for tplName, content := range rendered {
+		path := filepath.Join(goldenPath, GoldenFileName(tplName))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create golden directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write golden file: %w", err)
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,129 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package packtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	must.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	must.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestLoadCases(t *testing.T) {
+	packPath := t.TempDir()
+
+	writeFile(t, filepath.Join(packPath, TestsDir, "defaults", GoldenDirName, "pack", "templates", "app.nomad"), "job {}\n")
+	writeFile(t, filepath.Join(packPath, TestsDir, "large", VarFileName), "count = 3\n")
+	writeFile(t, filepath.Join(packPath, TestsDir, "large", AssertionsFileName), `
+assert "memory" {
+  job           = "app"
+  condition     = tasks.web.Resources.MemoryMB == 512
+  error_message = "The web task should have 512 MB of memory."
+}
+`)
+
+	cases, err := LoadCases(packPath)
+	must.NoError(t, err)
+	must.Len(t, 2, cases)
+
+	must.Eq(t, "defaults", cases[0].Name)
+	must.Eq(t, "", cases[0].VarFile)
+	must.Len(t, 0, cases[0].Assertions)
+	must.Eq(t, filepath.Join(packPath, TestsDir, "defaults", GoldenDirName), cases[0].GoldenPath)
+
+	must.Eq(t, "large", cases[1].Name)
+	must.Eq(t, filepath.Join(packPath, TestsDir, "large", VarFileName), cases[1].VarFile)
+	must.Len(t, 1, cases[1].Assertions)
+	must.Eq(t, "app", cases[1].Assertions[0].Job)
+	for _, tc := range cases {
+		must.True(t, tc.HasChecks())
+	}
+
+	// A case with nothing to check is loaded, so its golden files can be
+	// written for the first time.
+	must.NoError(t, os.MkdirAll(filepath.Join(packPath, TestsDir, "empty"), 0o755))
+	cases, err = LoadCases(packPath)
+	must.NoError(t, err)
+	must.Len(t, 3, cases)
+	must.False(t, cases[1].HasChecks())
+}
+
+func TestAssertion_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), AssertionsFileName)
+	writeFile(t, path, `
+assert "memory" {
+  condition     = tasks.web.Resources.MemoryMB == 512
+  error_message = "The web task should have 512 MB of memory."
+}
+
+assert "count" {
+  job       = "app"
+  condition = groups.app.Count == 3
+}
+`)
+
+	asserts, diags := ParseAssertions(path)
+	must.False(t, diags.HasErrors())
+	must.Len(t, 2, asserts)
+
+	memory := 256
+	app := api.NewServiceJob("app", "app", "global", 50)
+	app.AddTaskGroup(api.NewTaskGroup("app", 3).AddTask(&api.Task{
+		Name:      "web",
+		Resources: &api.Resources{MemoryMB: &memory},
+	}))
+	jobs := map[string]*api.Job{"app": app}
+
+	diags = asserts[0].Check(jobs)
+	must.True(t, diags.HasErrors())
+	must.StrContains(t, diags.Error(), "The web task should have 512 MB of memory.")
+
+	must.False(t, asserts[1].Check(jobs).HasErrors())
+
+	// Without a job, the assertion is ambiguous once there is more than one.
+	jobs["db"] = api.NewServiceJob("db", "db", "global", 50)
+	must.StrContains(t, asserts[0].Check(jobs).Error(), "must set job")
+}
+
+func TestCompareGolden(t *testing.T) {
+	goldenPath := t.TempDir()
+
+	rendered := map[string]string{
+		"pack/templates/app.nomad.tpl": "job \"app\" {\n  type = \"service\"\n}\n",
+		"pack/templates/db.nomad.tpl":  "job \"db\" {}\n",
+	}
+
+	// Writing the golden files means the rendered templates match them.
+	must.NoError(t, WriteGolden(goldenPath, rendered))
+	diffs, err := CompareGolden(goldenPath, rendered)
+	must.NoError(t, err)
+	must.Len(t, 0, diffs)
+
+	rendered["pack/templates/app.nomad.tpl"] = "job \"app\" {\n  type = \"batch\"\n}\n"
+	delete(rendered, "pack/templates/db.nomad.tpl")
+
+	diffs, err = CompareGolden(goldenPath, rendered)
+	must.NoError(t, err)
+	must.Len(t, 2, diffs)
+	must.StrContains(t, diffs[0], "-  type = \"service\"")
+	must.StrContains(t, diffs[0], "+  type = \"batch\"")
+	must.StrContains(t, diffs[1], "has no rendered template")
+
+	// A template which is rendered without a golden file is reported too.
+	rendered["pack/templates/db.nomad.tpl"] = "job \"db\" {}\n"
+	rendered["pack/templates/cache.nomad.tpl"] = "job \"cache\" {}\n"
+
+	diffs, err = CompareGolden(goldenPath, rendered)
+	must.NoError(t, err)
+	must.Len(t, 2, diffs)
+	must.Eq(t, "rendered template "+filepath.FromSlash("pack/templates/cache.nomad")+" has no golden file", diffs[1])
+}
@@ -0,0 +1,202 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package planfile reads and writes the plan files saved by the plan command.
+// A plan file captures the rendered templates of a pack along with the job
+// modify index Nomad reported for each job when it was planned, so the run
+// command can later apply exactly what was reviewed, and refuse to if any of
+// the jobs has been changed in the meantime.
+package planfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// FormatVersion is the version of the plan file format written by this
+// version of Nomad Pack. Plan files of any other version are rejected, as a
+// plan is only meant to be applied by the same tooling which created it.
+const FormatVersion = 1
+
+// Plan is the saved result of planning a pack deployment.
+type Plan struct {
+	FormatVersion int `json:"format_version"`
+
+	// NomadPackVersion is the version of Nomad Pack which wrote the plan, for
+	// information only.
+	NomadPackVersion string `json:"nomad_pack_version"`
+
+	// Timestamp is the time at which the plan was made.
+	Timestamp time.Time `json:"timestamp"`
+
+	// DeploymentName, PackName, PackPath, PackRef, and RegistryName mirror
+	// the runner configuration used when the pack was planned, so the jobs
+	// are given the same nomad-pack meta when the plan is applied.
+	DeploymentName string `json:"deployment_name"`
+	PackName       string `json:"pack_name"`
+	PackPath       string `json:"pack_path"`
+	PackRef        string `json:"pack_ref"`
+	RegistryName   string `json:"registry_name"`
+
+	// Variables contains the resolved variable values used to render the
+	// pack, keyed by their fully qualified name. They are recorded with the
+	// release when the plan is applied.
+	Variables map[string]any `json:"variables,omitempty"`
+
+	// Templates contains the rendered templates which were planned, keyed by
+	// template name, before the nomad-pack job meta is injected.
+	Templates map[string]string `json:"templates"`
+
+	// Secrets is set when any variable was read from a source of secrets, so
+	// the templates are left out of the release recorded when the plan is
+	// applied.
+	Secrets bool `json:"secrets,omitempty"`
+
+	// Jobs is the plan of each job, sorted by template name.
+	Jobs []*Job `json:"jobs"`
+
+	// Unplanned lists the templates of hook and test jobs, sorted. They are
+	// not registered as part of the deployment, so are neither planned nor
+	// given a check index: hooks are run each time the plan is applied, as
+	// they are by run, and tests are only run by the test command.
+	Unplanned []string `json:"unplanned,omitempty"`
+
+	// NomadVariables are the Nomad Variables declared by the pack, which are
+	// created when the plan is applied.
+	NomadVariables []*NomadVariable `json:"nomad_variables,omitempty"`
+
+	// Output is the rendered output template of the pack, if it has one.
+	Output string `json:"output,omitempty"`
+}
+
+// Job is the plan of a single job of the deployment.
+type Job struct {
+	Template  string `json:"template"`
+	ID        string `json:"id"`
+	Namespace string `json:"namespace,omitempty"`
+	Region    string `json:"region,omitempty"`
+
+	// JobModifyIndex is the modify index of the job at the time it was
+	// planned, or zero if the job did not exist. The job is only registered
+	// when applying the plan if its index is unchanged.
+	JobModifyIndex uint64 `json:"job_modify_index"`
+
+	// Diff is the difference between the running job and the planned one,
+	// if the plan was made with a diff.
+	Diff *api.JobDiff `json:"diff,omitempty"`
+}
+
+// NomadVariable is a Nomad Variable declared by a nomad_variable block of the
+// pack, with its items converted to strings as they are stored in Nomad.
+type NomadVariable struct {
+	Name      string            `json:"name"`
+	Path      string            `json:"path"`
+	Namespace string            `json:"namespace,omitempty"`
+	Items     map[string]string `json:"items"`
+}
+
+// CheckIndexes returns the job modify index of each job keyed by template
+// name, for registering the jobs with check-index semantics.
+func (p *Plan) CheckIndexes() map[string]uint64 {
+	out := make(map[string]uint64, len(p.Jobs))
+	for _, j := range p.Jobs {
+		out[j.Template] = j.JobModifyIndex
+	}
+	return out
+}
+
+// Validate checks the plan is of a supported format and that every template
+// has the plan of its job, unless it is listed as unplanned.
+func (p *Plan) Validate() error {
+	if p.FormatVersion != FormatVersion {
+		return fmt.Errorf("unsupported plan file format version %d, expected %d", p.FormatVersion, FormatVersion)
+	}
+	if p.DeploymentName == "" {
+		return errors.New("plan file has no deployment name")
+	}
+	if len(p.Templates) == 0 {
+		return errors.New("plan file has no templates")
+	}
+
+	planned := make(map[string]struct{}, len(p.Jobs)+len(p.Unplanned))
+	for _, j := range p.Jobs {
+		planned[j.Template] = struct{}{}
+	}
+	for _, name := range p.Unplanned {
+		if _, ok := p.Templates[name]; !ok {
+			return fmt.Errorf("plan file has no template %q listed as unplanned", name)
+		}
+		planned[name] = struct{}{}
+	}
+	for name := range p.Templates {
+		if _, ok := planned[name]; !ok {
+			return fmt.Errorf("plan file has no job plan for template %q", name)
+		}
+	}
+	return nil
+}
+
+// Write saves the plan to path. The file is only readable by its owner, as
+// the variables and Nomad Variables of a pack may hold secrets.
+func Write(path string, p *Plan) error {
+	p.FormatVersion = FormatVersion
+	sort.Slice(p.Jobs, func(i, j int) bool { return p.Jobs[i].Template < p.Jobs[j].Template })
+	sort.Strings(p.Unplanned)
+
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// Read loads and validates the plan saved at path.
+func Read(path string) (*Plan, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var p Plan
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// IsPlanFile returns whether path is a plan file, and so should be read as a
+// plan rather than a pack. It is detected by its content: a JSON object whose
+// first key is format_version, as written by Write. Anything else, such as a
+// pack directory or an unrelated file, is left to be resolved as a pack.
+func IsPlanFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	// Only the leading tokens are read, so large files are not loaded.
+	dec := json.NewDecoder(f)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	tok, err := dec.Token()
+	return err == nil && tok == "format_version"
+}
@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func testPlan() *Plan {
+	return &Plan{
+		DeploymentName: "example",
+		PackName:       "example",
+		Templates: map[string]string{
+			"example/templates/b.nomad.tpl": `job "b" {}`,
+			"example/templates/a.nomad.tpl": `job "a" {}`,
+		},
+		Jobs: []*Job{
+			{Template: "example/templates/b.nomad.tpl", ID: "b", JobModifyIndex: 42},
+			{Template: "example/templates/a.nomad.tpl", ID: "a"},
+		},
+		NomadVariables: []*NomadVariable{
+			{Name: "creds", Path: "nomad/jobs/a", Items: map[string]string{"password": "secret"}},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	must.NoError(t, Write(path, testPlan()))
+
+	info, err := os.Stat(path)
+	must.NoError(t, err)
+	must.Eq(t, os.FileMode(0o600), info.Mode().Perm())
+	must.True(t, IsPlanFile(path))
+	must.False(t, IsPlanFile(filepath.Dir(path)))
+
+	p, err := Read(path)
+	must.NoError(t, err)
+	must.Eq(t, FormatVersion, p.FormatVersion)
+	must.Eq(t, "a", p.Jobs[0].ID)
+	must.Eq(t, map[string]uint64{
+		"example/templates/a.nomad.tpl": 0,
+		"example/templates/b.nomad.tpl": 42,
+	}, p.CheckIndexes())
+	must.Eq(t, testPlan().NomadVariables, p.NomadVariables)
+}
+
+func TestWriteRead_HookTemplates(t *testing.T) {
+	hook := "example/templates/hooks/pre-run/migrate.nomad.tpl"
+	test := "example/templates/tests/smoke.nomad.tpl"
+
+	plan := testPlan()
+	plan.Templates[hook] = `job "migrate" { type = "batch" }`
+	plan.Templates[test] = `job "smoke" { type = "batch" }`
+	plan.Unplanned = []string{test, hook}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	must.NoError(t, Write(path, plan))
+
+	p, err := Read(path)
+	must.NoError(t, err)
+	must.Eq(t, []string{hook, test}, p.Unplanned)
+	must.MapLen(t, 4, p.Templates)
+	must.MapNotContainsKeys(t, p.CheckIndexes(), []string{hook, test})
+
+	p.Unplanned = nil
+	must.ErrorContains(t, p.Validate(), "plan file has no job plan for template")
+}
+
+func TestRead_Invalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*Plan)
+		err    string
+	}{
+		{
+			name:   "format version",
+			modify: func(p *Plan) { p.FormatVersion = 99 },
+			err:    "unsupported plan file format version 99, expected 1",
+		},
+		{
+			name:   "no deployment",
+			modify: func(p *Plan) { p.DeploymentName = "" },
+			err:    "plan file has no deployment name",
+		},
+		{
+			name:   "missing job plan",
+			modify: func(p *Plan) { p.Jobs = p.Jobs[:1] },
+			err:    `plan file has no job plan for template "example/templates/a.nomad.tpl"`,
+		},
+		{
+			name:   "missing unplanned template",
+			modify: func(p *Plan) { p.Unplanned = []string{"example/templates/hooks/pre-run/c.nomad.tpl"} },
+			err:    `plan file has no template "example/templates/hooks/pre-run/c.nomad.tpl" listed as unplanned`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := testPlan()
+			p.FormatVersion = FormatVersion
+			tc.modify(p)
+			must.EqError(t, p.Validate(), tc.err)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	must.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := Read(path)
+	must.ErrorContains(t, err, "failed to parse plan file")
+}
+
+func TestIsPlanFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"empty":     "",
+		"text":      "variable \"count\" {}\n",
+		"json":      `{"name": "example", "format_version": 1}`,
+		"array":     `["format_version"]`,
+		"plan.json": `{"format_version": 1}`,
+	}
+	for name, content := range files {
+		must.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	must.True(t, IsPlanFile(filepath.Join(dir, "plan.json")))
+	for _, name := range []string{"empty", "text", "json", "array", "missing"} {
+		must.False(t, IsPlanFile(filepath.Join(dir, name)), must.Sprint(name))
+	}
+}
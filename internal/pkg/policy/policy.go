@@ -0,0 +1,212 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package policy loads and evaluates the rules which rendered pack jobs must
+// follow before they are planned or run. Rules are written as rule blocks
+// within the HCL files of a policy directory, for example:
+//
+//	rule "no_raw_exec" {
+//	  condition = !contains([for t in values(tasks) : t.Driver], "raw_exec")
+//	  message   = "The raw_exec driver is not allowed."
+//	}
+//
+// Conditions are evaluated against each job in the same way as pack test
+// assertions, so the job, groups and tasks variables are available.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/jobexpr"
+)
+
+const (
+	// LevelError is the level of a rule whose violation prevents the jobs
+	// from being planned or run. It is the default level of a rule.
+	LevelError = "error"
+
+	// LevelWarning is the level of a rule whose violation is reported, but
+	// does not prevent the jobs from being planned or run.
+	LevelWarning = "warning"
+)
+
+// Rule is a condition which must hold for every job rendered by a pack.
+type Rule struct {
+	Name      string
+	Level     string
+	Condition hcl.Expression
+	Message   string
+
+	DeclRange hcl.Range
+}
+
+var (
+	fileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "rule", LabelNames: []string{"name"}}},
+	}
+
+	ruleSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "level"},
+			{Name: "condition", Required: true},
+			{Name: "message"},
+		},
+	}
+)
+
+// Load parses the rules within the HCL files of the directory. Files are read
+// in lexical order, and rule names must be unique across the directory.
+func Load(dir string) ([]*Rule, hcl.Diagnostics) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read policy directory",
+			Detail:   fmt.Sprintf("The policy directory %q does not exist or is not a directory.", dir),
+		}}
+	}
+
+	// Glob returns the files in lexical order.
+	paths, err := filepath.Glob(filepath.Join(dir, "*.hcl"))
+	if err != nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read policy directory",
+			Detail:   err.Error(),
+		}}
+	}
+
+	var (
+		rules []*Rule
+		diags hcl.Diagnostics
+	)
+
+	parser := hclparse.NewParser()
+	seen := map[string]*Rule{}
+
+	for _, path := range paths {
+		fileRules, fileDiags := parseFile(parser, path)
+		diags = append(diags, fileDiags...)
+
+		for _, rule := range fileRules {
+			if prev, ok := seen[rule.Name]; ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate policy rule",
+					Detail:   fmt.Sprintf("A rule named %q was already declared at %s.", rule.Name, prev.DeclRange),
+					Subject:  rule.DeclRange.Ptr(),
+				})
+				continue
+			}
+			seen[rule.Name] = rule
+			rules = append(rules, rule)
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return rules, diags
+}
+
+func parseFile(parser *hclparse.Parser, path string) ([]*Rule, hcl.Diagnostics) {
+	f, diags := parser.ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	content, diags := f.Body.Content(fileSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	rules := make([]*Rule, 0, len(content.Blocks))
+	for _, block := range content.Blocks {
+		rule, ruleDiags := decodeRule(block)
+		diags = append(diags, ruleDiags...)
+		if ruleDiags.HasErrors() {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, diags
+}
+
+func decodeRule(block *hcl.Block) (*Rule, hcl.Diagnostics) {
+	content, diags := block.Body.Content(ruleSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	rule := &Rule{
+		Name:      block.Labels[0],
+		Level:     LevelError,
+		Condition: content.Attributes["condition"].Expr,
+		DeclRange: block.DefRange,
+	}
+
+	if attr, ok := content.Attributes["level"]; ok {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &rule.Level)...)
+	}
+	if attr, ok := content.Attributes["message"]; ok {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &rule.Message)...)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	switch rule.Level {
+	case LevelError, LevelWarning:
+	default:
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid policy rule level",
+			Detail: fmt.Sprintf("Rule %q has level %q, but the level must be %q or %q.",
+				rule.Name, rule.Level, LevelError, LevelWarning),
+			Subject: content.Attributes["level"].Range.Ptr(),
+		})
+	}
+	return rule, diags
+}
+
+// Check evaluates the rule against the job. A violated rule returns a
+// diagnostic whose severity matches the level of the rule, while a condition
+// which cannot be evaluated is always an error.
+func (r *Rule) Check(job *api.Job) hcl.Diagnostics {
+	ctx, err := jobexpr.EvalContext(job)
+	if err != nil {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to evaluate policy rule",
+			Detail:   err.Error(),
+			Subject:  r.Condition.Range().Ptr(),
+		}}
+	}
+
+	ok, diags := jobexpr.EvalCondition(r.Condition, ctx)
+	if diags.HasErrors() || ok {
+		return diags
+	}
+
+	severity := hcl.DiagError
+	if r.Level == LevelWarning {
+		severity = hcl.DiagWarning
+	}
+
+	detail := fmt.Sprintf("Policy rule %q failed for job %q.", r.Name, *job.ID)
+	if r.Message != "" {
+		detail = fmt.Sprintf("%s %s", detail, r.Message)
+	}
+	return append(diags, &hcl.Diagnostic{
+		Severity: severity,
+		Summary:  "Policy rule failed",
+		Detail:   detail,
+		Subject:  r.Condition.Range().Ptr(),
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	must.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func testJob(driver string, memoryMax int) *api.Job {
+	job := api.NewServiceJob("app", "app", "global", 50)
+	job.AddTaskGroup(api.NewTaskGroup("app", 1).AddTask(&api.Task{
+		Name:      "web",
+		Driver:    driver,
+		Resources: &api.Resources{MemoryMaxMB: &memoryMax},
+	}))
+	return job
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "drivers.hcl"), `
+rule "no_raw_exec" {
+  condition = !contains([for t in values(tasks) : t.Driver], "raw_exec")
+  message   = "The raw_exec driver is not allowed."
+}
+`)
+	writeFile(t, filepath.Join(dir, "resources.hcl"), `
+rule "memory_max" {
+  level     = "warning"
+  condition = length([for t in values(tasks) : t.Name if t.Resources.MemoryMaxMB == 0]) == 0
+}
+`)
+	writeFile(t, filepath.Join(dir, "README.md"), "Not a policy file.")
+
+	rules, diags := Load(dir)
+	must.False(t, diags.HasErrors())
+	must.Len(t, 2, rules)
+
+	must.Eq(t, "no_raw_exec", rules[0].Name)
+	must.Eq(t, LevelError, rules[0].Level)
+	must.Eq(t, "The raw_exec driver is not allowed.", rules[0].Message)
+	must.Eq(t, "memory_max", rules[1].Name)
+	must.Eq(t, LevelWarning, rules[1].Level)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		summary string
+	}{
+		{
+			name: "invalid level",
+			content: `
+rule "a" {
+  level     = "fatal"
+  condition = true
+}
+`,
+			summary: "Invalid policy rule level",
+		},
+		{
+			name: "duplicate rule",
+			content: `
+rule "a" { condition = true }
+rule "a" { condition = false }
+`,
+			summary: "Duplicate policy rule",
+		},
+		{
+			name:    "unexpected block",
+			content: `rules "a" { condition = true }`,
+			summary: "Unsupported block type",
+		},
+		{
+			name:    "missing condition",
+			content: `rule "a" { message = "missing" }`,
+			summary: "Missing required argument",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "policy.hcl"), tc.content)
+
+			rules, diags := Load(dir)
+			must.Nil(t, rules)
+			must.True(t, diags.HasErrors())
+			must.Eq(t, tc.summary, diags[0].Summary)
+		})
+	}
+
+	_, diags := Load(filepath.Join(t.TempDir(), "missing"))
+	must.True(t, diags.HasErrors())
+}
+
+func TestRule_Check(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "policy.hcl"), `
+rule "no_raw_exec" {
+  condition = !contains([for t in values(tasks) : t.Driver], "raw_exec")
+  message   = "The raw_exec driver is not allowed."
+}
+
+rule "memory_max" {
+  level     = "warning"
+  condition = length([for t in values(tasks) : t.Name if t.Resources.MemoryMaxMB == 0]) == 0
+}
+
+rule "not_bool" {
+  condition = job.Name
+}
+`)
+	rules, diags := Load(dir)
+	must.False(t, diags.HasErrors())
+
+	compliant := testJob("docker", 1024)
+	for _, rule := range rules[:2] {
+		must.Len(t, 0, rule.Check(compliant))
+	}
+
+	violating := testJob("raw_exec", 0)
+
+	diags = rules[0].Check(violating)
+	must.Len(t, 1, diags)
+	must.Eq(t, hcl.DiagError, diags[0].Severity)
+	must.Eq(t, `Policy rule "no_raw_exec" failed for job "app". The raw_exec driver is not allowed.`, diags[0].Detail)
+
+	diags = rules[1].Check(violating)
+	must.Len(t, 1, diags)
+	must.Eq(t, hcl.DiagWarning, diags[0].Severity)
+
+	// A condition which cannot be evaluated is an error whatever the level
+	// of the rule.
+	diags = rules[2].Check(compliant)
+	must.True(t, diags.HasErrors())
+	must.Eq(t, "Invalid condition result", diags[0].Summary)
+}
@@ -0,0 +1,123 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package release records the history of pack deployments. Every successful
+// run of a pack produces a Release which captures exactly what was submitted
+// to Nomad, so that a deployment can later be inspected or rolled back to a
+// previous revision without having to re-render the pack.
+package release
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Release is a single recorded deployment of a pack.
+type Release struct {
+	// Revision is the monotonically increasing identifier of the release
+	// within its deployment. The first release of a deployment is revision 1.
+	Revision int `json:"revision"`
+
+	// DeploymentName, PackName, PackPath, PackRef, and RegistryName mirror
+	// the runner configuration used when the release was deployed.
+	DeploymentName string `json:"deployment_name"`
+	PackName       string `json:"pack_name"`
+	PackPath       string `json:"pack_path"`
+	PackRef        string `json:"pack_ref"`
+	RegistryName   string `json:"registry_name"`
+
+	// Timestamp is the time at which the release was recorded.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Variables contains the resolved variable values used to render the
+	// pack, keyed by their fully qualified name (e.g. "my_pack.count").
+	Variables map[string]any `json:"variables,omitempty"`
+
+	// Templates contains the rendered templates submitted to Nomad, keyed by
+	// template name. These are stored before the nomad-pack job meta is
+	// injected so that they can be passed directly to Runner.SetTemplates.
+	Templates map[string]string `json:"templates"`
+
+	// SecretsOmitted is set when the pack was rendered with variables read
+	// from a source of secrets. The rendered templates hold the secret values,
+	// so they are not stored, and the release cannot be rolled back to.
+	SecretsOmitted bool `json:"secrets_omitted,omitempty"`
+
+	// Jobs lists the Nomad jobs registered by this release along with the
+	// job version Nomad assigned to each.
+	Jobs []*Job `json:"jobs"`
+
+	// RollbackOf is set to the revision that was re-deployed when this
+	// release was created by the rollback command.
+	RollbackOf int `json:"rollback_of,omitempty"`
+}
+
+// Job identifies a single Nomad job version registered by a release.
+type Job struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace,omitempty"`
+	Region    string `json:"region,omitempty"`
+	Version   uint64 `json:"version"`
+}
+
+// JobIDs returns the sorted IDs of the jobs registered by the release.
+func (r *Release) JobIDs() []string {
+	ids := make([]string, 0, len(r.Jobs))
+	for _, j := range r.Jobs {
+		ids = append(ids, j.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// encode serializes the release into the compact form stored within a Nomad
+// Variable item. Rendered templates can be large, and Nomad limits the size
+// of a single variable, so the JSON document is gzipped and base64 encoded.
+func (r *Release) encode() (string, error) {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal release: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return "", fmt.Errorf("failed to compress release: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress release: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decode is the inverse of encode.
+func decode(s string) (*Release, error) {
+	compressed, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress release: %w", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress release: %w", err)
+	}
+
+	var r Release
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release: %w", err)
+	}
+	return &r, nil
+}
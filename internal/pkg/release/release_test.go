@@ -0,0 +1,107 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package release
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+)
+
+func TestRelease_EncodeDecode(t *testing.T) {
+	in := &Release{
+		Revision:       3,
+		DeploymentName: "simple_raw_exec@latest",
+		PackName:       "simple_raw_exec",
+		PackPath:       "/tmp/packs/simple_raw_exec",
+		PackRef:        "latest",
+		RegistryName:   "default",
+		Timestamp:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Variables: map[string]any{
+			"simple_raw_exec.count": float64(2),
+		},
+		Templates: map[string]string{
+			"simple_raw_exec/templates/simple_raw_exec.nomad": `job "simple_raw_exec" {}`,
+		},
+		Jobs: []*Job{
+			{ID: "simple_raw_exec", Namespace: "default", Version: 4},
+		},
+		RollbackOf: 1,
+	}
+
+	encoded, err := in.encode()
+	must.NoError(t, err)
+
+	out, err := decode(encoded)
+	must.NoError(t, err)
+	must.Eq(t, in, out)
+}
+
+func TestRelease_DecodeInvalid(t *testing.T) {
+	_, err := decode("not base64!")
+	must.ErrorContains(t, err, "failed to decode release")
+}
+
+func TestRelease_JobIDs(t *testing.T) {
+	r := &Release{Jobs: []*Job{{ID: "b"}, {ID: "a"}, {ID: "c"}}}
+	must.Eq(t, []string{"a", "b", "c"}, r.JobIDs())
+}
+
+func TestStore_VariablePath(t *testing.T) {
+	// Nomad only accepts a restricted character set in variable paths.
+	validPath := regexp.MustCompile(`^[a-zA-Z0-9-_~/]{1,128}$`)
+
+	testCases := []struct {
+		name       string
+		deployment string
+		revision   int
+		expected   string
+	}{
+		{
+			name:       "plain",
+			deployment: "my-pack_1",
+			revision:   1,
+			expected:   "nomad-pack/releases/my-pack_1/1",
+		},
+		{
+			name:       "default deployment name",
+			deployment: "simple_raw_exec@latest",
+			revision:   12,
+			expected:   "nomad-pack/releases/simple_raw_exec~40latest/12",
+		},
+		{
+			name:       "slashes and dots",
+			deployment: "team/app.v2",
+			revision:   2,
+			expected:   "nomad-pack/releases/team~2fapp~2ev2/2",
+		},
+		{
+			name:       "tilde is escaped",
+			deployment: "a~40b",
+			revision:   1,
+			expected:   "nomad-pack/releases/a~7e40b/1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := variablePath(tc.deployment, tc.revision)
+			must.Eq(t, tc.expected, p)
+			must.RegexMatch(t, validPath, p)
+		})
+	}
+}
+
+func TestStore_CheckPathLength(t *testing.T) {
+	must.NoError(t, checkPathLength("short", variablePath("short", 1)))
+
+	// Each "@" of the name takes three characters once escaped.
+	long := strings.Repeat("@", 36)
+	err := checkPathLength(long, variablePath(long, 1))
+	must.ErrorContains(t, err, "is too long to record its releases")
+	must.ErrorContains(t, err, "is 130 characters, but Nomad allows at most 128")
+}
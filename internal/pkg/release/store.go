@@ -0,0 +1,231 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package release
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+const (
+	// PathPrefix is the Nomad Variable path under which all releases are
+	// stored. Each deployment has its own sub-path containing one variable
+	// per revision.
+	PathPrefix = "nomad-pack/releases"
+
+	// itemKey is the Nomad Variable item which holds the encoded release.
+	itemKey = "release"
+
+	// maxRecordAttempts is the number of times Record will retry allocating
+	// a revision number when racing with another writer.
+	maxRecordAttempts = 3
+
+	// maxPathLength is the longest Nomad Variable path Nomad accepts.
+	maxPathLength = 128
+)
+
+// ErrNotFound is returned when a requested release does not exist.
+var ErrNotFound = errors.New("release not found")
+
+// Store reads and writes releases as Nomad Variables. Using Nomad as the
+// backing store means the history is shared by everyone operating against
+// the cluster and is protected by the same ACLs as the jobs themselves.
+type Store struct {
+	client    *api.Client
+	namespace string
+}
+
+// NewStore returns a Store that persists releases within the given Nomad
+// namespace. An empty namespace uses the default namespace.
+func NewStore(client *api.Client, namespace string) *Store {
+	if namespace == "" {
+		namespace = api.DefaultNamespace
+	}
+	return &Store{client: client, namespace: namespace}
+}
+
+// Record persists the release as the next revision of its deployment and
+// sets r.Revision accordingly.
+func (s *Store) Record(r *Release) error {
+	if r.DeploymentName == "" {
+		return errors.New("release has no deployment name")
+	}
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now().UTC()
+	}
+
+	var lastErr error
+	for range maxRecordAttempts {
+		revisions, err := s.revisions(r.DeploymentName)
+		if err != nil {
+			return err
+		}
+
+		r.Revision = 1
+		if len(revisions) > 0 {
+			r.Revision = revisions[len(revisions)-1] + 1
+		}
+
+		path := variablePath(r.DeploymentName, r.Revision)
+		if err := checkPathLength(r.DeploymentName, path); err != nil {
+			return err
+		}
+
+		encoded, err := r.encode()
+		if err != nil {
+			return err
+		}
+
+		// CheckedCreate only succeeds if the variable does not already exist,
+		// which protects against two concurrent runs claiming the same
+		// revision.
+		_, _, err = s.client.Variables().CheckedCreate(&api.Variable{
+			Namespace: s.namespace,
+			Path:      path,
+			Items:     api.VariableItems{itemKey: encoded},
+		}, s.writeOpts())
+		if err == nil {
+			return nil
+		}
+
+		var casErr api.ErrCASConflict
+		if !errors.As(err, &casErr) {
+			return fmt.Errorf("failed to write release %d of deployment %q: %w", r.Revision, r.DeploymentName, err)
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("failed to allocate a revision for deployment %q: %w", r.DeploymentName, lastErr)
+}
+
+// Get returns the release with the given revision for the deployment.
+func (s *Store) Get(deploymentName string, revision int) (*Release, error) {
+	v, _, err := s.client.Variables().Read(variablePath(deploymentName, revision), s.queryOpts())
+	if err != nil {
+		if errors.Is(err, api.ErrVariablePathNotFound) {
+			return nil, fmt.Errorf("revision %d of deployment %q: %w", revision, deploymentName, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read revision %d of deployment %q: %w", revision, deploymentName, err)
+	}
+
+	encoded, ok := v.Items[itemKey]
+	if !ok {
+		return nil, fmt.Errorf("revision %d of deployment %q is malformed", revision, deploymentName)
+	}
+	return decode(encoded)
+}
+
+// List returns every release of the deployment ordered by revision, oldest
+// first. A deployment without any recorded releases returns an empty list.
+func (s *Store) List(deploymentName string) ([]*Release, error) {
+	revisions, err := s.revisions(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*Release, 0, len(revisions))
+	for _, rev := range revisions {
+		r, err := s.Get(deploymentName, rev)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Latest returns the most recent release of the deployment.
+func (s *Store) Latest(deploymentName string) (*Release, error) {
+	revisions, err := s.revisions(deploymentName)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("deployment %q: %w", deploymentName, ErrNotFound)
+	}
+	return s.Get(deploymentName, revisions[len(revisions)-1])
+}
+
+// revisions returns the sorted revision numbers recorded for the deployment.
+func (s *Store) revisions(deploymentName string) ([]int, error) {
+	prefix := deploymentPath(deploymentName) + "/"
+
+	metas, _, err := s.client.Variables().PrefixList(prefix, s.queryOpts())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases of deployment %q: %w", deploymentName, err)
+	}
+
+	var revisions []int
+	for _, m := range metas {
+		// Prefix matching is on the raw path, so guard against picking up a
+		// deployment whose escaped name shares our prefix.
+		rest, ok := strings.CutPrefix(m.Path, prefix)
+		if !ok || strings.Contains(rest, "/") {
+			continue
+		}
+		rev, err := strconv.Atoi(rest)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Ints(revisions)
+	return revisions, nil
+}
+
+func (s *Store) queryOpts() *api.QueryOptions {
+	return &api.QueryOptions{Namespace: s.namespace}
+}
+
+func (s *Store) writeOpts() *api.WriteOptions {
+	return &api.WriteOptions{Namespace: s.namespace}
+}
+
+// deploymentPath returns the Nomad Variable path containing the releases of
+// the deployment.
+func deploymentPath(deploymentName string) string {
+	return PathPrefix + "/" + escapePathSegment(deploymentName)
+}
+
+// variablePath returns the Nomad Variable path of a single release.
+func variablePath(deploymentName string, revision int) string {
+	return deploymentPath(deploymentName) + "/" + strconv.Itoa(revision)
+}
+
+// checkPathLength returns an error if the Nomad Variable path of a release of
+// the deployment is longer than Nomad accepts. The deployment name is escaped
+// in the path, so each character other than a letter, digit, dash, or
+// underscore takes three.
+func checkPathLength(deploymentName, path string) error {
+	if len(path) <= maxPathLength {
+		return nil
+	}
+	return fmt.Errorf("deployment name %q is too long to record its releases: the Nomad Variable path %q is %d characters, but Nomad allows at most %d",
+		deploymentName, path, len(path), maxPathLength)
+}
+
+// escapePathSegment makes a deployment name safe for use as a single Nomad
+// Variable path segment. Variable paths only allow alphanumerics, dashes,
+// underscores, tildes, and slashes, but deployment names default to the
+// "pack@ref" form. Every other byte is replaced by a tilde followed by its
+// two-digit hex value, which keeps the mapping reversible and collision free.
+func escapePathSegment(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "~%02x", c)
+		}
+	}
+	return b.String()
+}
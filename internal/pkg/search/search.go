@@ -0,0 +1,178 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package search finds the packs of the cached registries which match a
+// query, ranking them by where the query matched.
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+)
+
+// The weights of the fields of a pack a term can match. A term matching the
+// name of a pack ranks it above any term matching its other fields.
+const (
+	weightNameExact           = 100
+	weightNamePrefix          = 60
+	weightName                = 40
+	weightFlag                = 20
+	weightDescription         = 15
+	weightVariableName        = 10
+	weightVariableDescription = 5
+	weightURL                 = 5
+)
+
+// Result is a pack which matched a query.
+type Result struct {
+	Registry *caching.Registry
+	Pack     *caching.Pack
+
+	// Score ranks the result against the other results, higher scores
+	// matching the query better.
+	Score int
+
+	// Matches describes the fields of the pack matched by the query, such
+	// as "name" or `variable "region"`.
+	Matches []string
+}
+
+// Search returns the packs of the registries matching the query, best match
+// first. The query is split into terms on whitespace, and a pack matches if
+// every term is found, ignoring case, in its name, description, application
+// URL, integration flags, or the names and descriptions of its variables.
+func Search(registries []*caching.Registry, query string) []*Result {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []*Result
+	for _, registry := range registries {
+		for _, p := range registry.Packs {
+			if result := match(newDocument(p), terms); result != nil {
+				result.Registry, result.Pack = registry, p
+				results = append(results, result)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Pack.Name() != b.Pack.Name() {
+			return a.Pack.Name() < b.Pack.Name()
+		}
+		if a.Registry.Name != b.Registry.Name {
+			return a.Registry.Name < b.Registry.Name
+		}
+		return a.Pack.Ref < b.Pack.Ref
+	})
+	return results
+}
+
+// document holds the lower-cased searchable fields of a pack.
+type document struct {
+	name        string
+	description string
+	url         string
+	flags       []string
+	variables   []*variable
+}
+
+// variable holds the lower-cased name and description of a variable, along
+// with its name as declared for display.
+type variable struct {
+	declared    string
+	name        string
+	description string
+}
+
+func newDocument(p *caching.Pack) *document {
+	d := &document{name: strings.ToLower(p.Name())}
+
+	if p.Metadata.Pack != nil {
+		d.description = strings.ToLower(p.Metadata.Pack.Description)
+	}
+	if p.Metadata.App != nil {
+		d.url = strings.ToLower(p.Metadata.App.URL)
+	}
+	if p.Metadata.Integration != nil {
+		for _, flag := range p.Metadata.Integration.Flags {
+			d.flags = append(d.flags, strings.ToLower(flag))
+		}
+	}
+
+	// A pack whose variables cannot be parsed can still be found by its
+	// metadata, so the error is not surfaced.
+	variables, _ := p.Variables()
+	for _, v := range variables {
+		d.variables = append(d.variables, &variable{
+			declared:    v.Name,
+			name:        strings.ToLower(v.Name),
+			description: strings.ToLower(v.Description),
+		})
+	}
+	return d
+}
+
+// match scores the document against the terms, returning nil if any term is
+// not found in it.
+func match(d *document, terms []string) *Result {
+	result := &Result{}
+	matched := make(map[string]bool)
+
+	for _, term := range terms {
+		// Each field counts once per term, however many of its flags or
+		// variables match, so a pack with many variables does not outrank
+		// one matching by name.
+		weights := make(map[string]int)
+		add := func(field string, weight int, match string) {
+			weights[field] = max(weights[field], weight)
+			if !matched[match] {
+				matched[match] = true
+				result.Matches = append(result.Matches, match)
+			}
+		}
+
+		switch {
+		case d.name == term:
+			add("name", weightNameExact, "name")
+		case strings.HasPrefix(d.name, term):
+			add("name", weightNamePrefix, "name")
+		case strings.Contains(d.name, term):
+			add("name", weightName, "name")
+		}
+		for _, flag := range d.flags {
+			if strings.Contains(flag, term) {
+				add("flag", weightFlag, fmt.Sprintf("flag %q", flag))
+			}
+		}
+		if strings.Contains(d.description, term) {
+			add("description", weightDescription, "description")
+		}
+		for _, v := range d.variables {
+			if strings.Contains(v.name, term) {
+				add("variable", weightVariableName, fmt.Sprintf("variable %q", v.declared))
+			} else if strings.Contains(v.description, term) {
+				add("variable", weightVariableDescription, fmt.Sprintf("variable %q", v.declared))
+			}
+		}
+		if strings.Contains(d.url, term) {
+			add("url", weightURL, "url")
+		}
+
+		if len(weights) == 0 {
+			return nil
+		}
+		for _, weight := range weights {
+			result.Score += weight
+		}
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package search
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/caching"
+	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
+	"github.com/hashicorp/nomad-pack/internal/pkg/testfixture"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+func metadataPack(name, description, url string, flags ...string) *caching.Pack {
+	return &caching.Pack{
+		Ref: "latest",
+		Pack: &pack.Pack{
+			Metadata: &pack.Metadata{
+				App:         &pack.MetadataApp{URL: url},
+				Pack:        &pack.MetadataPack{Name: name, Description: description},
+				Integration: &pack.MetadataIntegration{Flags: flags},
+			},
+		},
+	}
+}
+
+func loadedPack(t *testing.T, name string) *caching.Pack {
+	t.Helper()
+	p, err := loader.Load(testfixture.MustAbsPath("v2/test_registry/packs/" + name))
+	must.NoError(t, err)
+	return &caching.Pack{Ref: "latest", Pack: p}
+}
+
+func names(results []*Result) []string {
+	var out []string
+	for _, result := range results {
+		out = append(out, result.Registry.Name+"/"+result.Pack.Name())
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	ci.Parallel(t)
+
+	registries := []*caching.Registry{
+		{
+			Name: "community",
+			Packs: []*caching.Pack{
+				metadataPack("traefik", "Traefik reverse proxy", "https://traefik.io", "ingress"),
+				metadataPack("nginx", "Web server which can act as a proxy for traefik", "https://nginx.org"),
+				metadataPack("traefik_ingress", "", "", "load-balancer"),
+				loadedPack(t, "simple_raw_exec"),
+			},
+		},
+		{
+			Name: "internal",
+			Packs: []*caching.Pack{
+				metadataPack("traefik", "Internal Traefik", ""),
+				metadataPack("grafana", "Dashboards", "https://grafana.com", "observability"),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		query   string
+		results []string
+		matches []string
+	}{
+		{
+			name:    "empty",
+			query:   "  ",
+			results: nil,
+		},
+		{
+			name:    "no match",
+			query:   "consul",
+			results: nil,
+		},
+		{
+			name:    "ranked by name",
+			query:   "Traefik",
+			results: []string{"community/traefik", "internal/traefik", "community/traefik_ingress", "community/nginx"},
+			matches: []string{"name", "description", "url"},
+		},
+		{
+			name:    "integration flag",
+			query:   "observ",
+			results: []string{"internal/grafana"},
+			matches: []string{`flag "observability"`},
+		},
+		{
+			name:    "url",
+			query:   "nginx.org",
+			results: []string{"community/nginx"},
+			matches: []string{"url"},
+		},
+		{
+			name:    "every term must match",
+			query:   "traefik ingress",
+			results: []string{"community/traefik", "community/traefik_ingress"},
+			matches: []string{"name", "description", "url", `flag "ingress"`},
+		},
+		{
+			name:    "variable name",
+			query:   "datacenters",
+			results: []string{"community/simple_raw_exec"},
+			matches: []string{`variable "datacenters"`},
+		},
+		{
+			name:    "variable description",
+			query:   "app instances",
+			results: []string{"community/simple_raw_exec"},
+			matches: []string{`variable "count"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results := Search(registries, tc.query)
+			must.Eq(t, tc.results, names(results))
+			if len(results) > 0 {
+				must.Eq(t, tc.matches, results[0].Matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package signature signs and verifies the content of registries and packs
+// with OpenPGP keys. Registries are verified against a key ring of public
+// keys the user trusts, either through the signature of the commit a git
+// registry was cloned at, or through the signature of each pack pulled from
+// an OCI registry.
+package signature
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrNotSigned is returned when content which must be verified carries no
+// signature.
+var ErrNotSigned = errors.New("no signature found")
+
+// ReadKeyRing reads the armored OpenPGP public keys in the file at path, or
+// in every file within path if it is a directory.
+func ReadKeyRing(path string) (openpgp.EntityList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	var keyring openpgp.EntityList
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keys from %s: %w", file, err)
+		}
+		keyring = append(keyring, keys...)
+	}
+
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("no keys found in %s", path)
+	}
+	return keyring, nil
+}
+
+// ReadSigningKey reads the armored OpenPGP private key in the file at path,
+// decrypting it with passphrase if it is encrypted.
+func ReadSigningKey(path, passphrase string) (*openpgp.Entity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("expected a single signing key in %s, found %d", path, len(keys))
+	}
+
+	key := keys[0]
+	if key.PrivateKey == nil {
+		return nil, fmt.Errorf("%s does not hold a private key", path)
+	}
+	if key.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, errors.New("signing key is encrypted and no passphrase was given")
+		}
+		if err := key.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// Sign returns the armored detached signature of data by signer.
+func Sign(signer *openpgp.Entity, data []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, signer, bytes.NewReader(data), nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Verify checks the armored detached signature of data against the keys of
+// keyring, returning the key which made it.
+func Verify(keyring openpgp.EntityList, data []byte, signature string) (*openpgp.Entity, error) {
+	if signature == "" {
+		return nil, ErrNotSigned
+	}
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(data), strings.NewReader(signature), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	return signer, nil
+}
+
+// VerifyCommit checks the signature of the HEAD commit of the git repository
+// at repoPath against the keys of keyring, returning the key which made it.
+func VerifyCommit(keyring openpgp.EntityList, repoPath string) (*openpgp.Entity, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read cloned repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("could not get ref of cloned repository: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if commit.PGPSignature == "" {
+		return nil, fmt.Errorf("commit %s: %w", commit.Hash, ErrNotSigned)
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return nil, err
+	}
+	r, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, r, strings.NewReader(commit.PGPSignature), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature of commit %s: %w", commit.Hash, err)
+	}
+	return signer, nil
+}
+
+// Identity returns a description of key for display, being the name of its
+// primary identity along with its key ID.
+func Identity(key *openpgp.Entity) string {
+	id := key.PrimaryKey.KeyIdString()
+	if identity := key.PrimaryIdentity(); identity != nil {
+		return fmt.Sprintf("%s (%s)", identity.Name, id)
+	}
+	return id
+}
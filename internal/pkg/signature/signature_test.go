@@ -0,0 +1,145 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package signature
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/signature/signaturetest"
+)
+
+func TestSignVerify(t *testing.T) {
+	ci.Parallel(t)
+
+	signer := signaturetest.NewKey(t, "signer")
+	other := signaturetest.NewKey(t, "other")
+	data := []byte("sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae")
+
+	sig, err := Sign(signer, data)
+	must.NoError(t, err)
+
+	key, err := Verify(openpgp.EntityList{other, signer}, data, sig)
+	must.NoError(t, err)
+	must.Eq(t, signer.PrimaryKey.KeyId, key.PrimaryKey.KeyId)
+	must.Eq(t, "signer <signer@example.com> ("+signer.PrimaryKey.KeyIdString()+")", Identity(key))
+
+	_, err = Verify(openpgp.EntityList{other}, data, sig)
+	must.ErrorContains(t, err, "invalid signature")
+
+	_, err = Verify(openpgp.EntityList{signer}, []byte("tampered"), sig)
+	must.ErrorContains(t, err, "invalid signature")
+
+	_, err = Verify(openpgp.EntityList{signer}, data, "")
+	must.ErrorIs(t, err, ErrNotSigned)
+}
+
+func TestReadKeyRing(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	first := signaturetest.NewKey(t, "first")
+	second := signaturetest.NewKey(t, "second")
+	signaturetest.WritePublicKey(t, first, filepath.Join(dir, "first.asc"))
+	signaturetest.WritePublicKey(t, second, filepath.Join(dir, "second.asc"))
+
+	keyring, err := ReadKeyRing(filepath.Join(dir, "first.asc"))
+	must.NoError(t, err)
+	must.Len(t, 1, keyring)
+	must.Nil(t, keyring[0].PrivateKey)
+
+	keyring, err = ReadKeyRing(dir)
+	must.NoError(t, err)
+	must.Len(t, 2, keyring)
+
+	_, err = ReadKeyRing(t.TempDir())
+	must.ErrorContains(t, err, "no keys found")
+
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.asc"), []byte("not a key"), 0644))
+	_, err = ReadKeyRing(dir)
+	must.ErrorContains(t, err, "failed to read keys from")
+}
+
+func TestReadSigningKey(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	key := signaturetest.NewKey(t, "signer")
+	signaturetest.WritePrivateKey(t, key, filepath.Join(dir, "private.asc"))
+	signaturetest.WritePublicKey(t, key, filepath.Join(dir, "public.asc"))
+
+	signer, err := ReadSigningKey(filepath.Join(dir, "private.asc"), "")
+	must.NoError(t, err)
+	must.NotNil(t, signer.PrivateKey)
+
+	_, err = ReadSigningKey(filepath.Join(dir, "public.asc"), "")
+	must.ErrorContains(t, err, "does not hold a private key")
+
+	must.NoError(t, key.PrivateKey.Encrypt([]byte("secret")))
+	for _, subkey := range key.Subkeys {
+		must.NoError(t, subkey.PrivateKey.Encrypt([]byte("secret")))
+	}
+	signaturetest.WritePrivateKey(t, key, filepath.Join(dir, "encrypted.asc"))
+
+	_, err = ReadSigningKey(filepath.Join(dir, "encrypted.asc"), "")
+	must.ErrorContains(t, err, "no passphrase was given")
+
+	_, err = ReadSigningKey(filepath.Join(dir, "encrypted.asc"), "wrong")
+	must.ErrorContains(t, err, "failed to decrypt signing key")
+
+	signer, err = ReadSigningKey(filepath.Join(dir, "encrypted.asc"), "secret")
+	must.NoError(t, err)
+	_, err = Sign(signer, []byte("data"))
+	must.NoError(t, err)
+}
+
+func TestVerifyCommit(t *testing.T) {
+	ci.Parallel(t)
+
+	signer := signaturetest.NewKey(t, "signer")
+	other := signaturetest.NewKey(t, "other")
+
+	commit := func(t *testing.T, key *openpgp.Entity) string {
+		dir := t.TempDir()
+		repo, err := git.PlainInit(dir, false)
+		must.NoError(t, err)
+		must.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("registry"), 0644))
+		w, err := repo.Worktree()
+		must.NoError(t, err)
+		_, err = w.Add(".")
+		must.NoError(t, err)
+		_, err = w.Commit("Initial Commit", &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "Test User",
+				Email: "test@example.com",
+				When:  time.Now(),
+			},
+			SignKey: key,
+		})
+		must.NoError(t, err)
+		return dir
+	}
+
+	signed := commit(t, signer)
+	key, err := VerifyCommit(openpgp.EntityList{signer}, signed)
+	must.NoError(t, err)
+	must.Eq(t, signer.PrimaryKey.KeyId, key.PrimaryKey.KeyId)
+
+	_, err = VerifyCommit(openpgp.EntityList{other}, signed)
+	must.ErrorContains(t, err, "invalid signature of commit")
+
+	_, err = VerifyCommit(openpgp.EntityList{signer}, commit(t, nil))
+	must.ErrorIs(t, err, ErrNotSigned)
+
+	_, err = VerifyCommit(openpgp.EntityList{signer}, t.TempDir())
+	must.ErrorContains(t, err, "could not read cloned repository")
+}
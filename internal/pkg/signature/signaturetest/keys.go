@@ -0,0 +1,50 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package signaturetest provides OpenPGP keys for testing code which signs
+// and verifies registries and packs.
+package signaturetest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/shoenig/test/must"
+)
+
+// NewKey generates a key for the named identity.
+func NewKey(t testing.TB, name string) *openpgp.Entity {
+	t.Helper()
+	key, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	must.NoError(t, err)
+	return key
+}
+
+// WritePublicKey writes the armored public key of key to path.
+func WritePublicKey(t testing.TB, key *openpgp.Entity, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	must.NoError(t, err)
+	defer f.Close()
+
+	w, err := armor.Encode(f, openpgp.PublicKeyType, nil)
+	must.NoError(t, err)
+	must.NoError(t, key.Serialize(w))
+	must.NoError(t, w.Close())
+}
+
+// WritePrivateKey writes the armored private key of key to path, encrypted
+// if its private keys have been encrypted.
+func WritePrivateKey(t testing.TB, key *openpgp.Entity, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	must.NoError(t, err)
+	defer f.Close()
+
+	w, err := armor.Encode(f, openpgp.PrivateKeyType, nil)
+	must.NoError(t, err)
+	must.NoError(t, key.SerializePrivateWithoutSigning(w, nil))
+	must.NoError(t, w.Close())
+}
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/hashicorp/nomad-pack/internal/pkg/variable/source"
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 )
@@ -52,6 +54,12 @@ type ParserConfig struct {
 	// external variable sources (Consul, Vault, Nomad).
 	ExternalSourceConfigs []source.SourceConfig
 
+	// ExternalSourceTimeout is the maximum time each external source may take
+	// to fetch the variables of a pack. The sources and packs are fetched
+	// concurrently, so it also bounds the time taken to resolve them all. When
+	// zero, a default of 30 seconds is used.
+	ExternalSourceTimeout time.Duration
+
 	// IgnoreMissingVars determines whether we error or not on variable overrides
 	// that don't have corresponding vars in the pack.
 	IgnoreMissingVars bool
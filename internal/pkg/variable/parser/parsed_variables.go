@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors/packdiags"
 	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser/config"
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/source"
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
 	"golang.org/x/exp/maps"
@@ -22,6 +23,8 @@ type ParsedVariables struct {
 	v1Vars    map[string]map[string]*variables.Variable
 	v2Vars    map[pack.ID]map[variables.ID]*variables.Variable
 	nomadVars map[pack.ID][]*variables.NomadVariable
+	leases    []source.Lease
+	secrets   map[pack.ID]map[variables.ID]struct{}
 	Metadata  *pack.Metadata
 	version   *config.ParserVersion
 }
@@ -80,6 +83,25 @@ func (pv *ParsedVariables) GetNomadVars() map[pack.ID][]*variables.NomadVariable
 	return pv.nomadVars
 }
 
+// Leases returns the leases of the secrets that external sources read variable
+// values from, sorted by when they expire.
+func (pv *ParsedVariables) Leases() []source.Lease {
+	return pv.leases
+}
+
+// IsSecret returns whether the value of the variable of the pack was read
+// from an external source of secrets, such as Vault.
+func (pv *ParsedVariables) IsSecret(packID pack.ID, name variables.ID) bool {
+	_, ok := pv.secrets[packID][name]
+	return ok
+}
+
+// HasSecrets returns whether the value of any variable was read from an
+// external source of secrets. The rendered templates then hold secret values.
+func (pv *ParsedVariables) HasSecrets() bool {
+	return len(pv.secrets) > 0
+}
+
 // asV2Vars traverses the v1-style and converts it into an equivalent single
 // level v2 variable map
 func asV2Vars(in map[string]map[string]*variables.Variable) map[pack.ID]map[variables.ID]*variables.Variable {
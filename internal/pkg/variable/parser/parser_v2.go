@@ -7,8 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
@@ -28,10 +31,12 @@ import (
 )
 
 const (
-	// externalSourceTimeout is the maximum time allowed for fetching variables
-	// from external sources (Consul, Vault, Nomad). This prevents hanging on
-	// slow or unresponsive external services.
-	externalSourceTimeout = 30 * time.Second
+	// defaultExternalSourceTimeout is the maximum time allowed for each
+	// external source (Consul, Vault, Nomad, HTTP) to fetch the variables of a
+	// pack, unless the config sets another. As the sources and packs are
+	// fetched concurrently, it also bounds the time taken to resolve them all.
+	// This prevents hanging on slow or unresponsive external services.
+	defaultExternalSourceTimeout = 30 * time.Second
 )
 
 type ParserV2 struct {
@@ -119,9 +124,14 @@ func (p *ParserV2) Parse() (*ParsedVariables, hcl.Diagnostics) {
 	p.sourceRegistry.Register(source.NewEnvSource(source.PriorityEnv, p.envOverrideVars))
 	p.sourceRegistry.Register(source.NewFileSource(source.PriorityFile, p.fileOverrideVars))
 
-	// Create and register external sources (Consul, Vault, Nomad) from configs if provided.
+	// Create and register external sources (Consul, Vault, Nomad, file, HTTP) from configs if provided.
 	// This is where we lazily build the actual sources from their parsed
 	// configs, avoiding remote connections for commands that never resolve.
+	externalSources := make([]source.VariableSource, 0, len(p.cfg.ExternalSourceConfigs))
+	timeout := p.cfg.ExternalSourceTimeout
+	if timeout <= 0 {
+		timeout = defaultExternalSourceTimeout
+	}
 	for _, sc := range p.cfg.ExternalSourceConfigs {
 		src, err := sc.Build()
 		if err != nil {
@@ -132,19 +142,52 @@ func (p *ParserV2) Parse() (*ParsedVariables, hcl.Diagnostics) {
 			})
 		}
 
-		p.sourceRegistry.Register(src)
+		// A source scoped to a pack that is not part of this render would
+		// silently never apply, which is almost always a mistyped pack ID.
+		if scoped, ok := src.(*source.ScopedSource); ok {
+			if _, ok := p.rootVars[scoped.Pack()]; !ok {
+				return nil, diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unknown pack for external source",
+					Detail:   fmt.Sprintf("Pack %q, to which a source is scoped, is not the pack being rendered or one of its dependencies.", scoped.Pack()),
+				})
+			}
+		}
+
+		// The variables read from sources of secrets are recorded, so they
+		// can be kept out of what is stored in plain form.
+		if source.IsSecret(sc) {
+			src = source.NewSecretSource(src)
+		}
+
+		// Each external source has its own timeout, so one which hangs fails
+		// on its own while the others, fetched concurrently, complete.
+		p.sourceRegistry.RegisterWithTimeout(src, timeout)
+		externalSources = append(externalSources, src)
 	}
 
 	p.sourceRegistry.Register(source.NewCLISource(source.PriorityCLI, p.flagOverrideVars))
 
-	// Use context with timeout to prevent hanging on external sources
-	ctx, cancel := context.WithTimeout(context.Background(), externalSourceTimeout)
-	defer cancel()
+	// Resolve the variables of every pack from all sources using the
+	// registry. The packs are resolved concurrently, so an external source
+	// which hangs holds up the render by its timeout once, rather than once
+	// for each of the pack's dependencies.
+	packNames := slices.Sorted(maps.Keys(p.rootVars))
+	resolved := make([][]*variables.Variable, len(packNames))
+	resolveErrs := make([]error, len(packNames))
+
+	var wg sync.WaitGroup
+	for i, packName := range packNames {
+		wg.Go(func() {
+			// Pass the pack's schema to enable schema-aware type conversion
+			resolved[i], resolveErrs[i] = p.sourceRegistry.Resolve(context.Background(), packName, p.rootVars[packName])
+		})
+	}
+	wg.Wait()
 
-	// Resolve and merge variables from all sources using the registry
-	for packName, packSchema := range p.rootVars {
-		// Pass the pack's schema to enable schema-aware type conversion
-		resolvedVars, resolveErr := p.sourceRegistry.Resolve(ctx, packName, packSchema)
+	// Merge the resolved variables in pack order, so diagnostics are stable.
+	for i, packName := range packNames {
+		resolvedVars, resolveErr := resolved[i], resolveErrs[i]
 		if resolveErr != nil {
 			diags = diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -184,6 +227,38 @@ func (p *ParserV2) Parse() (*ParsedVariables, hcl.Diagnostics) {
 	out.LoadV2Result(p.rootVars)
 	out.nomadVars = p.nomadVars
 
+	// Collect the leases of any secrets the external sources read, so that
+	// callers can warn of values which will stop being valid.
+	for _, src := range externalSources {
+		if ls, ok := src.(source.LeaseSource); ok {
+			out.leases = append(out.leases, ls.Leases()...)
+		}
+	}
+	slices.SortStableFunc(out.leases, func(a, b source.Lease) int {
+		return a.Expires.Compare(b.Expires)
+	})
+
+	// Mark the variables read from sources of secrets. A variable is marked
+	// even if a source of higher priority overrode its value, erring on the
+	// side of keeping values hidden.
+	for _, src := range externalSources {
+		ss, ok := src.(*source.SecretSource)
+		if !ok {
+			continue
+		}
+		for packID := range p.rootVars {
+			for _, name := range ss.Variables(packID) {
+				if out.secrets == nil {
+					out.secrets = make(map[pack.ID]map[variables.ID]struct{})
+				}
+				if out.secrets[packID] == nil {
+					out.secrets[packID] = make(map[variables.ID]struct{})
+				}
+				out.secrets[packID][name] = struct{}{}
+			}
+		}
+	}
+
 	return out, diags
 }
 
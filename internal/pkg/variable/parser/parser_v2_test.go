@@ -4,12 +4,17 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/nomad-pack/internal/pkg/loader"
@@ -454,6 +459,187 @@ func TestParserV2_VariableOverrides(t *testing.T) {
 	}
 }
 
+func TestParserV2_ExternalSourcesPerPack(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	must.NoError(t, os.MkdirAll(filepath.Join(dir, "example", "dep"), 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "example", "vars.json"), []byte(`{"input": "parent"}`), 0o644))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "example", "dep", "vars.json"), []byte(`{"input": "dep"}`), 0o644))
+	scoped := filepath.Join(dir, "scoped.json")
+	must.NoError(t, os.WriteFile(scoped, []byte(`{"input": "scoped"}`), 0o644))
+
+	newParser := func(configs ...source.SourceConfig) *ParserV2 {
+		p := NewTestInputParserV2()
+		p.rootVars["example.dep"] = map[variables.ID]*variables.Variable{
+			"input": NewStringVariableV2("input", "root", "rootVars"),
+		}
+		p.cfg.ExternalSourceConfigs = configs
+		return p
+	}
+
+	templated := source.FileSourceConfig{
+		Priority: source.PriorityExternalBase,
+		Path:     filepath.Join(dir, "{pack}", "vars.json"),
+	}
+
+	t.Run("templated path", func(t *testing.T) {
+		pv, diags := newParser(templated).Parse()
+		must.SliceEmpty(t, diags)
+		must.Eq(t, "parent", pv.v2Vars["example"]["input"].Value.AsString())
+		must.Eq(t, "dep", pv.v2Vars["example.dep"]["input"].Value.AsString())
+	})
+
+	t.Run("scoped source", func(t *testing.T) {
+		pv, diags := newParser(templated, source.ScopedSourceConfig{
+			Pack:   "example.dep",
+			Source: source.FileSourceConfig{Priority: source.PriorityExternalBase + 1, Path: scoped},
+		}).Parse()
+		must.SliceEmpty(t, diags)
+		must.Eq(t, "parent", pv.v2Vars["example"]["input"].Value.AsString())
+		must.Eq(t, "scoped", pv.v2Vars["example.dep"]["input"].Value.AsString())
+	})
+
+	t.Run("unknown pack", func(t *testing.T) {
+		_, diags := newParser(source.ScopedSourceConfig{
+			Pack:   "example.missing",
+			Source: source.FileSourceConfig{Priority: source.PriorityExternalBase, Path: scoped},
+		}).Parse()
+		must.True(t, diags.HasErrors())
+		must.StrContains(t, diags.Error(), `Pack "example.missing", to which a source is scoped`)
+	})
+}
+
+// leasedSourceConfig builds a source which reads every variable of the
+// schema from a secret with a lease that expires after the given duration.
+type leasedSourceConfig struct {
+	name    string
+	expires time.Duration
+}
+
+func (c leasedSourceConfig) Build() (source.VariableSource, error) {
+	return &leasedSource{cfg: c}, nil
+}
+
+type leasedSource struct {
+	cfg    leasedSourceConfig
+	mu     sync.Mutex
+	leases []source.Lease
+}
+
+func (s *leasedSource) Name() string  { return s.cfg.name }
+func (s *leasedSource) Priority() int { return source.PriorityExternalBase }
+func (s *leasedSource) Leases() []source.Lease {
+	return s.leases
+}
+
+func (s *leasedSource) Fetch(_ context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	lease := source.Lease{Source: s.cfg.name, Pack: packID, Expires: time.Now().Add(s.cfg.expires)}
+	var vars []*variables.Variable
+	for name := range schema {
+		vars = append(vars, NewStringVariableV2(string(name), s.cfg.name, s.cfg.name))
+		lease.Variables = append(lease.Variables, name)
+	}
+	s.mu.Lock()
+	s.leases = append(s.leases, lease)
+	s.mu.Unlock()
+	return vars, nil
+}
+
+// hangingSourceConfig builds a source which never responds, returning only
+// once its context is done.
+type hangingSourceConfig struct{}
+
+func (hangingSourceConfig) Build() (source.VariableSource, error) { return hangingSource{}, nil }
+
+type hangingSource struct{}
+
+func (hangingSource) Name() string  { return "hanging" }
+func (hangingSource) Priority() int { return source.PriorityExternalBase }
+func (hangingSource) Fetch(ctx context.Context, _ pack.ID, _ map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestParserV2_ResolveTimeout(t *testing.T) {
+	ci.Parallel(t)
+
+	// The pack has several dependencies, all of which read from a source
+	// which hangs.
+	p := NewTestInputParserV2()
+	for _, dep := range []pack.ID{"example.a", "example.b", "example.c"} {
+		p.rootVars[dep] = map[variables.ID]*variables.Variable{
+			"input": {Name: "input", Type: cty.String, Value: cty.StringVal("root")},
+		}
+	}
+	p.cfg.ExternalSourceConfigs = []source.SourceConfig{hangingSourceConfig{}}
+	p.cfg.ExternalSourceTimeout = 200 * time.Millisecond
+
+	start := time.Now()
+	_, diags := p.Parse()
+	elapsed := time.Since(start)
+
+	// Every pack fails, but the render is held up by the timeout once
+	// rather than once for each pack.
+	must.Len(t, 4, diags)
+	for _, diag := range diags {
+		must.StrContains(t, diag.Detail, "timed out after 200ms")
+	}
+	must.Less(t, 600*time.Millisecond, elapsed)
+}
+
+func TestParserV2_Leases(t *testing.T) {
+	ci.Parallel(t)
+
+	p := NewTestInputParserV2()
+	p.cfg.ExternalSourceConfigs = []source.SourceConfig{
+		leasedSourceConfig{name: "later", expires: 2 * time.Hour},
+		source.ScopedSourceConfig{
+			Pack:   "example",
+			Source: leasedSourceConfig{name: "sooner", expires: time.Hour},
+		},
+	}
+
+	pv, diags := p.Parse()
+	must.SliceEmpty(t, diags)
+
+	leases := pv.Leases()
+	must.Len(t, 2, leases)
+	must.Eq(t, "sooner", leases[0].Source)
+	must.Eq(t, "later", leases[1].Source)
+	must.Eq(t, []variables.ID{"input"}, leases[0].Variables)
+}
+
+func TestParserV2_Secrets(t *testing.T) {
+	ci.Parallel(t)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v1/secret/data/app" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"input":"s3cret"},"metadata":{"version":1}}}`))
+	}))
+	t.Cleanup(vault.Close)
+
+	p := NewTestInputParserV2()
+	p.cfg.ExternalSourceConfigs = []source.SourceConfig{
+		source.VaultSourceConfig{Priority: source.PriorityExternalBase, Address: vault.URL, Mount: "secret", Paths: []string{"app"}},
+	}
+	pv, diags := p.Parse()
+	must.SliceEmpty(t, diags)
+	must.Eq(t, "s3cret", pv.GetVars()["example"]["input"].Value.AsString())
+	must.True(t, pv.IsSecret("example", "input"))
+
+	p = NewTestInputParserV2()
+	p.cfg.ExternalSourceConfigs = []source.SourceConfig{leasedSourceConfig{name: "plain"}}
+	pv, diags = p.Parse()
+	must.SliceEmpty(t, diags)
+	must.False(t, pv.IsSecret("example", "input"))
+}
+
 type testParserV2Option func(*ParserV2)
 
 func WithEnvVar(key, value string) testParserV2Option {
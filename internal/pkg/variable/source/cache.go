@@ -0,0 +1,337 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"golang.org/x/crypto/argon2"
+)
+
+// SourceCache stores the variable values fetched from external sources on
+// disk, so that they can be reused for a time without contacting the sources
+// again, and replayed when the sources cannot be reached at all.
+type SourceCache struct {
+	dir     string
+	ttl     time.Duration
+	offline bool
+	key     []byte
+}
+
+// NewSourceCache returns a cache storing values in dir. Values fetched less
+// than ttl ago are used instead of fetching them again; a ttl of zero always
+// fetches values, but still caches them. When offline is set, no source is
+// contacted, and the last cached values are used however old they are.
+//
+// When key is not empty, cached values are encrypted with a key derived from
+// it. It is required to cache the values of sources holding secrets, such as
+// Vault.
+func NewSourceCache(dir string, ttl time.Duration, offline bool, key string) *SourceCache {
+	c := &SourceCache{dir: dir, ttl: ttl, offline: offline}
+	if key != "" {
+		c.key = []byte(key)
+	}
+	return c
+}
+
+// cacheFile is the format of a file of the cache. Data holds a cacheEntry
+// encoded as JSON, which is encrypted when Encrypted is set.
+type cacheFile struct {
+	Encrypted bool   `json:"encrypted"`
+	Salt      []byte `json:"salt,omitempty"`
+	Nonce     []byte `json:"nonce,omitempty"`
+	Data      []byte `json:"data"`
+}
+
+// cacheEntry holds the values fetched from a source for a pack.
+type cacheEntry struct {
+	Source    string           `json:"source"`
+	Pack      pack.ID          `json:"pack"`
+	Fetched   time.Time        `json:"fetched"`
+	Variables []cachedVariable `json:"variables"`
+	Leases    []Lease          `json:"leases,omitempty"`
+}
+
+// cachedVariable is a variable value with its type, encoded as cty JSON.
+type cachedVariable struct {
+	Name  variables.ID    `json:"name"`
+	Type  json.RawMessage `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// entryName returns the name of the file caching the values of the source
+// for the pack.
+func entryName(source string, packID pack.ID) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + string(packID)))
+	return hex.EncodeToString(sum[:])
+}
+
+// load returns the cached values of the source for the pack, or nil if none
+// are cached.
+func (c *SourceCache) load(source string, packID pack.ID) (*cacheEntry, error) {
+	name := entryName(source, packID)
+	raw, err := os.ReadFile(filepath.Join(c.dir, name+".json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cached values: %w", err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode cached values: %w", err)
+	}
+
+	data := file.Data
+	if file.Encrypted {
+		if c.key == nil {
+			return nil, errors.New("cached values are encrypted, but no cache key was given")
+		}
+		aead, err := c.cipher(file.Salt)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = aead.Open(nil, file.Nonce, file.Data, []byte(name)); err != nil {
+			return nil, errors.New("failed to decrypt cached values; the cache key may have changed")
+		}
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode cached values: %w", err)
+	}
+	return &entry, nil
+}
+
+// store caches the values of the entry, encrypting them if the cache has a
+// key. The file is replaced atomically so a concurrent render never reads a
+// partial entry.
+func (c *SourceCache) store(entry *cacheEntry) error {
+	name := entryName(entry.Source, entry.Pack)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached values: %w", err)
+	}
+
+	file := cacheFile{Data: data}
+	if c.key != nil {
+		file.Encrypted = true
+		file.Salt = make([]byte, 16)
+		if _, err := rand.Read(file.Salt); err != nil {
+			return err
+		}
+		aead, err := c.cipher(file.Salt)
+		if err != nil {
+			return err
+		}
+		file.Nonce = make([]byte, aead.NonceSize())
+		if _, err := rand.Read(file.Nonce); err != nil {
+			return err
+		}
+		// The name is authenticated, so an entry cannot be passed off as
+		// the values of another source or pack.
+		file.Data = aead.Seal(nil, file.Nonce, data, []byte(name))
+	}
+
+	raw, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached values: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cached values: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cached values: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cached values: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name+".json")); err != nil {
+		return fmt.Errorf("failed to write cached values: %w", err)
+	}
+	return nil
+}
+
+// cipher returns the cipher encrypting entries with the given salt.
+func (c *SourceCache) cipher(salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey(c.key, salt, 1, 64*1024, 4, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// CachedSource caches the values fetched from a source in a SourceCache.
+type CachedSource struct {
+	source VariableSource
+	cache  *SourceCache
+
+	// leases are the leases of the secrets the values returned by Fetch were
+	// read from, whether fetched or cached, guarded by mu.
+	mu     sync.Mutex
+	leases []Lease
+}
+
+// NewCachedSource creates a source which fetches from src through the cache.
+// secret marks sources whose values may be secrets, which are only cached
+// encrypted, so the cache must have a key.
+func NewCachedSource(cache *SourceCache, src VariableSource, secret bool) (*CachedSource, error) {
+	if secret && cache.key == nil {
+		return nil, fmt.Errorf("caching the values of %s requires a cache key to encrypt them with", src.Name())
+	}
+	return &CachedSource{source: src, cache: cache}, nil
+}
+
+// Name returns the unique identifier for this source.
+func (c *CachedSource) Name() string {
+	return c.source.Name()
+}
+
+// Priority returns the precedence level of the cached source.
+func (c *CachedSource) Priority() int {
+	return c.source.Priority()
+}
+
+// Leases returns the leases of the secrets the values returned by Fetch were
+// read from. The leases of cached values are those recorded when they were
+// fetched, so they may have already expired.
+func (c *CachedSource) Leases() []Lease {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.leases)
+}
+
+// Fetch returns the cached values of the pack if they were fetched within the
+// TTL of the cache, or the cache is offline. Otherwise it fetches the values
+// from the source and caches them.
+func (c *CachedSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	entry, err := c.cache.load(c.source.Name(), packID)
+
+	if c.cache.offline {
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay cached values of pack %s: %w", packID, err)
+		}
+		if entry == nil {
+			return nil, fmt.Errorf("no values of pack %s are cached", packID)
+		}
+		return c.replay(entry, schema)
+	}
+
+	// An unreadable entry is fetched again and replaced, rather than failing
+	// a render which can reach the source.
+	if err == nil && entry != nil && c.cache.ttl > 0 && time.Since(entry.Fetched) < c.cache.ttl {
+		return c.replay(entry, schema)
+	}
+
+	vars, err := c.source.Fetch(ctx, packID, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &cacheEntry{
+		Source:  c.source.Name(),
+		Pack:    packID,
+		Fetched: time.Now().UTC(),
+	}
+	for _, v := range vars {
+		ty, err := ctyjson.MarshalType(v.Value.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to cache value of %s: %w", v.Name, err)
+		}
+		value, err := ctyjson.Marshal(v.Value, v.Value.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to cache value of %s: %w", v.Name, err)
+		}
+		entry.Variables = append(entry.Variables, cachedVariable{Name: v.Name, Type: ty, Value: value})
+	}
+	if ls, ok := c.source.(LeaseSource); ok {
+		for _, lease := range ls.Leases() {
+			if lease.Pack == packID {
+				entry.Leases = append(entry.Leases, lease)
+			}
+		}
+	}
+
+	if err := c.cache.store(entry); err != nil {
+		return nil, fmt.Errorf("failed to cache values of pack %s: %w", packID, err)
+	}
+
+	c.addLeases(entry.Leases)
+	return vars, nil
+}
+
+// replay returns the cached values of variables in schema, converted to the
+// type each is declared with, as the pack may have changed since they were
+// cached.
+func (c *CachedSource) replay(entry *cacheEntry, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	vars := make([]*variables.Variable, 0, len(entry.Variables))
+	for _, cached := range entry.Variables {
+		schemaVar, inSchema := schema[cached.Name]
+		if !inSchema {
+			continue
+		}
+
+		ty, err := ctyjson.UnmarshalType(cached.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode cached value of %s: %w", cached.Name, err)
+		}
+		value, err := ctyjson.Unmarshal(cached.Value, ty)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode cached value of %s: %w", cached.Name, err)
+		}
+
+		expectedType := schemaVar.ConstraintType
+		if expectedType == cty.NilType {
+			expectedType = schemaVar.Type
+		}
+		if value, err = convert.Convert(value, expectedType); err != nil {
+			return nil, fmt.Errorf("cached value of %s cannot be converted to %s: %w", cached.Name, expectedType.FriendlyName(), err)
+		}
+
+		vars = append(vars, &variables.Variable{
+			Name:  cached.Name,
+			Value: value,
+			Type:  value.Type(),
+		})
+	}
+
+	c.addLeases(entry.Leases)
+	return vars, nil
+}
+
+func (c *CachedSource) addLeases(leases []Lease) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leases = append(c.leases, leases...)
+}
@@ -0,0 +1,223 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// countingSource returns its values, and a lease for them, counting how many
+// times it is fetched from. It fails when err is set.
+type countingSource struct {
+	values  map[variables.ID]cty.Value
+	err     error
+	fetches int
+	leases  []Lease
+}
+
+func (s *countingSource) Name() string  { return "counting(test)" }
+func (s *countingSource) Priority() int { return PriorityExternalBase }
+func (s *countingSource) Leases() []Lease {
+	return s.leases
+}
+
+func (s *countingSource) Fetch(_ context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	s.fetches++
+	if s.err != nil {
+		return nil, s.err
+	}
+	var vars []*variables.Variable
+	for name, value := range s.values {
+		if _, ok := schema[name]; ok {
+			vars = append(vars, &variables.Variable{Name: name, Value: value, Type: value.Type()})
+		}
+	}
+	s.leases = append(s.leases, Lease{Source: s.Name(), Pack: packID, Path: "creds/app", Variables: []variables.ID{"password"}})
+	return vars, nil
+}
+
+func cacheTestSchema() map[variables.ID]*variables.Variable {
+	return map[variables.ID]*variables.Variable{
+		"password": {Name: "password", Type: cty.String},
+		"replicas": {Name: "replicas", Type: cty.Number},
+	}
+}
+
+func TestCachedSource(t *testing.T) {
+	ci.Parallel(t)
+
+	packID := pack.ID("webapp")
+	values := map[variables.ID]cty.Value{
+		"password": cty.StringVal("s3cret"),
+		"replicas": cty.NumberIntVal(3),
+	}
+
+	t.Run("values are reused within the ttl", func(t *testing.T) {
+		inner := &countingSource{values: values}
+		src, err := NewCachedSource(NewSourceCache(t.TempDir(), time.Hour, false, ""), inner, false)
+		must.NoError(t, err)
+
+		for range 2 {
+			vars, err := src.Fetch(t.Context(), packID, cacheTestSchema())
+			must.NoError(t, err)
+			must.Len(t, 2, vars)
+		}
+		must.Eq(t, 1, inner.fetches)
+		must.Len(t, 2, src.Leases())
+	})
+
+	t.Run("values are fetched again without a ttl", func(t *testing.T) {
+		inner := &countingSource{values: values}
+		src, err := NewCachedSource(NewSourceCache(t.TempDir(), 0, false, ""), inner, false)
+		must.NoError(t, err)
+
+		for range 2 {
+			_, err := src.Fetch(t.Context(), packID, cacheTestSchema())
+			must.NoError(t, err)
+		}
+		must.Eq(t, 2, inner.fetches)
+	})
+
+	t.Run("offline replays cached values", func(t *testing.T) {
+		dir := t.TempDir()
+		online := &countingSource{values: values}
+		src, err := NewCachedSource(NewSourceCache(dir, 0, false, "key"), online, true)
+		must.NoError(t, err)
+		_, err = src.Fetch(t.Context(), packID, cacheTestSchema())
+		must.NoError(t, err)
+
+		unreachable := &countingSource{err: errors.New("connection refused")}
+		src, err = NewCachedSource(NewSourceCache(dir, 0, true, "key"), unreachable, true)
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, cacheTestSchema())
+		must.NoError(t, err)
+		must.Eq(t, 0, unreachable.fetches)
+		got := map[variables.ID]cty.Value{}
+		for _, v := range vars {
+			got[v.Name] = v.Value
+		}
+		must.Eq(t, "s3cret", got["password"].AsString())
+		must.True(t, got["replicas"].RawEquals(cty.NumberIntVal(3)))
+
+		leases := src.Leases()
+		must.Len(t, 1, leases)
+		must.Eq(t, "creds/app", leases[0].Path)
+
+		_, err = src.Fetch(t.Context(), "other", cacheTestSchema())
+		must.ErrorContains(t, err, "no values of pack other are cached")
+	})
+
+	t.Run("cached values are converted to the current schema", func(t *testing.T) {
+		dir := t.TempDir()
+		src, err := NewCachedSource(NewSourceCache(dir, 0, false, ""), &countingSource{values: values}, false)
+		must.NoError(t, err)
+		_, err = src.Fetch(t.Context(), packID, cacheTestSchema())
+		must.NoError(t, err)
+
+		src, err = NewCachedSource(NewSourceCache(dir, 0, true, ""), &countingSource{}, false)
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, map[variables.ID]*variables.Variable{
+			"replicas": {Name: "replicas", Type: cty.String},
+		})
+		must.NoError(t, err)
+		must.Len(t, 1, vars)
+		must.Eq(t, cty.StringVal("3"), vars[0].Value)
+
+		_, err = src.Fetch(t.Context(), packID, map[variables.ID]*variables.Variable{
+			"password": {Name: "password", Type: cty.Number},
+		})
+		must.ErrorContains(t, err, "cached value of password cannot be converted to number")
+	})
+
+	t.Run("fetch errors are not cached", func(t *testing.T) {
+		dir := t.TempDir()
+		src, err := NewCachedSource(NewSourceCache(dir, time.Hour, false, ""), &countingSource{err: errors.New("boom")}, false)
+		must.NoError(t, err)
+		_, err = src.Fetch(t.Context(), packID, cacheTestSchema())
+		must.ErrorContains(t, err, "boom")
+
+		entries, err := os.ReadDir(dir)
+		must.NoError(t, err)
+		must.SliceEmpty(t, entries)
+	})
+}
+
+func TestCachedSource_Encryption(t *testing.T) {
+	ci.Parallel(t)
+
+	packID := pack.ID("webapp")
+	inner := &countingSource{values: map[variables.ID]cty.Value{"password": cty.StringVal("s3cret")}}
+
+	_, err := NewCachedSource(NewSourceCache(t.TempDir(), 0, false, ""), inner, true)
+	must.ErrorContains(t, err, "requires a cache key")
+
+	dir := t.TempDir()
+	src, err := NewCachedSource(NewSourceCache(dir, 0, false, "correct horse"), inner, true)
+	must.NoError(t, err)
+	_, err = src.Fetch(t.Context(), packID, cacheTestSchema())
+	must.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	must.NoError(t, err)
+	must.Len(t, 1, files)
+	raw, err := os.ReadFile(files[0])
+	must.NoError(t, err)
+	must.False(t, strings.Contains(string(raw), "s3cret"))
+	info, err := os.Stat(files[0])
+	must.NoError(t, err)
+	must.Eq(t, os.FileMode(0o600), info.Mode().Perm())
+
+	src, err = NewCachedSource(NewSourceCache(dir, 0, true, "wrong"), inner, true)
+	must.NoError(t, err)
+	_, err = src.Fetch(t.Context(), packID, cacheTestSchema())
+	must.ErrorContains(t, err, "failed to decrypt cached values")
+
+	src, err = NewCachedSource(NewSourceCache(dir, 0, true, ""), inner, false)
+	must.NoError(t, err)
+	_, err = src.Fetch(t.Context(), packID, cacheTestSchema())
+	must.ErrorContains(t, err, "no cache key was given")
+
+	src, err = NewCachedSource(NewSourceCache(dir, 0, true, "correct horse"), inner, true)
+	must.NoError(t, err)
+	vars, err := src.Fetch(t.Context(), packID, cacheTestSchema())
+	must.NoError(t, err)
+	must.Len(t, 1, vars)
+	must.Eq(t, "s3cret", vars[0].Value.AsString())
+}
+
+func TestWithCache(t *testing.T) {
+	ci.Parallel(t)
+
+	cache := NewSourceCache(t.TempDir(), 0, false, "")
+	file := FileSourceConfig{Priority: PriorityExternalBase, Path: "vars.json"}
+
+	cached, ok := WithCache(file, cache).(CachedSourceConfig)
+	must.True(t, ok)
+	must.Eq[SourceConfig](t, file, cached.Source)
+	must.True(t, cached.Cache == cache)
+
+	scoped, ok := WithCache(ScopedSourceConfig{Pack: "web.redis", Source: file}, cache).(ScopedSourceConfig)
+	must.True(t, ok)
+	must.Eq(t, "web.redis", scoped.Pack)
+	cached, ok = scoped.Source.(CachedSourceConfig)
+	must.True(t, ok)
+	must.Eq[SourceConfig](t, file, cached.Source)
+
+	_, err := WithCache(VaultSourceConfig{Mount: "secret", Paths: []string{"app"}}, cache).Build()
+	must.ErrorContains(t, err, "requires a cache key")
+}
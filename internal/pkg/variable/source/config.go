@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
 	nomadapi "github.com/hashicorp/nomad/api"
 	vaultapi "github.com/hashicorp/vault/api"
 )
@@ -37,7 +38,8 @@ type ConsulSourceConfig struct {
 	Address string
 
 	// Path is the Consul KV path under which variables are stored. Each
-	// variable is read from <Path>/<var-name>.
+	// variable is read from <Path>/<var-name>. A PackPlaceholder in the path is
+	// replaced with the ID of the pack being resolved.
 	Path string
 }
 
@@ -53,7 +55,7 @@ func (c ConsulSourceConfig) Build() (VariableSource, error) {
 	return NewConsulSource(c.Priority, apiCfg, c.Path)
 }
 
-// VaultSourceConfig holds the parsed configuration for a Vault KV v2 variable
+// VaultSourceConfig holds the parsed configuration for a Vault variable
 // source.
 type VaultSourceConfig struct {
 	// Priority is the precedence level applied to the built source.
@@ -63,12 +65,18 @@ type VaultSourceConfig struct {
 	// standard Vault environment configuration is used.
 	Address string
 
-	// Mount is the KV v2 engine mount point under which the secret lives.
+	// Engine is the kind of secrets engine mounted at Mount. When empty, a KV
+	// v2 engine is assumed.
+	Engine VaultEngine
+
+	// Mount is the mount point of the secrets engine.
 	Mount string
 
-	// Path is the secret path within the mount. All variables for the pack are
-	// stored as fields of the single secret at <Mount>/<Path>.
-	Path string
+	// Paths are the secret paths within the mount. All variables for the pack
+	// are stored as fields of the secrets at <Mount>/<Path>, merged in order
+	// so a later path overrides an earlier one. The paths may contain a
+	// PackPlaceholder; the mount may not.
+	Paths []string
 }
 
 // Build implements SourceConfig by constructing a VaultSource.
@@ -84,7 +92,7 @@ func (v VaultSourceConfig) Build() (VariableSource, error) {
 		apiCfg.Address = addr
 	}
 
-	return NewVaultSource(v.Priority, apiCfg, v.Mount, v.Path)
+	return NewVaultSource(v.Priority, apiCfg, v.Engine, v.Mount, v.Paths...)
 }
 
 // NomadSourceConfig holds the parsed configuration for a Nomad Variables
@@ -98,7 +106,8 @@ type NomadSourceConfig struct {
 	Address string
 
 	// Path is the Nomad Variable path. All variables for the pack are stored as
-	// items of the single Nomad Variable at this path.
+	// items of the single Nomad Variable at this path, which may contain a
+	// PackPlaceholder.
 	Path string
 }
 
@@ -121,3 +130,92 @@ func (n NomadSourceConfig) Build() (VariableSource, error) {
 
 	return NewNomadSource(n.Priority, apiCfg, n.Path)
 }
+
+// FileSourceConfig holds the parsed configuration for a variable source
+// reading a local HCL, JSON or YAML document.
+type FileSourceConfig struct {
+	// Priority is the precedence level applied to the built source.
+	Priority int
+
+	// Path is the path of the document. Its syntax is taken from its
+	// extension. Each top-level attribute or key maps to one pack variable.
+	// The path may contain a PackPlaceholder.
+	Path string
+}
+
+// Build implements SourceConfig by constructing a DocumentFileSource. The
+// file is not read until Fetch.
+func (f FileSourceConfig) Build() (VariableSource, error) {
+	return NewDocumentFileSource(f.Priority, f.Path)
+}
+
+// HTTPSourceConfig holds the parsed configuration for a variable source
+// reading an HCL, JSON or YAML document over HTTP or HTTPS.
+type HTTPSourceConfig struct {
+	// Priority is the precedence level applied to the built source.
+	Priority int
+
+	// URL is the http:// or https:// URL the document is requested from. Each
+	// top-level attribute or key of the document maps to one pack variable. A
+	// PackPlaceholder may be used in the path or query of the URL.
+	URL string
+}
+
+// Build implements SourceConfig by constructing an HTTPSource. No request is
+// made until Fetch.
+func (h HTTPSourceConfig) Build() (VariableSource, error) {
+	return NewHTTPSource(h.Priority, nil, h.URL)
+}
+
+// ScopedSourceConfig holds the parsed configuration for a variable source that
+// applies only to the variables of a single pack.
+type ScopedSourceConfig struct {
+	// Pack is the full ID of the pack the source applies to, such as
+	// "web.redis" for the "redis" dependency of the "web" pack.
+	Pack pack.ID
+
+	// Source is the configuration of the source being scoped.
+	Source SourceConfig
+}
+
+// Build implements SourceConfig by building the scoped source and wrapping it
+// in a ScopedSource.
+func (s ScopedSourceConfig) Build() (VariableSource, error) {
+	src, err := s.Source.Build()
+	if err != nil {
+		return nil, err
+	}
+	return NewScopedSource(s.Pack, src), nil
+}
+
+// CachedSourceConfig holds the parsed configuration for a variable source
+// whose values are cached in a SourceCache.
+type CachedSourceConfig struct {
+	// Source is the configuration of the source being cached.
+	Source SourceConfig
+
+	// Cache is the cache the values of the source are stored in.
+	Cache *SourceCache
+}
+
+// Build implements SourceConfig by building the cached source and wrapping it
+// in a CachedSource. Values of Vault and Nomad sources are treated as secrets.
+func (c CachedSourceConfig) Build() (VariableSource, error) {
+	src, err := c.Source.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCachedSource(c.Cache, src, IsSecret(c.Source))
+}
+
+// WithCache returns the configuration of cfg with its values cached in cache.
+// A scoped source keeps its scope, so that only the values of its pack are
+// cached.
+func WithCache(cfg SourceConfig, cache *SourceCache) SourceConfig {
+	if scoped, ok := cfg.(ScopedSourceConfig); ok {
+		scoped.Source = CachedSourceConfig{Source: scoped.Source, Cache: cache}
+		return scoped
+	}
+	return CachedSourceConfig{Source: cfg, Cache: cache}
+}
@@ -86,14 +86,14 @@ func (c *ConsulSource) Priority() int {
 //   - Returns an error for an empty value on a non-string variable; empty
 //     values for string variables are kept as ""
 //
-// The parser wraps Fetch in a timeout context, so a slow or unreachable Consul
-// fails the resolve instead of hanging.
-func (c *ConsulSource) Fetch(ctx context.Context, _ pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+// The registry bounds Fetch with the timeout the source was registered with,
+// so a slow or unreachable Consul fails the resolve instead of hanging.
+func (c *ConsulSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
 	// c.path was trimmed of slashes when the source was built; re-add a single
 	// trailing slash to scope the KV list to keys under this path and to strip
-	// each key down to its variable name. The pack ID is intentionally unused —
-	// the KV path is taken as-is from the source URL.
-	path := c.path + "/"
+	// each key down to its variable name. Any {pack} in the path is replaced
+	// with the ID of the pack being resolved.
+	path := expandPackPath(c.path, packID) + "/"
 
 	// List all keys under this path
 	opts := api.QueryOptions{RequireConsistent: true}
@@ -0,0 +1,179 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// documentFormat is the syntax of a document of variable values read by the
+// file and HTTP sources.
+type documentFormat string
+
+const (
+	documentFormatHCL  documentFormat = "hcl"
+	documentFormatJSON documentFormat = "json"
+	documentFormatYAML documentFormat = "yaml"
+)
+
+// documentFormatFromPath returns the format of a document from the extension
+// of its file name or URL path, or "" if the extension is not recognized.
+func documentFormatFromPath(p string) documentFormat {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".hcl":
+		return documentFormatHCL
+	case ".json":
+		return documentFormatJSON
+	case ".yaml", ".yml":
+		return documentFormatYAML
+	default:
+		return ""
+	}
+}
+
+// documentFormatFromContentType returns the format of a document from the
+// media type of an HTTP response, or "" if it is not recognized.
+func documentFormatFromContentType(contentType string) documentFormat {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "application/hcl", "text/hcl":
+		return documentFormatHCL
+	case "application/json":
+		return documentFormatJSON
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return documentFormatYAML
+	default:
+		return ""
+	}
+}
+
+// decodeDocument parses a document of variable values, returning the value of
+// each of its top-level attributes or keys. filename is used in error
+// messages. HCL documents are evaluated without functions or variables, so
+// they may only hold literal values.
+func decodeDocument(format documentFormat, filename string, data []byte) (map[string]cty.Value, error) {
+	switch format {
+	case documentFormatHCL:
+		file, diags := hclsyntax.ParseConfig(data, filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		attrs, diags := file.Body.JustAttributes()
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		values := make(map[string]cty.Value, len(attrs))
+		for name, attr := range attrs {
+			value, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			values[name] = value
+		}
+		return values, nil
+
+	case documentFormatJSON:
+		ty, err := ctyjson.ImpliedType(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		value, err := ctyjson.Unmarshal(data, ty)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		return documentValues(filename, value)
+
+	case documentFormatYAML:
+		value, err := ctyyaml.Unmarshal(data, cty.DynamicPseudoType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		return documentValues(filename, value)
+
+	default:
+		return nil, fmt.Errorf("unsupported format of %s (supported: hcl, json, yaml)", filename)
+	}
+}
+
+// documentValues returns the attributes of the top-level object or map of a
+// JSON or YAML document. An empty document holds no values.
+func documentValues(filename string, value cty.Value) (map[string]cty.Value, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	ty := value.Type()
+	if !ty.IsObjectType() && !ty.IsMapType() {
+		return nil, fmt.Errorf("%s must hold an object of variable values, not a %s", filename, ty.FriendlyName())
+	}
+	return value.AsValueMap(), nil
+}
+
+// documentVariables returns the values of a document for the variables of
+// schema, converted to the type each is declared with. Values for variables
+// not in the schema are skipped.
+//
+// Values are converted as they are by the other external sources, so a
+// document may hold a number, bool, list or object as a JSON string, as it
+// would be written to Consul KV. Typed values are converted directly.
+func documentVariables(source string, values map[string]cty.Value, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	vars := make([]*variables.Variable, 0, len(values))
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		schemaVar, inSchema := schema[variables.ID(name)]
+		if !inSchema {
+			continue
+		}
+
+		// Convert using the variable's constraint type. ConstraintType preserves
+		// optional() attributes.
+		expectedType := schemaVar.ConstraintType
+		if expectedType == cty.NilType {
+			expectedType = schemaVar.Type
+		}
+
+		value, err := convertDocumentValue(source, values[name], expectedType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert value for %s: %w", name, err)
+		}
+
+		vars = append(vars, &variables.Variable{
+			Name:  variables.ID(name),
+			Value: value,
+			Type:  value.Type(),
+		})
+	}
+
+	return vars, nil
+}
+
+// convertDocumentValue converts a value of a document to expectedType. String
+// values for variables of other types are decoded as JSON, as decodeValue
+// does for the other external sources.
+func convertDocumentValue(source string, value cty.Value, expectedType cty.Type) (cty.Value, error) {
+	if value.IsKnown() && !value.IsNull() && value.Type() == cty.String &&
+		expectedType != cty.String && expectedType != cty.DynamicPseudoType {
+		str := value.AsString()
+		if str == "" {
+			return cty.NilVal, fmt.Errorf("empty %s value: a %s value is required", source, expectedType.FriendlyName())
+		}
+		return decodeValue(source, []byte(str), expectedType)
+	}
+
+	converted, err := convert.Convert(value, expectedType)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("converting %s value to %s: %w", source, expectedType.FriendlyName(), err)
+	}
+	return converted, nil
+}
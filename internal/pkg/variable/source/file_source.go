@@ -0,0 +1,80 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// DocumentFileSource fetches variables from a local HCL, JSON or YAML
+// document. Each top-level attribute or key of the document maps to one pack
+// variable. It differs from the source built by NewFileSource, which wraps the
+// values of the --var-file flags.
+type DocumentFileSource struct {
+	name     string
+	priority int
+	path     string
+	format   documentFormat
+}
+
+// NewDocumentFileSource creates a new file variable source reading the
+// document at path, whose syntax is taken from its extension.
+func NewDocumentFileSource(priority int, path string) (*DocumentFileSource, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file source requires a non-empty path")
+	}
+
+	format := documentFormatFromPath(path)
+	if format == "" {
+		return nil, fmt.Errorf("file source requires a .hcl, .json, .yaml or .yml file, got %s", path)
+	}
+
+	return &DocumentFileSource{
+		name:     fmt.Sprintf("file(%s)", path),
+		priority: priority,
+		path:     path,
+		format:   format,
+	}, nil
+}
+
+// Name returns the unique identifier for this source.
+func (f *DocumentFileSource) Name() string {
+	return f.name
+}
+
+// Priority returns the precedence level (higher = higher priority).
+func (f *DocumentFileSource) Priority() int {
+	return f.priority
+}
+
+// Fetch reads the document and returns variables whose names appear in
+// schema, converted to the type each is declared with. See documentVariables
+// for the conversion rules.
+//
+// Returns nil (not an error) when the file does not exist, as the other
+// external sources do for a missing path.
+func (f *DocumentFileSource) Fetch(_ context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	path := expandPackPath(f.path, packID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	values, err := decodeDocument(f.format, path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return documentVariables("file", values, schema)
+}
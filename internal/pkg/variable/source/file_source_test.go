@@ -0,0 +1,154 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// documentTestSchema declares a variable of each kind of type a document
+// value is converted to.
+func documentTestSchema() map[variables.ID]*variables.Variable {
+	return map[variables.ID]*variables.Variable{
+		"region":   {Name: "region", Type: cty.String},
+		"replicas": {Name: "replicas", Type: cty.Number},
+		"enabled":  {Name: "enabled", Type: cty.Bool},
+		"tags":     {Name: "tags", Type: cty.List(cty.String)},
+		"svc": {
+			Name: "svc",
+			Type: cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"port": cty.Number,
+			}),
+			ConstraintType: cty.ObjectWithOptionalAttrs(
+				map[string]cty.Type{"name": cty.String, "port": cty.Number},
+				[]string{"port"},
+			),
+		},
+	}
+}
+
+func writeDocument(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	must.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestDocumentFileSource_Fetch(t *testing.T) {
+	ci.Parallel(t)
+
+	packID := pack.ID("webapp")
+
+	documents := map[string]string{
+		"vars.hcl": `
+region   = "us-west-2"
+replicas = 3
+enabled  = true
+tags     = ["web", "prod"]
+svc      = { name = "api" }
+ignored  = "not in the pack"
+`,
+		"vars.json": `{
+  "region": "us-west-2",
+  "replicas": 3,
+  "enabled": true,
+  "tags": ["web", "prod"],
+  "svc": {"name": "api"},
+  "ignored": "not in the pack"
+}`,
+		"vars.yaml": `
+region: us-west-2
+replicas: 3
+enabled: true
+tags: [web, prod]
+svc:
+  name: api
+ignored: not in the pack
+`,
+		// Values written as strings are decoded as the other external
+		// sources decode them.
+		"strings.json": `{
+  "region": "us-west-2",
+  "replicas": "3",
+  "enabled": "true",
+  "tags": "[\"web\", \"prod\"]",
+  "svc": "{\"name\": \"api\"}"
+}`,
+	}
+
+	for name, content := range documents {
+		t.Run(name, func(t *testing.T) {
+			src, err := NewDocumentFileSource(PriorityExternalBase, writeDocument(t, name, content))
+			must.NoError(t, err)
+
+			vars, err := src.Fetch(t.Context(), packID, documentTestSchema())
+			must.NoError(t, err)
+			must.Len(t, 5, vars)
+
+			got := varsByName(vars)
+			must.Eq(t, "us-west-2", got["region"].Value.AsString())
+			replicas, _ := got["replicas"].Value.AsBigFloat().Int64()
+			must.Eq(t, int64(3), replicas)
+			must.True(t, got["enabled"].Value.True())
+			must.Eq(t, cty.ListVal([]cty.Value{cty.StringVal("web"), cty.StringVal("prod")}), got["tags"].Value)
+			must.Eq(t, "api", got["svc"].Value.GetAttr("name").AsString())
+			must.True(t, got["svc"].Value.GetAttr("port").IsNull())
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		src, err := NewDocumentFileSource(PriorityExternalBase, filepath.Join(t.TempDir(), "missing.json"))
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, documentTestSchema())
+		must.NoError(t, err)
+		must.Len(t, 0, vars)
+	})
+
+	t.Run("empty value for non-string variable is an error", func(t *testing.T) {
+		src, err := NewDocumentFileSource(PriorityExternalBase, writeDocument(t, "vars.json", `{"replicas": ""}`))
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, documentTestSchema())
+		must.ErrorContains(t, err, "empty file value")
+	})
+
+	t.Run("value of the wrong type is an error", func(t *testing.T) {
+		src, err := NewDocumentFileSource(PriorityExternalBase, writeDocument(t, "vars.yaml", "tags: {a: b}\n"))
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, documentTestSchema())
+		must.ErrorContains(t, err, "failed to convert value for tags")
+	})
+
+	t.Run("document must be an object", func(t *testing.T) {
+		src, err := NewDocumentFileSource(PriorityExternalBase, writeDocument(t, "vars.json", `["a"]`))
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, documentTestSchema())
+		must.ErrorContains(t, err, "must hold an object of variable values")
+	})
+
+	t.Run("HCL documents hold literal values", func(t *testing.T) {
+		src, err := NewDocumentFileSource(PriorityExternalBase, writeDocument(t, "vars.hcl", `region = var.region`))
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, documentTestSchema())
+		must.ErrorContains(t, err, "Variables not allowed")
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := NewDocumentFileSource(PriorityExternalBase, "/etc/nomad-pack/vars.txt")
+		must.ErrorContains(t, err, "requires a .hcl, .json, .yaml or .yml file")
+	})
+}
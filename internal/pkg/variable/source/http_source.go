@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// maxHTTPDocumentSize bounds the size of a document read by an HTTPSource, so
+// a misconfigured URL cannot exhaust memory.
+const maxHTTPDocumentSize = 10 << 20
+
+// HTTPSource fetches variables from an HCL, JSON or YAML document served over
+// HTTP or HTTPS. Each top-level attribute or key of the document maps to one
+// pack variable.
+type HTTPSource struct {
+	name     string
+	priority int
+	client   *http.Client
+	url      *url.URL
+}
+
+// NewHTTPSource creates a new HTTP variable source reading the document at
+// rawURL. client can be nil to use a default client. The syntax of the
+// document is taken from the Content-Type of the response, or else from the
+// extension of the URL path. Basic auth credentials may be given as the user
+// info of the URL.
+func NewHTTPSource(priority int, client *http.Client, rawURL string) (*HTTPSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("http source requires an http or https URL, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("http source requires a host")
+	}
+
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &HTTPSource{
+		// Redact the password so it does not appear in error messages.
+		name:     fmt.Sprintf("http(%s)", redactURL(u)),
+		priority: priority,
+		client:   client,
+		url:      u,
+	}, nil
+}
+
+// Name returns the unique identifier for this source.
+func (h *HTTPSource) Name() string {
+	return h.name
+}
+
+// Priority returns the precedence level (higher = higher priority).
+func (h *HTTPSource) Priority() int {
+	return h.priority
+}
+
+// Fetch requests the document and returns variables whose names appear in
+// schema, converted to the type each is declared with. See documentVariables
+// for the conversion rules.
+//
+// Returns nil (not an error) when the server responds 404 Not Found, as the
+// other external sources do for a missing path. Any other non-2xx response is
+// an error.
+func (h *HTTPSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	// Any {pack} in the URL path or query is replaced with the ID of the pack
+	// being resolved.
+	u := *h.url
+	u.Path = expandPackPath(u.Path, packID)
+	u.RawPath = ""
+	u.RawQuery = expandPackPath(u.RawQuery, packID)
+	redacted := u.Redacted()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", redacted, err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml, application/hcl;q=0.9, */*;q=0.1")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", redacted, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to request %s: unexpected status %s", redacted, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", redacted, err)
+	}
+	if len(data) > maxHTTPDocumentSize {
+		return nil, fmt.Errorf("response from %s exceeds %d bytes", redacted, maxHTTPDocumentSize)
+	}
+
+	format := documentFormatFromContentType(resp.Header.Get("Content-Type"))
+	if format == "" {
+		format = documentFormatFromPath(u.Path)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("could not determine the format of %s from its Content-Type %q or extension", redacted, resp.Header.Get("Content-Type"))
+	}
+
+	values, err := decodeDocument(format, redacted, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return documentVariables("HTTP", values, schema)
+}
+
+// redactURL returns u with its password redacted and any {pack} placeholder
+// in its path left unescaped, for use in the name of the source.
+func redactURL(u *url.URL) string {
+	return strings.ReplaceAll(u.Redacted(), url.PathEscape(PackPlaceholder), PackPlaceholder)
+}
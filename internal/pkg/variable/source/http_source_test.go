@@ -0,0 +1,93 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+)
+
+func TestHTTPSource_Fetch(t *testing.T) {
+	ci.Parallel(t)
+
+	packID := pack.ID("webapp")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/packs/web.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"region": "us-west-2", "replicas": 3}`))
+	})
+	mux.HandleFunc("/packs/web", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ci" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+		w.Write([]byte("region: " + r.URL.Query().Get("env") + "\nreplicas: 2\n"))
+	})
+	mux.HandleFunc("/packs/unknown", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("region = \"us-west-2\""))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	t.Run("format from extension", func(t *testing.T) {
+		src, err := NewHTTPSource(PriorityExternalBase, srv.Client(), srv.URL+"/packs/web.json")
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, documentTestSchema())
+		must.NoError(t, err)
+		must.Len(t, 2, vars)
+
+		got := varsByName(vars)
+		must.Eq(t, "us-west-2", got["region"].Value.AsString())
+		replicas, _ := got["replicas"].Value.AsBigFloat().Int64()
+		must.Eq(t, int64(3), replicas)
+	})
+
+	t.Run("format from content type", func(t *testing.T) {
+		src, err := NewHTTPSource(PriorityExternalBase, srv.Client(), "http://ci:secret@"+srv.Listener.Addr().String()+"/packs/web?env=prod")
+		must.NoError(t, err)
+		must.StrNotContains(t, src.Name(), "secret")
+
+		vars, err := src.Fetch(t.Context(), packID, documentTestSchema())
+		must.NoError(t, err)
+		must.Eq(t, "prod", varsByName(vars)["region"].Value.AsString())
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		src, err := NewHTTPSource(PriorityExternalBase, srv.Client(), srv.URL+"/packs/web")
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, documentTestSchema())
+		must.ErrorContains(t, err, "unexpected status 401 Unauthorized")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		src, err := NewHTTPSource(PriorityExternalBase, srv.Client(), srv.URL+"/packs/missing.json")
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, documentTestSchema())
+		must.NoError(t, err)
+		must.Len(t, 0, vars)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		src, err := NewHTTPSource(PriorityExternalBase, srv.Client(), srv.URL+"/packs/unknown")
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, documentTestSchema())
+		must.ErrorContains(t, err, "could not determine the format")
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		_, err := NewHTTPSource(PriorityExternalBase, nil, "ftp://example.com/vars.json")
+		must.ErrorContains(t, err, "requires an http or https URL")
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"time"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// Lease describes a secret with a lease that variable values were read from,
+// such as the credentials of a Vault database role. The values are rendered
+// into the pack as they were when read, so they stop being valid once the
+// lease expires or is revoked.
+type Lease struct {
+	// Source is the name of the source the secret was read from.
+	Source string
+
+	// Pack is the ID of the pack whose variables were read from the secret.
+	Pack pack.ID
+
+	// Path is the path the secret was read from.
+	Path string
+
+	// Variables are the names of the variables read from the secret, sorted.
+	Variables []variables.ID
+
+	// Duration is the duration of the lease when the secret was read.
+	Duration time.Duration
+
+	// Renewable is whether the lease can be renewed. Nomad Pack does not renew
+	// leases itself.
+	Renewable bool
+
+	// Expires is when the lease expires unless it is renewed.
+	Expires time.Time
+}
+
+// LeaseSource is implemented by sources which may read variable values from
+// secrets with leases.
+type LeaseSource interface {
+	VariableSource
+
+	// Leases returns the leases of the secrets variable values were read from
+	// by all calls to Fetch so far.
+	Leases() []Lease
+}
@@ -69,13 +69,14 @@ func (n *NomadSource) Priority() int {
 //
 // Each item of the Nomad Variable maps to one pack variable.
 // Returns nil (not an error) when no Nomad Variable exists at the given path.
-func (n *NomadSource) Fetch(ctx context.Context, _ pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
-	variable, _, err := n.client.Variables().Read(n.path, (&api.QueryOptions{}).WithContext(ctx))
+func (n *NomadSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	path := expandPackPath(n.path, packID)
+	variable, _, err := n.client.Variables().Read(path, (&api.QueryOptions{}).WithContext(ctx))
 	if err != nil {
 		if errors.Is(err, api.ErrVariablePathNotFound) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to read Nomad variable at %s: %w", n.path, err)
+		return nil, fmt.Errorf("failed to read Nomad variable at %s: %w", path, err)
 	}
 
 	vars := make([]*variables.Variable, 0, len(variable.Items))
@@ -6,9 +6,12 @@ package source
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
@@ -18,47 +21,79 @@ import (
 // priority-based precedence. Higher priority sources override lower
 // priority sources for variables with the same name.
 //
-// Thread Safety: Registry is NOT thread-safe. It is designed for
-// single-threaded CLI usage where sources are registered once during
-// initialization and then resolved. Do not call Register() and Resolve()
-// concurrently without external synchronization.
+// Resolve fetches from all sources concurrently, so a slow remote source
+// does not hold up the others, and merges their variables by priority once
+// every fetch has finished, so the result does not depend on the order in
+// which the fetches complete. Sources of equal priority are merged in the
+// order they were registered, the later one winning.
+//
+// Thread Safety: Registry is safe for concurrent use. Register and Resolve may
+// be called from several goroutines; a Resolve uses the sources registered
+// when it starts. As the Fetch of each source runs concurrently with those of
+// the others, sources must not share unsynchronized state.
 //
 // Example usage:
 //
 //	registry := source.NewRegistry()
 //	registry.Register(source.NewEnvSource(10, envVars))
 //	registry.Register(source.NewFileSource(20, fileVars))
+//	registry.RegisterWithTimeout(consulSource, 30*time.Second)
 //	registry.Register(source.NewCLISource(30, cliVars))
 //	vars, err := registry.Resolve(ctx, packID, schema)
 type Registry struct {
-	sources []VariableSource
+	mu      sync.RWMutex
+	sources []registeredSource
+}
+
+// registeredSource is a source of a Registry with the timeout its Fetch is
+// bounded by, or zero if it has none.
+type registeredSource struct {
+	source  VariableSource
+	timeout time.Duration
 }
 
 // NewRegistry creates a new source registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		sources: make([]VariableSource, 0),
+		sources: make([]registeredSource, 0),
 	}
 }
 
-// Register adds a source to the registry.
+// Register adds a source to the registry. Its Fetch is only bounded by the
+// context passed to Resolve.
 // Sources are automatically sorted by priority after registration.
 // Source names are expected to be unique (enforced by source constructors).
 func (r *Registry) Register(source VariableSource) {
-	r.sources = append(r.sources, source)
+	r.RegisterWithTimeout(source, 0)
+}
+
+// RegisterWithTimeout adds a source to the registry whose Fetch is cancelled
+// if it takes longer than timeout. A timeout of zero means no timeout of its
+// own, as for Register.
+func (r *Registry) RegisterWithTimeout(source VariableSource, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sources = append(r.sources, registeredSource{source: source, timeout: timeout})
 
-	// Sort by priority immediately after adding (lower first, so higher priority overwrites)
-	slices.SortFunc(r.sources, func(a, b VariableSource) int {
-		return cmp.Compare(a.Priority(), b.Priority())
+	// Sort by priority immediately after adding (lower first, so higher
+	// priority overwrites). The sort is stable, so sources of equal priority
+	// keep their registration order.
+	slices.SortStableFunc(r.sources, func(a, b registeredSource) int {
+		return cmp.Compare(a.source.Priority(), b.source.Priority())
 	})
 }
 
 // Resolve fetches and merges variables from all registered sources.
-// Sources are processed in priority order (lowest to highest), with
+// Sources are fetched concurrently, each bounded by its own timeout, and
+// their variables are then merged in priority order (lowest to highest), with
 // higher priority sources overwriting variables from lower priority sources.
 // The schema parameter provides the expected type for each variable, allowing
-// sources to perform schema-aware type conversion.
+// sources to perform schema-aware type conversion. The variables are returned
+// sorted by name.
+//
 // Returns an error if context is cancelled or if any source fails to fetch.
+// The error lists every source that failed, not only the first.
 func (r *Registry) Resolve(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
 	// Check context before starting
 	if err := ctx.Err(); err != nil {
@@ -66,28 +101,60 @@ func (r *Registry) Resolve(ctx context.Context, packID pack.ID, schema map[varia
 	}
 
 	// Note: Sources are already sorted by priority in Register()
+	r.mu.RLock()
+	sources := slices.Clone(r.sources)
+	r.mu.RUnlock()
 
-	// Use a map to merge by variable name (higher priority overwrites)
-	varMap := make(map[variables.ID]*variables.Variable)
+	// Each fetch writes only its own index, so the results need no locking.
+	results := make([][]*variables.Variable, len(sources))
+	errs := make([]error, len(sources))
 
-	for _, source := range r.sources {
-		// Check context in loop for long-running operations
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("context cancelled during resolution: %w", err)
-		}
+	var wg sync.WaitGroup
+	for i, rs := range sources {
+		wg.Go(func() {
+			results[i], errs[i] = rs.fetch(ctx, packID, schema)
+		})
+	}
+	wg.Wait()
 
-		vars, err := source.Fetch(ctx, packID, schema)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch from %s: %w", source.Name(), err)
-		}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
 
-		// Merge (higher priority overwrites)
+	// Use a map to merge by variable name (higher priority overwrites)
+	varMap := make(map[variables.ID]*variables.Variable)
+	for _, vars := range results {
 		for _, v := range vars {
 			varMap[v.Name] = v
 		}
 	}
 
-	result := slices.Collect(maps.Values(varMap))
+	result := make([]*variables.Variable, 0, len(varMap))
+	for _, name := range slices.Sorted(maps.Keys(varMap)) {
+		result = append(result, varMap[name])
+	}
 
 	return result, nil
 }
+
+// fetch fetches the variables of the pack from the source within its
+// timeout, naming the source in any error.
+func (rs registeredSource) fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	fetchCtx := ctx
+	if rs.timeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, rs.timeout)
+		defer cancel()
+	}
+
+	vars, err := rs.source.Fetch(fetchCtx, packID, schema)
+	if err != nil {
+		// Report a timeout of the source's own as such, rather than leaving
+		// it to be guessed from the error of the client that was cut off.
+		if ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to fetch from %s: timed out after %s: %w", rs.source.Name(), rs.timeout, err)
+		}
+		return nil, fmt.Errorf("failed to fetch from %s: %w", rs.source.Name(), err)
+	}
+	return vars, nil
+}
@@ -5,7 +5,11 @@ package source
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
@@ -111,3 +115,149 @@ func TestRegistry_Resolve(t *testing.T) {
 		must.ErrorContains(t, err, "context canceled")
 	})
 }
+
+// blockingSource returns its variables once release is closed, or fails when
+// err is set. Until then, it blocks until its context is done.
+type blockingSource struct {
+	name     string
+	priority int
+	vars     []*variables.Variable
+	err      error
+	release  <-chan struct{}
+	started  *sync.WaitGroup
+}
+
+func (s *blockingSource) Name() string  { return s.name }
+func (s *blockingSource) Priority() int { return s.priority }
+
+func (s *blockingSource) Fetch(ctx context.Context, _ pack.ID, _ map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	if s.started != nil {
+		s.started.Done()
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	select {
+	case <-s.release:
+		return s.vars, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestRegistry_ResolveConcurrently(t *testing.T) {
+	packID := pack.ID("webapp")
+	schema := map[variables.ID]*variables.Variable{
+		"app_name": {Name: "app_name", Type: cty.String},
+		"replicas": {Name: "replicas", Type: cty.Number},
+	}
+
+	t.Run("sources are fetched concurrently", func(t *testing.T) {
+		registry := NewRegistry()
+		release := make(chan struct{})
+		var started sync.WaitGroup
+		for i := range 3 {
+			started.Add(1)
+			registry.Register(&blockingSource{
+				name:     fmt.Sprintf("slow-%d", i),
+				priority: PriorityExternalBase,
+				release:  release,
+				started:  &started,
+			})
+		}
+
+		// Each fetch blocks until all three have started, which only
+		// happens if they run at the same time.
+		go func() {
+			started.Wait()
+			close(release)
+		}()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		t.Cleanup(cancel)
+		_, err := registry.Resolve(ctx, packID, schema)
+		must.NoError(t, err)
+	})
+
+	t.Run("sources are merged by priority whichever finishes first", func(t *testing.T) {
+		registry := NewRegistry()
+		slow := make(chan struct{})
+		registry.Register(&blockingSource{
+			name:     "slow",
+			priority: PriorityCLI,
+			vars:     []*variables.Variable{{Name: "app_name", Value: cty.StringVal("cli-app")}},
+			release:  slow,
+		})
+		fast := make(chan struct{})
+		close(fast)
+		registry.Register(&blockingSource{
+			name:     "fast",
+			priority: PriorityExternalBase,
+			vars: []*variables.Variable{
+				{Name: "replicas", Value: cty.NumberIntVal(3)},
+				{Name: "app_name", Value: cty.StringVal("fast-app")},
+			},
+			release: fast,
+		})
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(slow)
+		}()
+
+		result, err := registry.Resolve(t.Context(), packID, schema)
+		must.NoError(t, err)
+		must.Len(t, 2, result)
+		must.Eq(t, "app_name", result[0].Name)
+		must.Eq(t, "cli-app", result[0].Value.AsString())
+		must.Eq(t, "replicas", result[1].Name)
+	})
+
+	t.Run("sources of equal priority merge in registration order", func(t *testing.T) {
+		registry := NewRegistry()
+		released := make(chan struct{})
+		close(released)
+		for _, name := range []string{"first", "second"} {
+			registry.Register(&blockingSource{
+				name:     name,
+				priority: PriorityExternalBase,
+				vars:     []*variables.Variable{{Name: "app_name", Value: cty.StringVal(name + "-app")}},
+				release:  released,
+			})
+		}
+
+		for range 10 {
+			result, err := registry.Resolve(t.Context(), packID, schema)
+			must.NoError(t, err)
+			must.Eq(t, "second-app", result[0].Value.AsString())
+		}
+	})
+
+	t.Run("a source which times out fails alone", func(t *testing.T) {
+		registry := NewRegistry()
+		fast := make(chan struct{})
+		close(fast)
+		registry.RegisterWithTimeout(&blockingSource{name: "hung", priority: PriorityExternalBase}, 20*time.Millisecond)
+		registry.RegisterWithTimeout(&blockingSource{
+			name:     "fast",
+			priority: PriorityExternalBase,
+			vars:     []*variables.Variable{{Name: "app_name", Value: cty.StringVal("fast-app")}},
+			release:  fast,
+		}, 20*time.Millisecond)
+
+		_, err := registry.Resolve(t.Context(), packID, schema)
+		must.ErrorContains(t, err, "failed to fetch from hung: timed out after 20ms")
+		must.ErrorIs(t, err, context.DeadlineExceeded)
+		must.StrNotContains(t, err.Error(), "fast")
+	})
+
+	t.Run("errors of every failing source are reported", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.Register(&blockingSource{name: "consul", priority: PriorityExternalBase, err: errors.New("connection refused")})
+		registry.Register(&blockingSource{name: "vault", priority: PriorityExternalBase + 1, err: errors.New("permission denied")})
+
+		_, err := registry.Resolve(t.Context(), packID, schema)
+		must.ErrorContains(t, err, "failed to fetch from consul: connection refused")
+		must.ErrorContains(t, err, "failed to fetch from vault: permission denied")
+	})
+}
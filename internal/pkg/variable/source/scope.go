@@ -0,0 +1,76 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// PackPlaceholder is replaced in the path of an external source with the ID
+// of the pack whose variables are being fetched, so that a parent pack and
+// each of its dependencies read from their own subtree. The ID is written as
+// a path, with the IDs of the parent packs as parent directories; for
+// example, consul:///packs/{pack}/prod reads the variables of the "redis"
+// dependency of the "web" pack from packs/web/redis/prod.
+const PackPlaceholder = "{pack}"
+
+// expandPackPath replaces PackPlaceholder in path with the ID of the pack.
+func expandPackPath(path string, packID pack.ID) string {
+	return strings.ReplaceAll(path, PackPlaceholder, packID.AsPath())
+}
+
+// ScopedSource restricts a source to the variables of a single pack, so that
+// a source can be given for a dependency pack without its values also being
+// applied to the parent pack or other dependencies declaring variables of the
+// same name.
+type ScopedSource struct {
+	source VariableSource
+	pack   pack.ID
+}
+
+// NewScopedSource creates a source which fetches the variables of the pack
+// with the given ID from src, and no variables for any other pack. packID is
+// the full ID of the pack, such as "web" for a parent pack or "web.redis" for
+// its "redis" dependency.
+func NewScopedSource(packID pack.ID, src VariableSource) *ScopedSource {
+	return &ScopedSource{source: src, pack: packID}
+}
+
+// Name returns the unique identifier for this source.
+func (s *ScopedSource) Name() string {
+	return fmt.Sprintf("%s for pack %s", s.source.Name(), s.pack)
+}
+
+// Priority returns the precedence level of the scoped source.
+func (s *ScopedSource) Priority() int {
+	return s.source.Priority()
+}
+
+// Pack returns the ID of the pack the source is restricted to.
+func (s *ScopedSource) Pack() pack.ID {
+	return s.pack
+}
+
+// Fetch retrieves variables from the scoped source if packID is the pack it
+// is restricted to, and returns nil for any other pack.
+func (s *ScopedSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	if packID != s.pack {
+		return nil, nil
+	}
+	return s.source.Fetch(ctx, packID, schema)
+}
+
+// Leases returns the leases of the secrets read by the scoped source, if it
+// reads secrets with leases.
+func (s *ScopedSource) Leases() []Lease {
+	if ls, ok := s.source.(LeaseSource); ok {
+		return ls.Leases()
+	}
+	return nil
+}
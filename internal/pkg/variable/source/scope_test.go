@@ -0,0 +1,120 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExpandPackPath(t *testing.T) {
+	ci.Parallel(t)
+
+	must.Eq(t, "packs/web/prod", expandPackPath("packs/{pack}/prod", "web"))
+	must.Eq(t, "packs/web/redis/prod", expandPackPath("packs/{pack}/prod", pack.ID("web").Join("redis")))
+	must.Eq(t, "packs/prod", expandPackPath("packs/prod", "web"))
+}
+
+func TestDocumentFileSource_FetchPackTemplate(t *testing.T) {
+	ci.Parallel(t)
+
+	dir := t.TempDir()
+	must.NoError(t, os.MkdirAll(filepath.Join(dir, "web", "redis"), 0o755))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "web", "vars.json"), []byte(`{"region": "us-west-2"}`), 0o644))
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "web", "redis", "vars.json"), []byte(`{"region": "eu-west-1"}`), 0o644))
+
+	src, err := NewDocumentFileSource(PriorityExternalBase, filepath.Join(dir, "{pack}", "vars.json"))
+	must.NoError(t, err)
+
+	schema := documentTestSchema()
+
+	vars, err := src.Fetch(context.Background(), "web", schema)
+	must.NoError(t, err)
+	must.Len(t, 1, vars)
+	must.Eq(t, cty.StringVal("us-west-2"), vars[0].Value)
+
+	vars, err = src.Fetch(context.Background(), pack.ID("web").Join("redis"), schema)
+	must.NoError(t, err)
+	must.Len(t, 1, vars)
+	must.Eq(t, cty.StringVal("eu-west-1"), vars[0].Value)
+
+	// A pack without a document of its own has no values.
+	vars, err = src.Fetch(context.Background(), pack.ID("web").Join("memcached"), schema)
+	must.NoError(t, err)
+	must.Len(t, 0, vars)
+}
+
+func TestHTTPSource_FetchPackTemplate(t *testing.T) {
+	ci.Parallel(t)
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"region": "us-west-2"}`))
+	}))
+	defer server.Close()
+
+	src, err := NewHTTPSource(PriorityExternalBase, server.Client(), server.URL+"/packs/{pack}.json?pack={pack}")
+	must.NoError(t, err)
+	must.Eq(t, "http("+server.URL+"/packs/{pack}.json?pack={pack})", src.Name())
+
+	_, err = src.Fetch(context.Background(), pack.ID("web").Join("redis"), documentTestSchema())
+	must.NoError(t, err)
+	must.Eq(t, []string{"/packs/web/redis.json?pack=web/redis"}, requested)
+}
+
+func TestScopedSource(t *testing.T) {
+	ci.Parallel(t)
+
+	path := writeDocument(t, "vars.json", `{"region": "us-west-2"}`)
+	file, err := NewDocumentFileSource(PriorityExternalBase+1, path)
+	must.NoError(t, err)
+
+	redis := pack.ID("web").Join("redis")
+	src := NewScopedSource(redis, file)
+	must.Eq(t, redis, src.Pack())
+	must.Eq(t, PriorityExternalBase+1, src.Priority())
+	must.Eq(t, "file("+path+") for pack web.redis", src.Name())
+
+	schema := map[variables.ID]*variables.Variable{
+		"region": {Name: "region", Type: cty.String},
+	}
+
+	vars, err := src.Fetch(context.Background(), redis, schema)
+	must.NoError(t, err)
+	must.Len(t, 1, vars)
+
+	vars, err = src.Fetch(context.Background(), "web", schema)
+	must.NoError(t, err)
+	must.Len(t, 0, vars)
+}
+
+func TestScopedSourceConfig_Build(t *testing.T) {
+	ci.Parallel(t)
+
+	cfg := ScopedSourceConfig{
+		Pack:   "web.redis",
+		Source: FileSourceConfig{Priority: PriorityExternalBase, Path: "vars/{pack}.yaml"},
+	}
+	src, err := cfg.Build()
+	must.NoError(t, err)
+	scoped, ok := src.(*ScopedSource)
+	must.True(t, ok)
+	must.Eq(t, "web.redis", scoped.Pack())
+
+	cfg.Source = FileSourceConfig{Priority: PriorityExternalBase, Path: "vars.txt"}
+	_, err = cfg.Build()
+	must.ErrorContains(t, err, "requires a .hcl, .json, .yaml or .yml file")
+}
@@ -0,0 +1,91 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+)
+
+// IsSecret returns whether the values of the source configured by cfg may be
+// secrets, as those of Vault and Nomad sources are.
+func IsSecret(cfg SourceConfig) bool {
+	switch c := cfg.(type) {
+	case VaultSourceConfig, NomadSourceConfig:
+		return true
+	case ScopedSourceConfig:
+		return IsSecret(c.Source)
+	case CachedSourceConfig:
+		return IsSecret(c.Source)
+	}
+	return false
+}
+
+// SecretSource records the variables whose values were fetched from a source
+// holding secrets, so that they can be kept out of anything stored in plain
+// form, such as the releases of a deployment.
+type SecretSource struct {
+	source VariableSource
+
+	// fetched are the names of the variables returned by Fetch for each
+	// pack, guarded by mu.
+	mu      sync.Mutex
+	fetched map[pack.ID]map[variables.ID]struct{}
+}
+
+// NewSecretSource creates a source which fetches from src, recording the
+// variables it returns.
+func NewSecretSource(src VariableSource) *SecretSource {
+	return &SecretSource{source: src, fetched: make(map[pack.ID]map[variables.ID]struct{})}
+}
+
+// Name returns the unique identifier for this source.
+func (s *SecretSource) Name() string {
+	return s.source.Name()
+}
+
+// Priority returns the precedence level of the secret source.
+func (s *SecretSource) Priority() int {
+	return s.source.Priority()
+}
+
+// Fetch retrieves variables from the secret source and records their names.
+func (s *SecretSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	vars, err := s.source.Fetch(ctx, packID, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range vars {
+		if s.fetched[packID] == nil {
+			s.fetched[packID] = make(map[variables.ID]struct{})
+		}
+		s.fetched[packID][v.Name] = struct{}{}
+	}
+	return vars, nil
+}
+
+// Variables returns the sorted names of the variables of the pack returned by
+// all calls to Fetch so far.
+func (s *SecretSource) Variables(packID pack.ID) []variables.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.fetched[packID]))
+}
+
+// Leases returns the leases of the secrets read by the source, if it reads
+// secrets with leases.
+func (s *SecretSource) Leases() []Lease {
+	if ls, ok := s.source.(LeaseSource); ok {
+		return ls.Leases()
+	}
+	return nil
+}
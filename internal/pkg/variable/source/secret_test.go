@@ -0,0 +1,44 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/hashicorp/nomad/ci"
+	"github.com/shoenig/test/must"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestIsSecret(t *testing.T) {
+	ci.Parallel(t)
+
+	vault := VaultSourceConfig{Mount: "secret", Paths: []string{"app"}}
+	file := FileSourceConfig{Path: "vars.json"}
+	cache := NewSourceCache(t.TempDir(), 0, false, "key")
+
+	must.True(t, IsSecret(vault))
+	must.True(t, IsSecret(NomadSourceConfig{Path: "app"}))
+	must.True(t, IsSecret(WithCache(ScopedSourceConfig{Pack: "web", Source: vault}, cache)))
+	must.False(t, IsSecret(file))
+	must.False(t, IsSecret(WithCache(file, cache)))
+}
+
+func TestSecretSource(t *testing.T) {
+	ci.Parallel(t)
+
+	inner := &countingSource{values: map[variables.ID]cty.Value{
+		"password": cty.StringVal("s3cret"),
+	}}
+	src := NewSecretSource(inner)
+	must.Eq(t, inner.Name(), src.Name())
+
+	_, err := src.Fetch(t.Context(), "web", cacheTestSchema())
+	must.NoError(t, err)
+	must.Eq(t, []variables.ID{"password"}, src.Variables("web"))
+	must.SliceEmpty(t, src.Variables(pack.ID("other")))
+	must.Len(t, 1, src.Leases())
+}
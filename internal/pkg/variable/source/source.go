@@ -21,8 +21,8 @@ import (
 //   - CLI flags (--var)
 //
 // External sources rank below all local input: anything passed with --var,
-// --var-file, or the environment overrides a value read from Consul, Vault, or
-// Nomad. Each external source is assigned PriorityExternalBase plus its position
+// --var-file, or the environment overrides a value read from Consul, Vault,
+// Nomad, a file:// document or an http(s):// document. Each external source is assigned PriorityExternalBase plus its position
 // on the command line, so when two sources supply the same variable the one
 // given later wins.
 const (
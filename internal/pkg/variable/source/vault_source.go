@@ -5,9 +5,14 @@ package source
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
@@ -15,30 +20,71 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-// VaultSource fetches variables from a Vault KV v2 secret. All variables for a
-// pack are stored as fields of a single secret at <mount>/<path> (for example, mount="secret", path="myapp/config").
+// VaultEngine is the kind of Vault secrets engine a VaultSource reads from.
+type VaultEngine string
+
+const (
+	// VaultEngineKVv2 reads the latest version of secrets from a KV v2 mount.
+	VaultEngineKVv2 VaultEngine = "kv2"
+
+	// VaultEngineKVv1 reads secrets from a KV v1 mount.
+	VaultEngineKVv1 VaultEngine = "kv1"
+
+	// VaultEngineLogical reads any logical path, such as the credentials of a
+	// database role, whose response may carry a lease.
+	VaultEngineLogical VaultEngine = "logical"
+)
+
+// VaultSource fetches variables from Vault secrets. All variables for a pack
+// are stored as fields of the secrets at <mount>/<path> (for example,
+// mount="secret", path="myapp/config"). When more than one path is given, the
+// fields of the secrets are merged, and a field of a later path overrides the
+// same field of an earlier one.
 type VaultSource struct {
 	name     string
 	priority int
 	client   *vaultapi.Client
-	mount    string // KV v2 mount point, e.g. "secret"
-	path     string // secret path within the mount, e.g. "myapp/config"
+	engine   VaultEngine
+	mount    string   // mount point, e.g. "secret"
+	paths    []string // secret paths within the mount, e.g. "myapp/config"
+
+	// leases are the leases of the secrets read by Fetch, and logical the
+	// secrets read from each logical path, guarded by mu.
+	mu      sync.Mutex
+	leases  []Lease
+	logical map[string]*vaultSecret
 }
 
-// NewVaultSource creates a new Vault KV v2 variable source.
+// NewVaultSource creates a new Vault variable source.
 // config can be nil to use the default Vault configuration
 // (reads VAULT_ADDR and VAULT_TOKEN from the environment).
-// mount is the KV v2 engine mount point; path is the secret path within it.
-func NewVaultSource(priority int, config *vaultapi.Config, mount, path string) (*VaultSource, error) {
+// engine is the kind of secrets engine mounted at mount, and defaults to KV
+// v2 when empty; paths are the secret paths within the mount, read in order.
+func NewVaultSource(priority int, config *vaultapi.Config, engine VaultEngine, mount string, paths ...string) (*VaultSource, error) {
+	switch engine {
+	case "":
+		engine = VaultEngineKVv2
+	case VaultEngineKVv2, VaultEngineKVv1, VaultEngineLogical:
+	default:
+		return nil, fmt.Errorf("vault source engine must be one of %s, %s or %s, got %q",
+			VaultEngineKVv2, VaultEngineKVv1, VaultEngineLogical, engine)
+	}
+
 	mount = strings.Trim(mount, "/")
 	if mount == "" {
 		return nil, fmt.Errorf("vault source requires a non-empty mount point")
 	}
 
-	path = strings.Trim(path, "/")
-	if path == "" {
+	if len(paths) == 0 {
 		return nil, fmt.Errorf("vault source requires a non-empty secret path")
 	}
+	trimmed := make([]string, len(paths))
+	for i, path := range paths {
+		trimmed[i] = strings.Trim(path, "/")
+		if trimmed[i] == "" {
+			return nil, fmt.Errorf("vault source requires a non-empty secret path")
+		}
+	}
 
 	if config == nil {
 		config = vaultapi.DefaultConfig()
@@ -49,14 +95,28 @@ func NewVaultSource(priority int, config *vaultapi.Config, mount, path string) (
 		return nil, fmt.Errorf("failed to create Vault client: %w", err)
 	}
 
-	name := fmt.Sprintf("vault(%s/%s/%s)", config.Address, mount, path)
+	// The name takes the form of the --var-source URL the source is built
+	// from, so that errors point at the source as the user gave it.
+	name := fmt.Sprintf("vault(%s/%s/%s", config.Address, mount, trimmed[0])
+	var query []string
+	if engine != VaultEngineKVv2 {
+		query = append(query, "engine="+string(engine))
+	}
+	for _, path := range trimmed[1:] {
+		query = append(query, "path="+path)
+	}
+	if len(query) > 0 {
+		name += "?" + strings.Join(query, "&")
+	}
+	name += ")"
 
 	return &VaultSource{
 		name:     name,
 		priority: priority,
 		client:   client,
+		engine:   engine,
 		mount:    mount,
-		path:     path,
+		paths:    trimmed,
 	}, nil
 }
 
@@ -70,66 +130,216 @@ func (v *VaultSource) Priority() int {
 	return v.priority
 }
 
-// Fetch reads the Vault KV v2 secret at <mount>/<path> and returns variables
+// Leases returns the leases of the secrets read by Fetch. Only secrets with a
+// lease that at least one variable was read from are included.
+func (v *VaultSource) Leases() []Lease {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return slices.Clone(v.leases)
+}
+
+// vaultSecret is a secret read by a VaultSource.
+type vaultSecret struct {
+	path          string
+	data          map[string]any
+	leaseID       string
+	leaseDuration time.Duration
+	renewable     bool
+}
+
+// Fetch reads the Vault secrets at <mount>/<path> and returns variables
 // whose names appear in schema. Values are decoded using the schema type —
 // string fields are returned as-is; all other types are JSON-decoded.
 //
-// Each field of the secret maps to one pack variable. Fields not present in
+// Each field of a secret maps to one pack variable. Fields not present in
 // the schema are silently skipped. An empty value for a non-string variable
-// is an error; empty strings are valid for string variables.
+// is an error; empty strings are valid for string variables. KV secrets hold
+// only strings, but a logical path may return any JSON value, which is
+// converted to the type of the variable.
 //
-// Returns nil (not an error) when the secret does not exist at the given path
-// or when the latest version has been deleted.
-func (v *VaultSource) Fetch(ctx context.Context, _ pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
-	secret, err := v.client.KVv2(v.mount).Get(ctx, v.path)
-	if err != nil {
-		if errors.Is(err, vaultapi.ErrSecretNotFound) {
-			return nil, nil
+// A secret which does not exist at its path, or whose latest version has been
+// deleted, provides no values rather than being an error.
+//
+// Reading a logical path may create a secret, such as the credentials of a
+// database role, so each logical path is read once, and the packs whose
+// paths expand to the same one share its secret and lease.
+func (v *VaultSource) Fetch(ctx context.Context, packID pack.ID, schema map[variables.ID]*variables.Variable) ([]*variables.Variable, error) {
+	// vars holds the merged variables and from holds the index of the secret
+	// each was last read from, to attribute it to that secret's lease.
+	vars := make(map[variables.ID]*variables.Variable)
+	from := make(map[variables.ID]int)
+	secrets := make([]*vaultSecret, len(v.paths))
+
+	for i, path := range v.paths {
+		secret, err := v.read(ctx, expandPackPath(path, packID))
+		if err != nil {
+			return nil, err
+		}
+		if secret == nil {
+			continue
+		}
+		secrets[i] = secret
+
+		for rawKey, rawVal := range secret.data {
+			schemaVar, inSchema := schema[variables.ID(rawKey)]
+			if !inSchema {
+				continue
+			}
+
+			value, err := v.decodeField(rawKey, rawVal, schemaVar)
+			if err != nil {
+				return nil, err
+			}
+
+			name := variables.ID(rawKey)
+			vars[name] = &variables.Variable{
+				Name:  name,
+				Value: value,
+				Type:  value.Type(),
+			}
+			from[name] = i
 		}
-		return nil, fmt.Errorf("failed to read Vault secret at %s/%s: %w", v.mount, v.path, err)
 	}
 
-	// A deleted secret has nil Data.
-	if secret.Data == nil {
-		return nil, nil
+	v.recordLeases(packID, secrets, from)
+
+	out := make([]*variables.Variable, 0, len(vars))
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		out = append(out, vars[name])
 	}
+	return out, nil
+}
 
-	vars := make([]*variables.Variable, 0, len(secret.Data))
-	for rawKey, rawVal := range secret.Data {
-		schemaVar, inSchema := schema[variables.ID(rawKey)]
-		if !inSchema {
-			continue
+// read reads the secret at path within the mount, returning nil if it does
+// not exist or has been deleted.
+func (v *VaultSource) read(ctx context.Context, path string) (*vaultSecret, error) {
+	fullPath := v.mount + "/" + path
+
+	switch v.engine {
+	case VaultEngineLogical:
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if secret, ok := v.logical[fullPath]; ok {
+			return secret, nil
 		}
 
-		// Vault KV stores all values as strings.
-		str, ok := rawVal.(string)
-		if !ok {
-			return nil, fmt.Errorf("field %s is not a string (got %T)", rawKey, rawVal)
+		secret, err := v.client.Logical().ReadWithContext(ctx, fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Vault secret at %s: %w", fullPath, err)
 		}
 
-		// Empty values for string variables are valid and kept as "".
-		if str == "" && schemaVar.Type != cty.String {
-			return nil, fmt.Errorf("empty Vault value for %s: a %s value is required", rawKey, schemaVar.Type.FriendlyName())
+		var out *vaultSecret
+		if secret != nil && secret.Data != nil {
+			out = &vaultSecret{
+				path:          fullPath,
+				data:          secret.Data,
+				leaseID:       secret.LeaseID,
+				leaseDuration: time.Duration(secret.LeaseDuration) * time.Second,
+				renewable:     secret.Renewable,
+			}
 		}
+		if v.logical == nil {
+			v.logical = make(map[string]*vaultSecret)
+		}
+		v.logical[fullPath] = out
+		return out, nil
 
-		// Convert using the variable's constraint type. ConstraintType preserves
-		// optional() attributes.
-		expectedType := schemaVar.ConstraintType
-		if expectedType == cty.NilType {
-			expectedType = schemaVar.Type
+	case VaultEngineKVv1:
+		secret, err := v.client.KVv1(v.mount).Get(ctx, path)
+		if err != nil {
+			if errors.Is(err, vaultapi.ErrSecretNotFound) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to read Vault secret at %s: %w", fullPath, err)
 		}
+		return &vaultSecret{path: fullPath, data: secret.Data}, nil
 
-		value, err := decodeValue("Vault", []byte(str), expectedType)
+	default:
+		secret, err := v.client.KVv2(v.mount).Get(ctx, path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert value for %s: %w", rawKey, err)
+			if errors.Is(err, vaultapi.ErrSecretNotFound) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to read Vault secret at %s: %w", fullPath, err)
 		}
 
-		vars = append(vars, &variables.Variable{
-			Name:  variables.ID(rawKey),
-			Value: value,
-			Type:  value.Type(),
-		})
+		// A deleted secret has nil Data.
+		if secret.Data == nil {
+			return nil, nil
+		}
+		return &vaultSecret{path: fullPath, data: secret.Data}, nil
+	}
+}
+
+// decodeField converts the value of a field of a secret to the type of the
+// variable it is read into.
+func (v *VaultSource) decodeField(rawKey string, rawVal any, schemaVar *variables.Variable) (cty.Value, error) {
+	str, ok := rawVal.(string)
+	if !ok {
+		// Vault KV stores all values as strings, but a logical path may
+		// return numbers, lists and objects, such as the CA chain of a PKI
+		// certificate, which are decoded as JSON.
+		if v.engine != VaultEngineLogical {
+			return cty.NilVal, fmt.Errorf("field %s is not a string (got %T)", rawKey, rawVal)
+		}
+		data, err := json.Marshal(rawVal)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("failed to encode value for %s: %w", rawKey, err)
+		}
+		str = string(data)
+	}
+
+	// Empty values for string variables are valid and kept as "".
+	if str == "" && schemaVar.Type != cty.String {
+		return cty.NilVal, fmt.Errorf("empty Vault value for %s: a %s value is required", rawKey, schemaVar.Type.FriendlyName())
 	}
 
-	return vars, nil
+	// Convert using the variable's constraint type. ConstraintType preserves
+	// optional() attributes.
+	expectedType := schemaVar.ConstraintType
+	if expectedType == cty.NilType {
+		expectedType = schemaVar.Type
+	}
+
+	value, err := decodeValue("Vault", []byte(str), expectedType)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to convert value for %s: %w", rawKey, err)
+	}
+	return value, nil
+}
+
+// recordLeases records the lease of each secret with one that a variable of
+// the pack was read from. from holds the index of the secret each variable
+// was read from.
+func (v *VaultSource) recordLeases(packID pack.ID, secrets []*vaultSecret, from map[variables.ID]int) {
+	now := time.Now()
+
+	for i, secret := range secrets {
+		if secret == nil || secret.leaseID == "" || secret.leaseDuration <= 0 {
+			continue
+		}
+
+		var names []variables.ID
+		for name, idx := range from {
+			if idx == i {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		slices.Sort(names)
+
+		v.mu.Lock()
+		v.leases = append(v.leases, Lease{
+			Source:    v.name,
+			Pack:      packID,
+			Path:      secret.path,
+			Variables: names,
+			Duration:  secret.leaseDuration,
+			Renewable: secret.renewable,
+			Expires:   now.Add(secret.leaseDuration),
+		})
+		v.mu.Unlock()
+	}
 }
@@ -0,0 +1,226 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/hashicorp/nomad/ci"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/shoenig/test/must"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// newFakeVault serves the responses of the Vault HTTP API for the given
+// request paths, such as "/v1/secret/data/app", and 404 Not Found for any
+// other path.
+func newFakeVault(t *testing.T, responses map[string]any) *vaultapi.Config {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		must.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := vaultapi.DefaultConfig()
+	cfg.Address = server.URL
+	return cfg
+}
+
+// kvv2Response is the response of a KV v2 read of a secret holding data.
+func kvv2Response(data map[string]any) map[string]any {
+	return map[string]any{
+		"data": map[string]any{
+			"data": data,
+			"metadata": map[string]any{
+				"created_time":  "2026-01-02T03:04:05Z",
+				"deletion_time": "",
+				"destroyed":     false,
+				"version":       1,
+			},
+		},
+	}
+}
+
+func TestVaultSource_FetchEngines(t *testing.T) {
+	ci.Parallel(t)
+
+	packID := pack.ID("webapp")
+	schema := map[variables.ID]*variables.Variable{
+		"region":   {Name: "region", Type: cty.String},
+		"replicas": {Name: "replicas", Type: cty.Number},
+		"username": {Name: "username", Type: cty.String},
+		"password": {Name: "password", Type: cty.String},
+		"ca_chain": {Name: "ca_chain", Type: cty.List(cty.String)},
+	}
+
+	cfg := newFakeVault(t, map[string]any{
+		"/v1/secret/data/common": kvv2Response(map[string]any{"region": "us-east-1", "replicas": "1"}),
+		"/v1/secret/data/prod":   kvv2Response(map[string]any{"region": "us-west-2"}),
+		"/v1/kv/webapp":          map[string]any{"data": map[string]any{"replicas": "3"}},
+		"/v1/database/creds/app": map[string]any{
+			"lease_id":       "database/creds/app/abc123",
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]any{"username": "v-app", "password": "s3cret"},
+		},
+		"/v1/pki/cert/ca_chain": map[string]any{
+			"data": map[string]any{"ca_chain": []string{"root", "intermediate"}},
+		},
+	})
+
+	t.Run("kv v2 paths are merged in order", func(t *testing.T) {
+		src, err := NewVaultSource(PriorityExternalBase, cfg, VaultEngineKVv2, "secret", "common", "missing", "prod")
+		must.NoError(t, err)
+		must.Eq(t, "vault("+cfg.Address+"/secret/common?path=missing&path=prod)", src.Name())
+
+		vars, err := src.Fetch(t.Context(), packID, schema)
+		must.NoError(t, err)
+		got := vaultVarsByName(vars)
+		must.MapLen(t, 2, got)
+		must.Eq(t, "us-west-2", got["region"].Value.AsString())
+		must.True(t, got["replicas"].Value.RawEquals(cty.NumberIntVal(1)))
+		must.SliceEmpty(t, src.Leases())
+	})
+
+	t.Run("kv v1", func(t *testing.T) {
+		src, err := NewVaultSource(PriorityExternalBase, cfg, VaultEngineKVv1, "kv", "{pack}")
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, schema)
+		must.NoError(t, err)
+		must.Len(t, 1, vars)
+		must.True(t, vars[0].Value.RawEquals(cty.NumberIntVal(3)))
+
+		vars, err = src.Fetch(t.Context(), "other", schema)
+		must.NoError(t, err)
+		must.Len(t, 0, vars)
+	})
+
+	t.Run("logical path records its lease", func(t *testing.T) {
+		src, err := NewVaultSource(PriorityExternalBase, cfg, VaultEngineLogical, "database", "creds/app")
+		must.NoError(t, err)
+
+		before := time.Now()
+		vars, err := src.Fetch(t.Context(), packID, schema)
+		must.NoError(t, err)
+		must.Len(t, 2, vars)
+		must.Eq(t, "s3cret", vaultVarsByName(vars)["password"].Value.AsString())
+
+		leases := src.Leases()
+		must.Len(t, 1, leases)
+		must.Eq(t, packID, leases[0].Pack)
+		must.Eq(t, "database/creds/app", leases[0].Path)
+		must.Eq(t, []variables.ID{"password", "username"}, leases[0].Variables)
+		must.Eq(t, time.Hour, leases[0].Duration)
+		must.True(t, leases[0].Renewable)
+		must.False(t, leases[0].Expires.Before(before.Add(time.Hour)))
+	})
+
+	t.Run("logical path values are converted from JSON", func(t *testing.T) {
+		src, err := NewVaultSource(PriorityExternalBase, cfg, VaultEngineLogical, "pki", "cert/ca_chain")
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, schema)
+		must.NoError(t, err)
+		must.Len(t, 1, vars)
+		must.Eq(t, cty.ListVal([]cty.Value{cty.StringVal("root"), cty.StringVal("intermediate")}), vars[0].Value)
+		must.SliceEmpty(t, src.Leases())
+	})
+
+	t.Run("lease of overridden values is not recorded", func(t *testing.T) {
+		overrides := newFakeVault(t, map[string]any{
+			"/v1/database/creds/app": map[string]any{
+				"lease_id":       "database/creds/app/abc123",
+				"lease_duration": 3600,
+				"data":           map[string]any{"username": "v-app"},
+			},
+			"/v1/database/static": map[string]any{
+				"data": map[string]any{"username": "static"},
+			},
+		})
+		src, err := NewVaultSource(PriorityExternalBase, overrides, VaultEngineLogical, "database", "creds/app", "static")
+		must.NoError(t, err)
+
+		vars, err := src.Fetch(t.Context(), packID, schema)
+		must.NoError(t, err)
+		must.Len(t, 1, vars)
+		must.Eq(t, "static", vars[0].Value.AsString())
+		must.SliceEmpty(t, src.Leases())
+	})
+
+	t.Run("kv values must be strings", func(t *testing.T) {
+		typed := newFakeVault(t, map[string]any{
+			"/v1/kv/webapp": map[string]any{"data": map[string]any{"replicas": 3}},
+		})
+		src, err := NewVaultSource(PriorityExternalBase, typed, VaultEngineKVv1, "kv", "webapp")
+		must.NoError(t, err)
+
+		_, err = src.Fetch(t.Context(), packID, schema)
+		must.ErrorContains(t, err, "is not a string")
+	})
+}
+
+func TestVaultSource_FetchLogicalOnce(t *testing.T) {
+	ci.Parallel(t)
+
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads++
+		w.Header().Set("Content-Type", "application/json")
+		must.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"lease_id":       fmt.Sprintf("database/creds/app/%d", reads),
+			"lease_duration": 3600,
+			"data":           map[string]any{"username": fmt.Sprintf("v-app-%d", reads)},
+		}))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := vaultapi.DefaultConfig()
+	cfg.Address = server.URL
+	src, err := NewVaultSource(PriorityExternalBase, cfg, VaultEngineLogical, "database", "creds/app")
+	must.NoError(t, err)
+
+	// Each pack of a deployment fetches from the source, but the credentials
+	// are only created once, and shared.
+	schema := map[variables.ID]*variables.Variable{"username": {Name: "username", Type: cty.String}}
+	for _, packID := range []pack.ID{"webapp", "webapp.db"} {
+		vars, err := src.Fetch(t.Context(), packID, schema)
+		must.NoError(t, err)
+		must.Len(t, 1, vars)
+		must.Eq(t, "v-app-1", vars[0].Value.AsString())
+	}
+	must.Eq(t, 1, reads)
+
+	leases := src.Leases()
+	must.Len(t, 2, leases)
+	must.Eq(t, leases[0].Path, leases[1].Path)
+}
+
+func TestNewVaultSource_Errors(t *testing.T) {
+	ci.Parallel(t)
+
+	_, err := NewVaultSource(PriorityExternalBase, nil, "kv3", "secret", "app")
+	must.ErrorContains(t, err, `engine must be one of kv2, kv1 or logical, got "kv3"`)
+
+	_, err = NewVaultSource(PriorityExternalBase, nil, VaultEngineKVv2, "secret")
+	must.ErrorContains(t, err, "requires a non-empty secret path")
+
+	_, err = NewVaultSource(PriorityExternalBase, nil, VaultEngineKVv2, "secret", "app", "/")
+	must.ErrorContains(t, err, "requires a non-empty secret path")
+}
@@ -3,6 +3,8 @@
 
 package job
 
+import "time"
+
 // CLIConfig contains all possible configurations required by the Nomad Pack
 // CLI in order to render, plan, run, and destroy job templates.
 type CLIConfig struct {
@@ -13,18 +15,29 @@ type CLIConfig struct {
 // RunCLIConfig specifies the configuration that is used by the Nomad Pack run
 // command.
 type RunCLIConfig struct {
-	CheckIndex        uint64
+	CheckIndex uint64
+
+	// CheckIndexes holds the job modify index of each template of a saved
+	// plan, keyed by template name. A template found here is registered with
+	// its index enforced, overriding CheckIndex.
+	CheckIndexes map[string]uint64
+
 	ConsulToken       string
 	ConsulNamespace   string
 	VaultToken        string
 	VaultNamespace    string
 	EnableRollback    bool
+	Atomic            bool
 	PreserveCounts    bool
 	PreserveResources bool
 	DeployOverride    bool
 	PolicyOverride    bool
 	Detach            bool
 	Verbose           bool
+
+	// StageTimeout is the maximum time to wait for each stage of an ordered
+	// deployment, or a hook, to complete. Zero means no limit.
+	StageTimeout time.Duration
 }
 
 // PlanCLIConfig specifies the configuration that is used by the Nomad Pack
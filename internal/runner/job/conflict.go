@@ -26,6 +26,22 @@ func (r *Runner) CheckForConflicts(errCtx *errors.UIErrorContext) []*errors.Wrap
 		}
 	}
 
+	// Hook jobs are purged before they run, so they must not clash with a
+	// job belonging to anything else either.
+	for tplName, hook := range r.hookTemplates {
+		if err := r.checkForConflict(hook.GetName()); err != nil {
+			outputErrors = append(outputErrors, newValidationDeployerError(err, validationSubjConflict, tplName))
+		}
+	}
+
+	// The same goes for test jobs, which are replaced each time they are
+	// dispatched.
+	for tplName, test := range r.testTemplates {
+		if err := r.checkForConflict(test.GetName()); err != nil {
+			outputErrors = append(outputErrors, newValidationDeployerError(err, validationSubjConflict, tplName))
+		}
+	}
+
 	if len(outputErrors) > 0 {
 		return outputErrors
 	}
@@ -0,0 +1,101 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// deployStages groups the parsed templates into ordered stages using the
+// PackDependsOnKey meta of each job. Every job is placed in the first stage
+// following all the jobs it depends on, so jobs without dependencies are
+// deployed together in the first stage. Template names within a stage are
+// sorted, so the deploy order is stable between runs.
+func deployStages(templates map[string]ParsedTemplate) ([][]string, error) {
+
+	// Map each job ID to the template defining it, so dependencies, which
+	// are expressed as job IDs, can be resolved to templates.
+	tplByJobID := make(map[string]string, len(templates))
+	for tplName, tpl := range templates {
+		tplByJobID[*tpl.Job().ID] = tplName
+	}
+
+	dependsOn := make(map[string][]string, len(templates))
+	for tplName, tpl := range templates {
+		for _, dep := range jobDependencies(tpl) {
+			depTpl, ok := tplByJobID[dep]
+			if !ok {
+				return nil, fmt.Errorf("job %q depends on job %q which is not part of the pack",
+					*tpl.Job().ID, dep)
+			}
+			if depTpl == tplName {
+				return nil, fmt.Errorf("job %q depends on itself", dep)
+			}
+			dependsOn[tplName] = append(dependsOn[tplName], depTpl)
+		}
+	}
+
+	var stages [][]string
+	placed := make(map[string]struct{}, len(templates))
+
+	for len(placed) < len(templates) {
+		var stage []string
+		for tplName := range templates {
+			if _, ok := placed[tplName]; ok {
+				continue
+			}
+			ready := true
+			for _, depTpl := range dependsOn[tplName] {
+				if _, ok := placed[depTpl]; !ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				stage = append(stage, tplName)
+			}
+		}
+
+		// If nothing could be placed, the remaining jobs depend on each other.
+		if len(stage) == 0 {
+			var cycle []string
+			for tplName, tpl := range templates {
+				if _, ok := placed[tplName]; !ok {
+					cycle = append(cycle, *tpl.Job().ID)
+				}
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("dependency cycle between jobs %s", strings.Join(cycle, ", "))
+		}
+
+		sort.Strings(stage)
+		for _, tplName := range stage {
+			placed[tplName] = struct{}{}
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
+
+// jobDependencies parses the comma separated job IDs of the PackDependsOnKey
+// meta value.
+func jobDependencies(tpl ParsedTemplate) []string {
+	value, ok := tpl.Job().Meta[PackDependsOnKey]
+	if !ok {
+		return nil
+	}
+
+	var deps []string
+	for _, dep := range strings.Split(value, ",") {
+		dep = strings.TrimSpace(dep)
+		if dep != "" && !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
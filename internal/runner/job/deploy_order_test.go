@@ -0,0 +1,100 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+)
+
+func testParsedTemplate(id, dependsOn string) ParsedTemplate {
+	job := &api.Job{ID: &id, Meta: map[string]string{}}
+	if dependsOn != "" {
+		job.Meta[PackDependsOnKey] = dependsOn
+	}
+	return ParsedTemplate{original: job, canonical: job}
+}
+
+func TestDeployStages(t *testing.T) {
+	testCases := []struct {
+		name      string
+		templates map[string]ParsedTemplate
+		expected  [][]string
+		expectErr string
+	}{
+		{
+			name: "no dependencies",
+			templates: map[string]ParsedTemplate{
+				"pack/templates/b.nomad": testParsedTemplate("b", ""),
+				"pack/templates/a.nomad": testParsedTemplate("a", ""),
+			},
+			expected: [][]string{
+				{"pack/templates/a.nomad", "pack/templates/b.nomad"},
+			},
+		},
+		{
+			name: "migration before api",
+			templates: map[string]ParsedTemplate{
+				"pack/templates/db.nomad":      testParsedTemplate("db", ""),
+				"pack/templates/migrate.nomad": testParsedTemplate("migrate", "db"),
+				"pack/templates/api.nomad":     testParsedTemplate("api", "db, migrate"),
+				"pack/templates/cache.nomad":   testParsedTemplate("cache", ""),
+			},
+			expected: [][]string{
+				{"pack/templates/cache.nomad", "pack/templates/db.nomad"},
+				{"pack/templates/migrate.nomad"},
+				{"pack/templates/api.nomad"},
+			},
+		},
+		{
+			name: "dependency in dependent pack",
+			templates: map[string]ParsedTemplate{
+				"pack/templates/api.nomad":              testParsedTemplate("api", "postgres,"),
+				"pack/deps/pg/templates/postgres.nomad": testParsedTemplate("postgres", ""),
+			},
+			expected: [][]string{
+				{"pack/deps/pg/templates/postgres.nomad"},
+				{"pack/templates/api.nomad"},
+			},
+		},
+		{
+			name: "unknown dependency",
+			templates: map[string]ParsedTemplate{
+				"pack/templates/api.nomad": testParsedTemplate("api", "db"),
+			},
+			expectErr: `job "api" depends on job "db" which is not part of the pack`,
+		},
+		{
+			name: "self dependency",
+			templates: map[string]ParsedTemplate{
+				"pack/templates/api.nomad": testParsedTemplate("api", "api"),
+			},
+			expectErr: `job "api" depends on itself`,
+		},
+		{
+			name: "cycle",
+			templates: map[string]ParsedTemplate{
+				"pack/templates/a.nomad": testParsedTemplate("a", "c"),
+				"pack/templates/b.nomad": testParsedTemplate("b", "a"),
+				"pack/templates/c.nomad": testParsedTemplate("c", "b"),
+				"pack/templates/d.nomad": testParsedTemplate("d", ""),
+			},
+			expectErr: "dependency cycle between jobs a, b, c",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stages, err := deployStages(tc.templates)
+			if tc.expectErr != "" {
+				must.EqError(t, err, tc.expectErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expected, stages)
+		})
+	}
+}
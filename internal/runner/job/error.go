@@ -13,6 +13,8 @@ import (
 const (
 	validationSubjParseFailed = "failed to parse job specification"
 	validationSubjConflict    = "failed job conflict validation"
+	validationSubjInvalidHook = "invalid hook job specification"
+	validationSubjInvalidTest = "invalid test job specification"
 )
 
 var (
@@ -0,0 +1,79 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// jobRegisteredEvent is the data of the event emitted when a job of the
+// deployment is registered with Nomad.
+type jobRegisteredEvent struct {
+	Deployment string `json:"deployment"`
+	Template   string `json:"template"`
+	JobID      string `json:"job_id"`
+	Namespace  string `json:"namespace,omitempty"`
+
+	// EvalID is empty for periodic and parameterized jobs, which are not
+	// evaluated when registered.
+	EvalID   string `json:"eval_id,omitempty"`
+	Warnings string `json:"warnings,omitempty"`
+}
+
+// planEvent is the data of the event emitted for each job, and each region of
+// a multi-region job, planned by PlanDeployment.
+type planEvent struct {
+	Deployment string `json:"deployment"`
+	JobID      string `json:"job_id"`
+	Region     string `json:"region,omitempty"`
+
+	// Changes is true when the plan places, stops or updates allocations.
+	Changes bool `json:"changes"`
+
+	Diff           *api.JobDiff                     `json:"diff,omitempty"`
+	DesiredUpdates map[string]*api.DesiredUpdates   `json:"desired_updates,omitempty"`
+	FailedAllocs   map[string]*api.AllocationMetric `json:"failed_allocs,omitempty"`
+	Preemptions    []*api.AllocationListStub        `json:"preemptions,omitempty"`
+	JobModifyIndex uint64                           `json:"job_modify_index"`
+	Warnings       string                           `json:"warnings,omitempty"`
+}
+
+func (r *Runner) emitJobRegistered(ui terminal.UI, tplName string, job *api.Job, resp *api.JobRegisterResponse, evalID string) {
+	event := &jobRegisteredEvent{
+		Deployment: r.runnerCfg.DeploymentName,
+		Template:   tplName,
+		JobID:      *job.ID,
+		EvalID:     evalID,
+		Warnings:   resp.Warnings,
+	}
+	if job.Namespace != nil {
+		event.Namespace = *job.Namespace
+	}
+	terminal.Event(ui, terminal.EventJobRegistered, terminal.LevelInfo,
+		fmt.Sprintf("Job %q registered", *job.ID), event)
+}
+
+func (r *Runner) emitPlan(ui terminal.UI, job *api.Job, resp *api.JobPlanResponse, region string) {
+	event := &planEvent{
+		Deployment:     r.runnerCfg.DeploymentName,
+		JobID:          *job.ID,
+		Region:         region,
+		Changes:        getExitCode(resp) == runner.PlanCodeUpdates,
+		Diff:           resp.Diff,
+		FailedAllocs:   resp.FailedTGAllocs,
+		JobModifyIndex: resp.JobModifyIndex,
+		Warnings:       resp.Warnings,
+	}
+	if resp.Annotations != nil {
+		event.DesiredUpdates = resp.Annotations.DesiredTGUpdates
+		event.Preemptions = resp.Annotations.PreemptedAllocs
+	}
+	terminal.Event(ui, terminal.EventPlan, terminal.LevelInfo,
+		fmt.Sprintf("Plan of job %q", *job.ID), event)
+}
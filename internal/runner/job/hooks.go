@@ -0,0 +1,154 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// Hook phases are the sub-directories of a pack's templates/hooks directory.
+// Templates within them are deployed as hook jobs at that point of a Deploy
+// rather than as regular pack jobs.
+const (
+	HookPhasePreRun  = "pre-run"
+	HookPhasePostRun = "post-run"
+)
+
+// hookPhases lists the supported hook phases.
+var hookPhases = []string{HookPhasePreRun, HookPhasePostRun}
+
+// hookPhase returns the hook phase of the template, or an empty string if the
+// template is not a hook.
+func hookPhase(tplName string) string {
+	for _, phase := range hookPhases {
+		if strings.Contains(tplName, "templates/hooks/"+phase+"/") {
+			return phase
+		}
+	}
+	return ""
+}
+
+// IsHookTemplate returns whether the template is a hook job template found
+// within one of the templates/hooks phase directories.
+func IsHookTemplate(tplName string) bool {
+	return hookPhase(tplName) != ""
+}
+
+// validateHook checks that the hook template defines a job which runs to
+// completion, since a hook must finish before the deploy continues.
+func validateHook(tplName string, job *api.Job) *errors.WrappedUIContext {
+	if isRunToCompletion(job) {
+		return nil
+	}
+	return newValidationDeployerError(
+		fmt.Errorf("hook job %q must be a batch job which is neither periodic nor parameterized", *job.ID),
+		validationSubjInvalidHook, tplName)
+}
+
+// isRunToCompletion returns whether the job is a batch job which runs once
+// when registered.
+func isRunToCompletion(job *api.Job) bool {
+	return job.Type != nil && *job.Type == api.JobTypeBatch && !job.IsPeriodic() && !job.IsParameterized()
+}
+
+// hookTemplateNames returns the sorted names of the hook templates of the
+// given phase.
+func (r *Runner) hookTemplateNames(phase string) []string {
+	var names []string
+	for tplName := range r.hookTemplates {
+		if hookPhase(tplName) == phase {
+			names = append(names, tplName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runHooks deploys the hook jobs of the phase, waits for them to complete,
+// and then purges them. Hook jobs of the same phase run concurrently. A hook
+// job which fails is left in Nomad so its allocations can be inspected, and
+// is replaced the next time the hook runs.
+func (r *Runner) runHooks(ui terminal.UI, errorContext *errors.UIErrorContext, phase string) *errors.WrappedUIContext {
+	names := r.hookTemplateNames(phase)
+	if len(names) == 0 {
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Running %s hooks of pack deployment '%s'", phase, r.runnerCfg.DeploymentName))
+
+	var evalIDs []string
+	for _, tplName := range names {
+		hook := r.hookTemplates[tplName]
+
+		tplErrorContext := errorContext.Copy()
+		tplErrorContext.Add(errors.UIContextPrefixTemplateName, tplName)
+
+		// Purge any earlier run of the hook. Re-registering an unchanged
+		// batch job which has already completed would not run it again.
+		if err := r.purgeHook(hook); err != nil && !errIsNotFound(err) {
+			return &errors.WrappedUIContext{
+				Err:     err,
+				Subject: fmt.Sprintf("failed to purge previous run of hook job '%s'", *hook.Job().ID),
+				Context: tplErrorContext,
+			}
+		}
+
+		result, _, err := r.client.Jobs().RegisterOpts(hook.Job(), &api.RegisterOptions{
+			PolicyOverride: r.cfg.RunConfig.PolicyOverride,
+			Submission: &api.JobSubmission{
+				Source: r.rawTemplates[tplName],
+				Format: "hcl2",
+			},
+		}, r.newWriteOptsFromJob(hook))
+		if err != nil {
+			return generateRegisterError(err, tplErrorContext, hook.GetName())
+		}
+
+		if result.Warnings != "" {
+			ui.Warning(fmt.Sprintf("Job Warnings:\n%s[reset]\n", result.Warnings))
+		}
+		ui.Info(fmt.Sprintf("Evaluation ID: %s", result.EvalID))
+		ui.Info(fmt.Sprintf("Hook job '%s' in pack deployment '%s' registered successfully",
+			*hook.Job().ID, r.runnerCfg.DeploymentName))
+
+		evalIDs = append(evalIDs, result.EvalID)
+	}
+
+	// Without a way to wait for the hooks, they cannot be safely purged, so
+	// they are left to be garbage collected by Nomad.
+	if r.stageWaiter == nil {
+		return nil
+	}
+
+	if err := r.stageWaiter(evalIDs); err != nil {
+		return &errors.WrappedUIContext{
+			Err:     err,
+			Subject: fmt.Sprintf("%s hooks failed", phase),
+			Context: errorContext.Copy(),
+		}
+	}
+
+	for _, tplName := range names {
+		hook := r.hookTemplates[tplName]
+		if err := r.purgeHook(hook); err != nil {
+			ui.Warning(fmt.Sprintf("Failed to purge completed hook job '%s': %s", *hook.Job().ID, err))
+			continue
+		}
+		ui.Info(fmt.Sprintf("Hook job '%s' completed and was purged", *hook.Job().ID))
+	}
+
+	return nil
+}
+
+func (r *Runner) purgeHook(hook ParsedTemplate) error {
+	_, _, err := r.client.Jobs().DeregisterOpts(*hook.Job().ID, &api.DeregisterOptions{Purge: true}, r.newWriteOptsFromJob(hook))
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+)
+
+func TestHookPhase(t *testing.T) {
+	testCases := []struct {
+		tplName  string
+		expected string
+	}{
+		{"my_pack/templates/app.nomad.tpl", ""},
+		{"my_pack/templates/hooks/pre-run/migrate.nomad.tpl", HookPhasePreRun},
+		{"my_pack/templates/hooks/post-run/smoke.nomad.tpl", HookPhasePostRun},
+		{"my_pack/deps/db/templates/hooks/pre-run/init.nomad.tpl", HookPhasePreRun},
+		{"my_pack/templates/hooks/pre-destroy/cleanup.nomad.tpl", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tplName, func(t *testing.T) {
+			must.Eq(t, tc.expected, hookPhase(tc.tplName))
+			must.Eq(t, tc.expected != "", IsHookTemplate(tc.tplName))
+		})
+	}
+}
+
+func TestValidateHook(t *testing.T) {
+	batch := testHookJob("migrate", api.JobTypeBatch)
+	must.Nil(t, validateHook("tpl", batch))
+
+	service := testHookJob("migrate", api.JobTypeService)
+	err := validateHook("tpl", service)
+	must.NotNil(t, err)
+	must.Eq(t, validationSubjInvalidHook, err.Subject)
+
+	periodic := testHookJob("migrate", api.JobTypeBatch)
+	enabled := true
+	periodic.Periodic = &api.PeriodicConfig{Enabled: &enabled}
+	must.NotNil(t, validateHook("tpl", periodic))
+}
+
+func testHookJob(id, jobType string) *api.Job {
+	return &api.Job{ID: &id, Name: &id, Type: &jobType}
+}
+
+// fakeHookAPI records the job registrations and purges made while running
+// hooks.
+type fakeHookAPI struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeHookAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch req.Method {
+	case http.MethodPut, http.MethodPost:
+		var reg api.JobRegisterRequest
+		_ = json.NewDecoder(req.Body).Decode(&reg)
+		f.calls = append(f.calls, "register "+*reg.Job.ID)
+		_ = json.NewEncoder(w).Encode(&api.JobRegisterResponse{EvalID: "eval-" + *reg.Job.ID})
+	case http.MethodDelete:
+		f.calls = append(f.calls, "purge "+req.URL.Path[len("/v1/job/"):])
+		_ = json.NewEncoder(w).Encode(&api.JobDeregisterResponse{})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRunner_RunHooks(t *testing.T) {
+	fake := &fakeHookAPI{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	must.NoError(t, err)
+
+	migrate := testHookJob("migrate", api.JobTypeBatch)
+	smoke := testHookJob("smoke", api.JobTypeBatch)
+
+	var waitedOn []string
+	r := &Runner{
+		client:    client,
+		cfg:       &CLIConfig{RunConfig: &RunCLIConfig{}},
+		runnerCfg: &runner.Config{DeploymentName: "test"},
+		hookTemplates: map[string]ParsedTemplate{
+			"pack/templates/hooks/pre-run/migrate.nomad.tpl": {original: migrate, canonical: migrate},
+			"pack/templates/hooks/post-run/smoke.nomad.tpl":  {original: smoke, canonical: smoke},
+		},
+		stageWaiter: func(evalIDs []string) error {
+			waitedOn = append(waitedOn, evalIDs...)
+			return nil
+		},
+	}
+
+	var out bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &out, &out)
+
+	must.Nil(t, r.runHooks(ui, errors.NewUIErrorContext(), HookPhasePreRun))
+
+	// Only the hooks of the requested phase run, any earlier run of the hook
+	// is purged first, and the hook is purged again once it has completed.
+	must.Eq(t, []string{"purge migrate", "register migrate", "purge migrate"}, fake.calls)
+	must.Eq(t, []string{"eval-migrate"}, waitedOn)
+
+	// A failing hook is left in place for inspection.
+	fake.calls = nil
+	r.stageWaiter = func([]string) error { return errors.New("allocation failed") }
+
+	wErr := r.runHooks(ui, errors.NewUIErrorContext(), HookPhasePostRun)
+	must.NotNil(t, wErr)
+	must.Eq(t, "post-run hooks failed", wErr.Subject)
+	must.Eq(t, []string{"purge smoke", "register smoke"}, fake.calls)
+}
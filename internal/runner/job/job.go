@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/jobspec2"
 
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
 	"github.com/hashicorp/nomad-pack/internal/runner"
@@ -29,13 +30,36 @@ type Runner struct {
 	rawTemplates    map[string]string
 	parsedTemplates map[string]ParsedTemplate
 
+	// hookTemplates contains the parsed templates found within the pack's
+	// templates/hooks directory. These are kept apart from parsedTemplates
+	// as they are run and purged during Deploy rather than being part of
+	// the deployed pack.
+	hookTemplates map[string]ParsedTemplate
+
+	// testTemplates contains the parsed templates found within the pack's
+	// templates/tests directory. They are only dispatched by DispatchTests
+	// and are never deployed as part of the pack.
+	testTemplates map[string]ParsedTemplate
+
 	// deployedJobs tracks the jobs that have successfully been deployed to
 	// Nomad so that in the event of a failure, we can attempt to rollback.
 	deployedJobs []ParsedTemplate
 
+	// snapshots tracks the previous state of each job changed by an atomic
+	// Deploy, so that the jobs can be reverted if the deploy fails.
+	snapshots []jobSnapshot
+
+	// stageWaiter is called between the stages of an ordered deployment to
+	// wait for the previous stage to complete.
+	stageWaiter runner.StageWaitFunc
+
 	// evalIDs tracks the evaluation IDs returned from job registrations
 	// during Deploy. These can be used for deployment monitoring.
 	evalIDs []string
+
+	// planResults tracks the result of planning each template during
+	// PlanDeployment, so the plan can be saved and applied later.
+	planResults map[string]*PlanResult
 }
 
 type ParsedTemplate struct {
@@ -60,7 +84,8 @@ func (p *ParsedTemplate) Job() *api.Job {
 }
 
 // NewDeployer returns the job implementation of deploy.Deployer. This is
-// responsible for handling packs that contain job specifications.
+// responsible for handling packs that contain job specifications. A nil
+// client may be passed when the templates only need to be parsed.
 //
 // TODO(jrasell): design a nice method to have the QueryOpts setup once and
 // available to all subsystems that use a Nomad client.
@@ -70,6 +95,8 @@ func NewDeployer(client *api.Client, cfg *CLIConfig) runner.Runner {
 		cfg:             cfg,
 		rawTemplates:    make(map[string]string),
 		parsedTemplates: make(map[string]ParsedTemplate),
+		hookTemplates:   make(map[string]ParsedTemplate),
+		testTemplates:   make(map[string]ParsedTemplate),
 	}
 }
 
@@ -90,79 +117,200 @@ func (r *Runner) CanonicalizeTemplates() []*errors.WrappedUIContext {
 // runner.Runner interface.
 func (r *Runner) ParsedTemplates() any { return r.parsedTemplates }
 
+// PlanResults satisfies the PlanResults function of the runner.Runner
+// interface.
+func (r *Runner) PlanResults() any { return r.planResults }
+
 // Name satisfies the Name function of the runner.Runner interface.
 func (r *Runner) Name() string { return "job" }
 
-// EvalIDs returns the evaluation IDs from the most recent Deploy call.
+// EvalIDs returns the evaluation IDs from the most recent Deploy call which
+// have not already been waited on by the stage waiter.
 func (r *Runner) EvalIDs() []string { return r.evalIDs }
 
+// SetStageWaiter satisfies the SetStageWaiter function of the runner.Runner
+// interface.
+func (r *Runner) SetStageWaiter(fn runner.StageWaitFunc) { r.stageWaiter = fn }
+
 // Deploy satisfies the Deploy function of the runner.Runner interface.
 func (r *Runner) Deploy(ui terminal.UI, errorContext *errors.UIErrorContext) *errors.WrappedUIContext {
 
-	// Clear any previous eval IDs
+	// Clear any previous eval IDs and snapshots
 	r.evalIDs = nil
+	r.snapshots = nil
+
+	// Order the jobs using their declared dependencies before registering
+	// anything, so an invalid ordering does not leave a partial deployment.
+	stages, err := deployStages(r.parsedTemplates)
+	if err != nil {
+		return &errors.WrappedUIContext{
+			Err:     err,
+			Subject: "failed to determine job deployment order",
+			Context: errorContext.Copy(),
+		}
+	}
 
-	for tplName, jobSpec := range r.parsedTemplates {
-
-		// tplErrorContext forms the basis for error output context as is
-		// appended to when new information becomes available.
-		tplErrorContext := errorContext.Copy()
-		tplErrorContext.Add(errors.UIContextPrefixTemplateName, tplName)
+	// Pre-run hooks must complete before any of the pack's jobs are touched.
+	// Nothing has been deployed yet, so there is nothing to undo on failure.
+	if err := r.runHooks(ui, errorContext, HookPhasePreRun); err != nil {
+		return err
+	}
 
-		// submit the source of the job to Nomad, too
-		submission := &api.JobSubmission{
-			Source: r.rawTemplates[tplName],
-			Format: "hcl2",
-		}
+	// Post-run hooks need the pack's jobs to be running, so the final stage
+	// must be waited on here rather than being left to the caller.
+	hasPostRunHooks := len(r.hookTemplateNames(HookPhasePostRun)) > 0
 
-		registerOpts := api.RegisterOptions{
-			EnforceIndex:      r.cfg.RunConfig.CheckIndex > 0,
-			ModifyIndex:       r.cfg.RunConfig.CheckIndex,
-			PolicyOverride:    r.cfg.RunConfig.PolicyOverride,
-			PreserveCounts:    r.cfg.RunConfig.PreserveCounts,
-			PreserveResources: r.cfg.RunConfig.PreserveResources,
-			Submission:        submission,
+	for i, stage := range stages {
+		if len(stages) > 1 {
+			ui.Info(fmt.Sprintf("Deploying stage %d of %d of pack deployment '%s'",
+				i+1, len(stages), r.runnerCfg.DeploymentName))
 		}
 
-		// Submit the job
-		result, _, err := r.client.Jobs().RegisterOpts(jobSpec.Job(), &registerOpts, r.newWriteOptsFromJob(jobSpec))
-		if err != nil {
-			r.rollback(ui)
-			return generateRegisterError(err, tplErrorContext, jobSpec.GetName())
+		for _, tplName := range stage {
+			if err := r.deployJob(ui, errorContext, tplName, r.parsedTemplates[tplName]); err != nil {
+				return err
+			}
 		}
 
-		// Print any warnings if there are any
-		if result.Warnings != "" {
-			ui.Warning(fmt.Sprintf("Job Warnings:\n%s[reset]\n", result.Warnings))
+		// The final stage is left for the caller to monitor, as it is for an
+		// unordered deployment.
+		if (i == len(stages)-1 && !hasPostRunHooks) || r.stageWaiter == nil {
+			continue
 		}
 
-		// Handle output formatting based on job configuration
-		if jobSpec.Job().IsPeriodic() && !jobSpec.Job().IsParameterized() {
-			r.handlePeriodicJobResponse(ui, jobSpec.Job())
-		} else if !jobSpec.Job().IsParameterized() {
-			ui.Info(fmt.Sprintf("Evaluation ID: %s", result.EvalID))
-			// Store eval ID for deployment monitoring
-			r.evalIDs = append(r.evalIDs, result.EvalID)
+		ui.Info(fmt.Sprintf("Waiting for stage %d of pack deployment '%s' to complete", i+1, r.runnerCfg.DeploymentName))
+		if err := r.stageWaiter(r.evalIDs); err != nil {
+			r.undoDeploy(ui, errorContext)
+			return &errors.WrappedUIContext{
+				Err:     err,
+				Subject: fmt.Sprintf("stage %d of the deployment failed", i+1),
+				Context: errorContext.Copy(),
+			}
 		}
-
-		r.deployedJobs = append(r.deployedJobs, jobSpec)
-		ui.Info(fmt.Sprintf("Job '%s' in pack deployment '%s' registered successfully",
-			*jobSpec.Job().ID, r.runnerCfg.DeploymentName))
+		r.evalIDs = nil
 	}
 
 	// After deploying all current jobs, reconcile with Nomad to stop any jobs
 	// that were previously part of this deployment but are no longer in the
 	// pack definition.
 	if err := r.stopRemovedJobs(ui, errorContext); err != nil {
+		if r.cfg.RunConfig.Atomic {
+			r.revertAfterFailure(ui, errorContext)
+		}
+		return err
+	}
+
+	if err := r.runHooks(ui, errorContext, HookPhasePostRun); err != nil {
+		r.undoDeploy(ui, errorContext)
 		return err
 	}
 
 	return nil
 }
 
-// rollback begins a thought experiment about how to handle failures. It is not
-// targeted for the initial release, but will be plumbed for experimentation.
-// The flag is currently hidden and defaults to false.
+// deployJob registers a single parsed template with Nomad.
+func (r *Runner) deployJob(ui terminal.UI, errorContext *errors.UIErrorContext, tplName string, jobSpec ParsedTemplate) *errors.WrappedUIContext {
+
+	// tplErrorContext forms the basis for error output context as is
+	// appended to when new information becomes available.
+	tplErrorContext := errorContext.Copy()
+	tplErrorContext.Add(errors.UIContextPrefixTemplateName, tplName)
+
+	// submit the source of the job to Nomad, too
+	submission := &api.JobSubmission{
+		Source: r.rawTemplates[tplName],
+		Format: "hcl2",
+	}
+
+	// A job applied from a saved plan is only registered if it has not
+	// changed since it was planned.
+	enforceIndex, modifyIndex := r.cfg.RunConfig.CheckIndex > 0, r.cfg.RunConfig.CheckIndex
+	if index, ok := r.cfg.RunConfig.CheckIndexes[tplName]; ok {
+		enforceIndex, modifyIndex = true, index
+	}
+
+	registerOpts := api.RegisterOptions{
+		EnforceIndex:      enforceIndex,
+		ModifyIndex:       modifyIndex,
+		PolicyOverride:    r.cfg.RunConfig.PolicyOverride,
+		PreserveCounts:    r.cfg.RunConfig.PreserveCounts,
+		PreserveResources: r.cfg.RunConfig.PreserveResources,
+		Submission:        submission,
+	}
+
+	// In atomic mode, capture the version the job is currently at so it
+	// can be reverted to if any part of the deploy fails.
+	var snap jobSnapshot
+	if r.cfg.RunConfig.Atomic {
+		var err error
+		if snap, err = r.snapshotJob(jobSpec); err != nil {
+			r.revertAfterFailure(ui, errorContext)
+			return &errors.WrappedUIContext{
+				Err:     err,
+				Subject: "failed to snapshot job before registration",
+				Context: tplErrorContext,
+			}
+		}
+	}
+
+	// Submit the job
+	result, _, err := r.client.Jobs().RegisterOpts(jobSpec.Job(), &registerOpts, r.newWriteOptsFromJob(jobSpec))
+	if err != nil {
+		r.undoDeploy(ui, errorContext)
+		return generateRegisterError(err, tplErrorContext, jobSpec.GetName())
+	}
+
+	if r.cfg.RunConfig.Atomic {
+		r.snapshots = append(r.snapshots, snap)
+	}
+
+	// Print any warnings if there are any
+	if result.Warnings != "" {
+		ui.Warning(fmt.Sprintf("Job Warnings:\n%s[reset]\n", result.Warnings))
+	}
+
+	// Handle output formatting based on job configuration
+	var evalID string
+	if jobSpec.Job().IsPeriodic() && !jobSpec.Job().IsParameterized() {
+		r.handlePeriodicJobResponse(ui, jobSpec.Job())
+	} else if !jobSpec.Job().IsParameterized() {
+		evalID = result.EvalID
+		ui.Info(fmt.Sprintf("Evaluation ID: %s", evalID))
+		// Store eval ID for deployment monitoring
+		r.evalIDs = append(r.evalIDs, evalID)
+	}
+
+	r.deployedJobs = append(r.deployedJobs, jobSpec)
+	r.emitJobRegistered(ui, tplName, jobSpec.Job(), result, evalID)
+	ui.Info(fmt.Sprintf("Job '%s' in pack deployment '%s' registered successfully",
+		*jobSpec.Job().ID, r.runnerCfg.DeploymentName))
+
+	return nil
+}
+
+// undoDeploy undoes the jobs registered so far by a failed Deploy, either by
+// reverting them when atomic, or purging them when rollback is enabled.
+func (r *Runner) undoDeploy(ui terminal.UI, errorContext *errors.UIErrorContext) {
+	if r.cfg.RunConfig.Atomic {
+		r.revertAfterFailure(ui, errorContext)
+	} else {
+		r.rollback(ui)
+	}
+}
+
+// revertAfterFailure reverts the jobs changed so far by an atomic Deploy. The
+// deploy error is what gets returned to the caller, so any failure to revert
+// is printed here.
+func (r *Runner) revertAfterFailure(ui terminal.UI, errorContext *errors.UIErrorContext) {
+	for _, revertErr := range r.RevertDeployment(ui, errorContext) {
+		ui.ErrorWithContext(revertErr.Err, revertErr.Subject, revertErr.Context.GetAll()...)
+	}
+}
+
+// rollback purges the jobs registered so far when a Deploy fails part way
+// through. It only undoes the current run; reverting a deployment to an
+// earlier release is handled by the rollback command using the recorded
+// release history. The flag is currently hidden and defaults to false.
 func (r *Runner) rollback(ui terminal.UI) {
 
 	if !r.cfg.RunConfig.EnableRollback {
@@ -224,7 +372,7 @@ func (r *Runner) ParseTemplates() []*errors.WrappedUIContext {
 		// Use HCL-based parsing instead of regex to avoid falsely matching pack
 		// variables named "region" or "namespace" as Nomad job settings.
 		region, namespace, err := ExtractJobRegionNamespace(tpl)
-		if err == nil {
+		if err == nil && r.client != nil {
 			if namespace != "" {
 				r.client.SetNamespace(namespace)
 			}
@@ -233,10 +381,7 @@ func (r *Runner) ParseTemplates() []*errors.WrappedUIContext {
 			}
 		}
 
-		ncJob, err := r.client.Jobs().ParseHCLOpts(&api.JobsParseRequest{
-			JobHCL:       tpl,
-			Canonicalize: false,
-		})
+		ncJob, err := r.parseJob(tpl, false)
 		if err != nil {
 			outputErrors = append(
 				outputErrors,
@@ -245,10 +390,7 @@ func (r *Runner) ParseTemplates() []*errors.WrappedUIContext {
 			continue
 		}
 
-		job, err := r.client.Jobs().ParseHCLOpts(&api.JobsParseRequest{
-			JobHCL:       tpl,
-			Canonicalize: true,
-		})
+		job, err := r.parseJob(tpl, true)
 		if err != nil {
 			outputErrors = append(
 				outputErrors,
@@ -264,11 +406,56 @@ func (r *Runner) ParseTemplates() []*errors.WrappedUIContext {
 
 		// This could probably be a leaner object, but this will provide the
 		// highest resolution view of the original parsed job.
-		r.parsedTemplates[tplName] = ParsedTemplate{
+		parsed := ParsedTemplate{
 			original:  ncJob,
 			canonical: job,
 		}
+
+		if hookPhase(tplName) != "" {
+			if err := validateHook(tplName, job); err != nil {
+				outputErrors = append(outputErrors, err)
+				continue
+			}
+			r.hookTemplates[tplName] = parsed
+			continue
+		}
+
+		if isTestTemplate(tplName) {
+			if err := validateTest(tplName, job); err != nil {
+				outputErrors = append(outputErrors, err)
+				continue
+			}
+			r.testTemplates[tplName] = parsed
+			continue
+		}
+
+		r.parsedTemplates[tplName] = parsed
 	}
 
 	return outputErrors
 }
+
+// parseJob parses the job template using the Nomad API. When the runner has
+// no client, the template is parsed locally in the same way as the Nomad
+// agent would, so packs can be validated without a cluster.
+func (r *Runner) parseJob(tpl string, canonicalize bool) (*api.Job, error) {
+	if r.client != nil {
+		return r.client.Jobs().ParseHCLOpts(&api.JobsParseRequest{
+			JobHCL:       tpl,
+			Canonicalize: canonicalize,
+		})
+	}
+
+	job, err := jobspec2.ParseWithConfig(&jobspec2.ParseConfig{
+		Path:    "input.hcl",
+		Body:    []byte(tpl),
+		AllowFS: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if canonicalize {
+		job.Canonicalize()
+	}
+	return job, nil
+}
@@ -19,6 +19,11 @@ const (
 	PackDeploymentNameKey = "pack.deployment_name"
 	PackJobKey            = "pack.job"
 	PackRefKey            = "pack.version"
+
+	// PackDependsOnKey is set by pack authors, rather than nomad-pack, to a
+	// comma separated list of the IDs of other jobs in the pack which must be
+	// deployed before the job.
+	PackDependsOnKey = "pack.depends_on"
 )
 
 // setHCLMeta sets the nomad-pack metadata in the HCL job definition, merging
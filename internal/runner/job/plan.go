@@ -24,6 +24,17 @@ changed, another user has modified the job and the plan's results are
 potentially invalid.`
 )
 
+// PlanResult is the result of planning a single template, as returned by
+// Runner.PlanResults.
+type PlanResult struct {
+	// JobModifyIndex is the modify index of the job when it was planned, or
+	// zero if it does not yet exist.
+	JobModifyIndex uint64
+
+	// Diff is the diff of the plan, if one was requested.
+	Diff *api.JobDiff
+}
+
 // PlanDeployment satisfies the PlanDeployment function of the runner.Runner
 // interface.
 func (r *Runner) PlanDeployment(ui terminal.UI, errCtx *errors.UIErrorContext) (int, []*errors.WrappedUIContext) {
@@ -38,6 +49,8 @@ func (r *Runner) PlanDeployment(ui terminal.UI, errCtx *errors.UIErrorContext) (
 		return runner.PlanCodeError, outputErrors
 	}
 
+	r.planResults = make(map[string]*PlanResult, len(r.parsedTemplates))
+
 	for tplName, parsedJob := range r.parsedTemplates {
 
 		// tplErrorContext forms the basis for error output context as is
@@ -53,7 +66,10 @@ func (r *Runner) PlanDeployment(ui terminal.UI, errCtx *errors.UIErrorContext) (
 		}
 
 		if parsedJob.Job().IsMultiregion() {
-			return r.multiRegionPlan(planOpts, parsedJob.Job(), ui, tplErrorContext)
+			code, errs := r.multiRegionPlan(tplName, planOpts, parsedJob.Job(), ui, tplErrorContext)
+			exitCode = runner.HigherPlanCode(exitCode, code)
+			outputErrors = append(outputErrors, errs...)
+			continue
 		}
 
 		// Submit the job
@@ -68,7 +84,12 @@ func (r *Runner) PlanDeployment(ui terminal.UI, errCtx *errors.UIErrorContext) (
 			continue
 		}
 
-		exitCode = runner.HigherPlanCode(exitCode, r.outputPlannedJob(ui, parsedJob.Job(), planResponse))
+		r.planResults[tplName] = &PlanResult{
+			JobModifyIndex: planResponse.JobModifyIndex,
+			Diff:           planResponse.Diff,
+		}
+
+		exitCode = runner.HigherPlanCode(exitCode, r.outputPlannedJob(ui, parsedJob.Job(), planResponse, ""))
 		r.formatJobModifyIndex(planResponse.JobModifyIndex, ui)
 	}
 
@@ -79,6 +100,7 @@ func (r *Runner) PlanDeployment(ui terminal.UI, errCtx *errors.UIErrorContext) (
 }
 
 func (r *Runner) multiRegionPlan(
+	tplName string,
 	opts *api.PlanOptions,
 	job *api.Job,
 	ui terminal.UI,
@@ -118,15 +140,25 @@ func (r *Runner) multiRegionPlan(
 		return exitCode, outputErrors
 	}
 
+	// A multi-region job is registered once, so as with the Nomad CLI, the
+	// modify index of the first region is the one checked when it is run.
+	first := plans[job.Multiregion.Regions[0].Name]
+	r.planResults[tplName] = &PlanResult{
+		JobModifyIndex: first.JobModifyIndex,
+		Diff:           first.Diff,
+	}
+
 	for regionName, resp := range plans {
 		ui.Info(fmt.Sprintf("Region: %q", regionName))
-		exitCode = runner.HigherPlanCode(exitCode, r.outputPlannedJob(ui, job, resp))
+		exitCode = runner.HigherPlanCode(exitCode, r.outputPlannedJob(ui, job, resp, regionName))
 	}
 
 	return exitCode, outputErrors
 }
 
-func (r *Runner) outputPlannedJob(ui terminal.UI, job *api.Job, resp *api.JobPlanResponse) int {
+func (r *Runner) outputPlannedJob(ui terminal.UI, job *api.Job, resp *api.JobPlanResponse, region string) int {
+
+	r.emitPlan(ui, job, resp, region)
 
 	// Print the diff if not disabled
 	if r.cfg.PlanConfig.Diff {
@@ -0,0 +1,72 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+)
+
+func TestRunner_PlanDeployment_Multiregion(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		planned []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		planned = append(planned, strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/v1/job/"), "/plan"))
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(&api.JobPlanResponse{
+			JobModifyIndex: 7,
+			Annotations:    &api.PlanAnnotations{},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	must.NoError(t, err)
+
+	global := testParsedTemplate("global", "")
+	global.canonical.Name = global.canonical.ID
+	global.canonical.Multiregion = &api.Multiregion{
+		Regions: []*api.MultiregionRegion{{Name: "east"}, {Name: "west"}},
+	}
+	local := testParsedTemplate("local", "")
+	local.canonical.Name = local.canonical.ID
+
+	r := &Runner{
+		client:    client,
+		cfg:       &CLIConfig{PlanConfig: &PlanCLIConfig{}},
+		runnerCfg: &runner.Config{DeploymentName: "test"},
+		parsedTemplates: map[string]ParsedTemplate{
+			"global.nomad.tpl": global,
+			"local.nomad.tpl":  local,
+		},
+	}
+
+	var out bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &out, &out)
+
+	code, errs := r.PlanDeployment(ui, errors.NewUIErrorContext())
+	must.Len(t, 0, errs)
+	must.Eq(t, runner.PlanCodeNoUpdates, code)
+
+	// The multiregion job is planned in each region, without stopping the
+	// other templates from being planned.
+	must.SliceContainsAll(t, []string{"global", "global", "local"}, planned)
+	must.MapContainsKeys(t, r.planResults, []string{"global.nomad.tpl", "local.nomad.tpl"})
+}
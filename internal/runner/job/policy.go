@@ -0,0 +1,68 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/policy"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// CheckPolicies evaluates the rules against each parsed job, including the
+// pack's hook jobs as they are run alongside it. Warnings are printed as they
+// are found, while errors are returned.
+func (r *Runner) CheckPolicies(ui terminal.UI, rules []*policy.Rule, errCtx *errors.UIErrorContext) []*errors.WrappedUIContext {
+	if len(r.parsedTemplates) < 1 {
+		return []*errors.WrappedUIContext{newNoParsedTemplatesError("failed to check policies", errCtx)}
+	}
+
+	templates := make(map[string]ParsedTemplate, len(r.parsedTemplates)+len(r.hookTemplates))
+	for tplName, tpl := range r.parsedTemplates {
+		templates[tplName] = tpl
+	}
+	for tplName, tpl := range r.hookTemplates {
+		templates[tplName] = tpl
+	}
+
+	tplNames := make([]string, 0, len(templates))
+	for tplName := range templates {
+		tplNames = append(tplNames, tplName)
+	}
+	sort.Strings(tplNames)
+
+	var outputErrors []*errors.WrappedUIContext
+
+	for _, tplName := range tplNames {
+		tpl := templates[tplName]
+		job := tpl.Job()
+
+		for _, rule := range rules {
+			var errDiags hcl.Diagnostics
+
+			for _, diag := range rule.Check(job) {
+				if diag.Severity == hcl.DiagWarning {
+					ui.Warning(fmt.Sprintf("%s (template %s)", diag.Detail, tplName))
+					continue
+				}
+				errDiags = append(errDiags, diag)
+			}
+
+			for _, wErr := range errors.HCLDiagsToWrappedUIContext(errDiags) {
+				wErr.Context.Add(errors.UIContextPrefixTemplateName, tplName)
+				wErr.Context.Append(errCtx)
+				outputErrors = append(outputErrors, wErr)
+			}
+		}
+	}
+
+	if len(outputErrors) > 0 {
+		return outputErrors
+	}
+	return nil
+}
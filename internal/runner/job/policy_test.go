@@ -0,0 +1,64 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/policy"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+)
+
+func TestRunner_CheckPolicies(t *testing.T) {
+	dir := t.TempDir()
+	must.NoError(t, os.WriteFile(filepath.Join(dir, "policy.hcl"), []byte(`
+rule "no_raw_exec" {
+  condition = !contains([for t in values(tasks) : t.Driver], "raw_exec")
+}
+
+rule "batch_only" {
+  level     = "warning"
+  condition = job.Type == "batch"
+}
+`), 0o644))
+
+	rules, diags := policy.Load(dir)
+	must.False(t, diags.HasErrors())
+
+	withDriver := func(job *api.Job, driver string) *api.Job {
+		job.AddTaskGroup(api.NewTaskGroup("app", 1).AddTask(&api.Task{Name: "app", Driver: driver}))
+		return job
+	}
+	app := withDriver(testHookJob("app", api.JobTypeService), "docker")
+	hook := withDriver(testHookJob("migrate", api.JobTypeBatch), "raw_exec")
+
+	r := &Runner{
+		parsedTemplates: map[string]ParsedTemplate{
+			"pack/templates/app.nomad.tpl": {original: app, canonical: app},
+		},
+		hookTemplates: map[string]ParsedTemplate{
+			"pack/templates/hooks/pre-run/migrate.nomad.tpl": {original: hook, canonical: hook},
+		},
+	}
+
+	var out bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &out, &out)
+
+	policyErrs := r.CheckPolicies(ui, rules, errors.NewUIErrorContext())
+	must.Len(t, 1, policyErrs)
+	must.Eq(t, "Policy rule failed", policyErrs[0].Subject)
+	must.SliceContains(t, policyErrs[0].Context.GetAll(),
+		errors.UIContextPrefixTemplateName+"pack/templates/hooks/pre-run/migrate.nomad.tpl")
+
+	must.StrContains(t, out.String(), `Policy rule "batch_only" failed for job "app".`)
+	must.StrNotContains(t, out.String(), `"migrate"`)
+}
@@ -53,6 +53,22 @@ func (r *Runner) stopRemovedJobs(ui terminal.UI, errorContext *errors.UIErrorCon
 			writeOpts.Namespace = stub.Namespace
 		}
 
+		// In atomic mode, capture the version of the job so stopping it can be
+		// reverted along with the rest of the pack.
+		var snap jobSnapshot
+		if r.cfg.RunConfig.Atomic {
+			var err error
+			if snap, err = r.snapshotStub(stub); err != nil {
+				errCtx := errorContext.Copy()
+				errCtx.Add(errors.UIContextPrefixJobName, stub.ID)
+				return &errors.WrappedUIContext{
+					Err:     err,
+					Subject: fmt.Sprintf("failed to snapshot removed job '%s'", stub.ID),
+					Context: errCtx,
+				}
+			}
+		}
+
 		_, _, err := r.client.Jobs().DeregisterOpts(stub.ID, &api.DeregisterOptions{
 			Purge: false,
 		}, writeOpts)
@@ -66,6 +82,10 @@ func (r *Runner) stopRemovedJobs(ui terminal.UI, errorContext *errors.UIErrorCon
 			}
 		}
 
+		if r.cfg.RunConfig.Atomic {
+			r.snapshots = append(r.snapshots, snap)
+		}
+
 		ui.Success(fmt.Sprintf("Job '%s' stopped successfully", stub.ID))
 	}
 
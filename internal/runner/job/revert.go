@@ -0,0 +1,136 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// jobSnapshot records the state of a job in Nomad before an atomic deploy
+// modified it, so the job can be returned to that state if the deploy fails.
+type jobSnapshot struct {
+	id        string
+	namespace string
+	region    string
+
+	// exists is false when the job was not registered prior to the deploy.
+	// Such jobs have no earlier version to revert to.
+	exists  bool
+	version uint64
+}
+
+func (s jobSnapshot) writeOpts() *api.WriteOptions {
+	return &api.WriteOptions{Namespace: s.namespace, Region: s.region}
+}
+
+// snapshotJob captures the current version of the job described by the
+// parsed template ahead of it being registered.
+func (r *Runner) snapshotJob(jobSpec ParsedTemplate) (jobSnapshot, error) {
+	writeOpts := r.newWriteOptsFromJob(jobSpec)
+	return r.snapshot(*jobSpec.Job().ID, writeOpts.Namespace, writeOpts.Region)
+}
+
+// snapshotStub captures the current version of a job which is about to be
+// stopped because it is no longer part of the pack.
+func (r *Runner) snapshotStub(stub *api.JobListStub) (jobSnapshot, error) {
+	return r.snapshot(stub.ID, stub.Namespace, "")
+}
+
+func (r *Runner) snapshot(id, namespace, region string) (jobSnapshot, error) {
+	snap := jobSnapshot{id: id, namespace: namespace, region: region}
+
+	current, _, err := r.client.Jobs().Info(id, &api.QueryOptions{
+		Namespace: namespace,
+		Region:    region,
+	})
+	if err != nil {
+		if errIsNotFound(err) {
+			return snap, nil
+		}
+		return snap, fmt.Errorf("failed to read current version of job %q: %w", id, err)
+	}
+
+	snap.exists = true
+	if current.Version != nil {
+		snap.version = *current.Version
+	}
+	return snap, nil
+}
+
+// RevertDeployment satisfies the RevertDeployment function of the
+// runner.Runner interface. Every job changed by the most recent atomic Deploy
+// is reverted to the version it had beforehand using the Nomad job revert
+// API. Jobs that did not exist before the deploy are purged, since there is
+// no previous version to return to.
+func (r *Runner) RevertDeployment(ui terminal.UI, errorContext *errors.UIErrorContext) []*errors.WrappedUIContext {
+	var outputErrors []*errors.WrappedUIContext
+
+	if len(r.snapshots) == 0 {
+		return nil
+	}
+
+	ui.Warning(fmt.Sprintf("Reverting jobs in pack deployment '%s' to their previous versions", r.runnerCfg.DeploymentName))
+
+	for _, snap := range r.snapshots {
+		errCtx := errorContext.Copy()
+		errCtx.Add(errors.UIContextPrefixJobName, snap.id)
+
+		if err := r.revertJob(ui, snap); err != nil {
+			outputErrors = append(outputErrors, &errors.WrappedUIContext{
+				Err:     err,
+				Subject: fmt.Sprintf("failed to revert job '%s'", snap.id),
+				Context: errCtx,
+			})
+		}
+	}
+
+	return outputErrors
+}
+
+func (r *Runner) revertJob(ui terminal.UI, snap jobSnapshot) error {
+	if !snap.exists {
+		_, _, err := r.client.Jobs().DeregisterOpts(snap.id, &api.DeregisterOptions{Purge: true}, snap.writeOpts())
+		if err != nil {
+			return err
+		}
+		ui.Info(fmt.Sprintf("Job '%s' did not exist before this deployment and has been purged", snap.id))
+		return nil
+	}
+
+	current, _, err := r.client.Jobs().Info(snap.id, &api.QueryOptions{
+		Namespace: snap.namespace,
+		Region:    snap.region,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Nomad refuses to revert a job to its current version. This happens
+	// when the rendered job was identical to the running one, or Nomad has
+	// already auto-reverted the deployment.
+	currentVersion := *current.Version
+	if currentVersion == snap.version {
+		ui.Info(fmt.Sprintf("Job '%s' is already at version %d", snap.id, snap.version))
+		return nil
+	}
+
+	// Enforcing the prior version guards against reverting over a change
+	// made by someone else since the failed deploy.
+	result, _, err := r.client.Jobs().Revert(snap.id, snap.version, &currentVersion, snap.writeOpts(),
+		r.cfg.RunConfig.ConsulToken, r.cfg.RunConfig.VaultToken)
+	if err != nil {
+		return err
+	}
+
+	if result.EvalID != "" {
+		ui.Info(fmt.Sprintf("Evaluation ID: %s", result.EvalID))
+	}
+	ui.Info(fmt.Sprintf("Job '%s' reverted to version %d", snap.id, snap.version))
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+)
+
+// fakeJobsAPI serves the subset of the Nomad jobs API used when reverting,
+// recording every write it receives.
+type fakeJobsAPI struct {
+	versions map[string]uint64
+
+	mu      sync.Mutex
+	reverts []api.JobRevertRequest
+	purged  []string
+}
+
+func (f *fakeJobsAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case req.Method == http.MethodGet:
+		id := req.URL.Path[len("/v1/job/"):]
+		version, ok := f.versions[id]
+		if !ok {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&api.Job{ID: &id, Version: &version})
+
+	case req.Method == http.MethodPut:
+		var revertReq api.JobRevertRequest
+		_ = json.NewDecoder(req.Body).Decode(&revertReq)
+		f.reverts = append(f.reverts, revertReq)
+		_ = json.NewEncoder(w).Encode(&api.JobRegisterResponse{EvalID: "revert-eval"})
+
+	case req.Method == http.MethodDelete:
+		if req.URL.Query().Get("purge") != "true" {
+			http.Error(w, "job not purged", http.StatusBadRequest)
+			return
+		}
+		f.purged = append(f.purged, req.URL.Path[len("/v1/job/"):])
+		_ = json.NewEncoder(w).Encode(&api.JobDeregisterResponse{EvalID: "stop-eval"})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRunner_RevertDeployment(t *testing.T) {
+	fake := &fakeJobsAPI{
+		versions: map[string]uint64{
+			"upgraded":  4,
+			"unchanged": 2,
+		},
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	must.NoError(t, err)
+
+	r := &Runner{
+		client:    client,
+		cfg:       &CLIConfig{RunConfig: &RunCLIConfig{Atomic: true}},
+		runnerCfg: &runner.Config{DeploymentName: "test"},
+		snapshots: []jobSnapshot{
+			{id: "upgraded", exists: true, version: 3},
+			{id: "unchanged", exists: true, version: 2},
+			{id: "created"},
+		},
+	}
+
+	var out bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &out, &out)
+
+	errs := r.RevertDeployment(ui, errors.NewUIErrorContext())
+	must.Len(t, 0, errs)
+
+	// Only the job that changed version is reverted, guarded by the version
+	// it was found at.
+	must.Len(t, 1, fake.reverts)
+	must.Eq(t, "upgraded", fake.reverts[0].JobID)
+	must.Eq(t, 3, fake.reverts[0].JobVersion)
+	must.NotNil(t, fake.reverts[0].EnforcePriorVersion)
+	must.Eq(t, 4, *fake.reverts[0].EnforcePriorVersion)
+
+	// Jobs created by the deploy have nothing to revert to, so are purged.
+	must.Eq(t, []string{"created"}, fake.purged)
+
+	must.StrContains(t, out.String(), "Job 'upgraded' reverted to version 3")
+	must.StrContains(t, out.String(), "Job 'unchanged' is already at version 2")
+}
+
+func TestRunner_RevertDeployment_NoSnapshots(t *testing.T) {
+	r := &Runner{
+		cfg:       &CLIConfig{RunConfig: &RunCLIConfig{Atomic: true}},
+		runnerCfg: &runner.Config{DeploymentName: "test"},
+	}
+
+	var out bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &out, &out)
+
+	must.Len(t, 0, r.RevertDeployment(ui, errors.NewUIErrorContext()))
+	must.Eq(t, "", out.String())
+}
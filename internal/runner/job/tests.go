@@ -0,0 +1,123 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/terminal"
+)
+
+// DispatchedTest identifies a test job started by DispatchTests.
+type DispatchedTest struct {
+	TemplateName    string
+	JobID           string
+	DispatchedJobID string
+	EvalID          string
+
+	writeOpts *api.WriteOptions
+}
+
+// isTestTemplate returns whether the template is one of the pack's test job
+// templates found within the templates/tests directory.
+func isTestTemplate(tplName string) bool {
+	return strings.Contains(tplName, "templates/tests/")
+}
+
+// validateTest checks that the test template defines a batch job which can be
+// dispatched each time the tests run.
+func validateTest(tplName string, job *api.Job) *errors.WrappedUIContext {
+	if isRunToCompletion(job) {
+		return nil
+	}
+	return newValidationDeployerError(
+		fmt.Errorf("test job %q must be a batch job which is neither periodic nor parameterized", *job.ID),
+		validationSubjInvalidTest, tplName)
+}
+
+// TestTemplateNames returns the sorted names of the parsed test templates.
+func (r *Runner) TestTemplateNames() []string {
+	names := make([]string, 0, len(r.testTemplates))
+	for tplName := range r.testTemplates {
+		names = append(names, tplName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DispatchTests registers each test template as a parameterized batch job
+// and dispatches it. Registering the tests as parameterized jobs means a
+// test's allocations are only created when it is dispatched, and every run
+// of the tests gets its own child job and allocations to report on.
+func (r *Runner) DispatchTests(ui terminal.UI, errorContext *errors.UIErrorContext) ([]*DispatchedTest, *errors.WrappedUIContext) {
+	var dispatched []*DispatchedTest
+
+	for _, tplName := range r.TestTemplateNames() {
+		test := r.testTemplates[tplName]
+
+		tplErrorContext := errorContext.Copy()
+		tplErrorContext.Add(errors.UIContextPrefixTemplateName, tplName)
+
+		testJob := test.Job()
+		testJob.ParameterizedJob = &api.ParameterizedJobConfig{}
+
+		writeOpts := r.newWriteOptsFromJob(test)
+
+		result, _, err := r.client.Jobs().RegisterOpts(testJob, &api.RegisterOptions{
+			PolicyOverride: r.cfg.RunConfig.PolicyOverride,
+		}, writeOpts)
+		if err != nil {
+			return dispatched, generateRegisterError(err, tplErrorContext, test.GetName())
+		}
+		if result.Warnings != "" {
+			ui.Warning(fmt.Sprintf("Job Warnings:\n%s[reset]\n", result.Warnings))
+		}
+
+		resp, _, err := r.client.Jobs().Dispatch(*testJob.ID, nil, nil, "", writeOpts)
+		if err != nil {
+			tplErrorContext.Add(errors.UIContextPrefixJobName, test.GetName())
+			return dispatched, &errors.WrappedUIContext{
+				Err:     err,
+				Subject: "failed to dispatch test job",
+				Context: tplErrorContext,
+			}
+		}
+
+		ui.Info(fmt.Sprintf("Test job '%s' dispatched as '%s'", *testJob.ID, resp.DispatchedJobID))
+
+		dispatched = append(dispatched, &DispatchedTest{
+			TemplateName:    tplName,
+			JobID:           *testJob.ID,
+			DispatchedJobID: resp.DispatchedJobID,
+			EvalID:          resp.EvalID,
+			writeOpts:       writeOpts,
+		})
+	}
+
+	return dispatched, nil
+}
+
+// CleanupTest purges the parameterized test job once the test has finished.
+// The dispatched job is purged along with it when the test passed, and
+// otherwise left in Nomad so its allocations can be inspected. As a
+// dispatched job has a parent, it is never mistaken for a stale pack job.
+func (r *Runner) CleanupTest(test *DispatchedTest, passed bool) error {
+	ids := []string{test.JobID}
+	if passed {
+		ids = append(ids, test.DispatchedJobID)
+	}
+
+	for _, id := range ids {
+		_, _, err := r.client.Jobs().DeregisterOpts(id, &api.DeregisterOptions{Purge: true}, test.writeOpts)
+		if err != nil && !errIsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/shoenig/test/must"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/runner"
+	"github.com/hashicorp/nomad-pack/internal/testui"
+)
+
+func TestIsTestTemplate(t *testing.T) {
+	must.True(t, isTestTemplate("my_pack/templates/tests/smoke.nomad.tpl"))
+	must.True(t, isTestTemplate("my_pack/deps/db/templates/tests/ping.nomad.tpl"))
+	must.False(t, isTestTemplate("my_pack/templates/app.nomad.tpl"))
+	must.False(t, isTestTemplate("my_pack/templates/hooks/post-run/smoke.nomad.tpl"))
+}
+
+func TestValidateTest(t *testing.T) {
+	must.Nil(t, validateTest("tpl", testHookJob("smoke", api.JobTypeBatch)))
+
+	err := validateTest("tpl", testHookJob("smoke", api.JobTypeService))
+	must.NotNil(t, err)
+	must.Eq(t, validationSubjInvalidTest, err.Subject)
+
+	parameterized := testHookJob("smoke", api.JobTypeBatch)
+	parameterized.ParameterizedJob = &api.ParameterizedJobConfig{}
+	must.NotNil(t, validateTest("tpl", parameterized))
+}
+
+// fakeTestAPI records the job registrations, dispatches and purges made while
+// running pack tests.
+type fakeTestAPI struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeTestAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v1/job")
+
+	switch {
+	case req.Method == http.MethodDelete:
+		f.calls = append(f.calls, "purge "+strings.TrimPrefix(path, "/"))
+		_ = json.NewEncoder(w).Encode(&api.JobDeregisterResponse{})
+	case strings.HasSuffix(path, "/dispatch"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/dispatch")
+		f.calls = append(f.calls, "dispatch "+id)
+		_ = json.NewEncoder(w).Encode(&api.JobDispatchResponse{
+			DispatchedJobID: id + "/dispatch-1",
+			EvalID:          "eval-" + id,
+		})
+	default:
+		var reg api.JobRegisterRequest
+		_ = json.NewDecoder(req.Body).Decode(&reg)
+		if reg.Job.ParameterizedJob == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.calls = append(f.calls, "register "+*reg.Job.ID)
+		_ = json.NewEncoder(w).Encode(&api.JobRegisterResponse{})
+	}
+}
+
+func TestRunner_DispatchTests(t *testing.T) {
+	fake := &fakeTestAPI{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	must.NoError(t, err)
+
+	smoke := testHookJob("smoke", api.JobTypeBatch)
+	ping := testHookJob("ping", api.JobTypeBatch)
+
+	r := &Runner{
+		client:    client,
+		cfg:       &CLIConfig{RunConfig: &RunCLIConfig{}},
+		runnerCfg: &runner.Config{DeploymentName: "test"},
+		testTemplates: map[string]ParsedTemplate{
+			"pack/templates/tests/smoke.nomad.tpl": {original: smoke, canonical: smoke},
+			"pack/templates/tests/ping.nomad.tpl":  {original: ping, canonical: ping},
+		},
+	}
+
+	var out bytes.Buffer
+	ui := testui.NonInteractiveTestUI(context.Background(), &out, &out)
+
+	tests, wErr := r.DispatchTests(ui, errors.NewUIErrorContext())
+	must.Nil(t, wErr)
+	must.Len(t, 2, tests)
+	must.Eq(t, "ping", tests[0].JobID)
+	must.Eq(t, "ping/dispatch-1", tests[0].DispatchedJobID)
+	must.Eq(t, "eval-ping", tests[0].EvalID)
+	must.Eq(t, []string{"register ping", "dispatch ping", "register smoke", "dispatch smoke"}, fake.calls)
+
+	// A passing test is purged entirely, while a failing test keeps its
+	// dispatched job for inspection.
+	fake.calls = nil
+	must.NoError(t, r.CleanupTest(tests[0], true))
+	must.NoError(t, r.CleanupTest(tests[1], false))
+	must.Eq(t, []string{"purge ping", "purge ping/dispatch-1", "purge smoke"}, fake.calls)
+}
+
+func TestRunner_ParseTemplatesWithoutClient(t *testing.T) {
+	r := NewDeployer(nil, &CLIConfig{RunConfig: &RunCLIConfig{}}).(*Runner)
+	r.SetRunnerConfig(&runner.Config{DeploymentName: "example", PackName: "example"})
+	r.SetTemplates(map[string]string{
+		"example/templates/app.nomad.tpl": `
+job "app" {
+  group "app" {
+    task "web" {
+      driver = "docker"
+      config {
+        image = "nginx"
+      }
+      resources {
+        memory = 512
+      }
+    }
+  }
+}
+`,
+		"example/templates/tests/ping.nomad.tpl": `
+job "ping" {
+  type = "batch"
+  group "ping" {
+    task "ping" {
+      driver = "docker"
+      config {
+        image = "curlimages/curl"
+      }
+    }
+  }
+}
+`,
+	})
+
+	must.Nil(t, r.ParseTemplates())
+	must.MapLen(t, 1, r.parsedTemplates)
+	must.Eq(t, []string{"example/templates/tests/ping.nomad.tpl"}, r.TestTemplateNames())
+
+	tpl := r.parsedTemplates["example/templates/app.nomad.tpl"]
+	app := tpl.Job()
+	must.Eq(t, 512, *app.TaskGroups[0].Tasks[0].Resources.MemoryMB)
+	must.Eq(t, "example", app.Meta[PackDeploymentNameKey])
+}
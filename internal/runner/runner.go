@@ -5,6 +5,7 @@ package runner
 
 import (
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors"
+	"github.com/hashicorp/nomad-pack/internal/pkg/policy"
 	"github.com/hashicorp/nomad-pack/terminal"
 )
 
@@ -36,6 +37,12 @@ func HigherPlanCode(old, new int) int {
 	return old
 }
 
+// StageWaitFunc is called by Runner.Deploy once every object of a deployment
+// stage has been registered, and before moving on to the next stage. It is
+// passed the evaluation IDs of the stage's registrations and must block until
+// the stage has finished deploying, returning an error if it failed.
+type StageWaitFunc func(evalIDs []string) error
+
 // Runner is the interface that defines the deployment mechanism for creating
 // objects in a Nomad cluster from pack templates. This currently only covers
 // validation of templates against their native Nomad object, but will be
@@ -51,10 +58,16 @@ type Runner interface {
 	// conflicts with running packs.
 	CheckForConflicts(*errors.UIErrorContext) []*errors.WrappedUIContext
 
+	// CheckPolicies evaluates the policy rules against the parsed templates.
+	// Rules with a warning level are printed via the terminal.UI, while any
+	// error level violations are returned.
+	CheckPolicies(terminal.UI, []*policy.Rule, *errors.UIErrorContext) []*errors.WrappedUIContext
+
 	// Deploy the rendered templates to the Nomad cluster. A single error is
 	// returned as any error encountered is terminal. Any warnings and errors
 	// that need to be displayed to the console should be printed within the
-	// function and is why the UI and UIErrorContext is passed.
+	// function and is why the UI and UIErrorContext is passed. Each object
+	// registered is also emitted as a structured event using terminal.Event.
 	Deploy(terminal.UI, *errors.UIErrorContext) *errors.WrappedUIContext
 
 	// EvalIDs returns the evaluation IDs from the most recent Deploy call.
@@ -70,26 +83,44 @@ type Runner interface {
 	// based on the deployer implementation.
 	ParsedTemplates() any
 
+	// PlanResults returns the results of the last call to PlanDeployment,
+	// such as the modify index of each planned object, so that a plan can be
+	// saved. As with ParsedTemplates, the caller must assert the mapping type
+	// expected based on the deployer implementation.
+	PlanResults() any
+
 	// Name returns the name of the deployer which indicates the Nomad object
 	// it is designed to handle.
 	Name() string
 
 	// PlanDeployment plans the deployment of the templates. As the information
 	// of the plan is specific to the object, it is the responsibility of the
-	// implementation to print console information via the terminal.UI. The
-	// returned int identifies the exit code for the CLI. In order to keep
-	// consistency with the Nomad CLI and across pack objects, the following
-	// rules should be used:
+	// implementation to print console information via the terminal.UI, and
+	// to emit the plan of each object as a terminal.EventPlan event for UIs
+	// which support structured events. The returned int identifies the exit
+	// code for the CLI. In order to keep consistency with the Nomad CLI and
+	// across pack objects, the following rules should be used:
 	//
 	// code 0:   No objects will be created or destroyed.
 	// code 1:   Objects will be created or destroyed.
 	// code 255: An error occurred determining the plan.
 	PlanDeployment(terminal.UI, *errors.UIErrorContext) (int, []*errors.WrappedUIContext)
 
+	// RevertDeployment returns the objects changed by the most recent Deploy
+	// to the state they were in beforehand. It is used to undo a deploy that
+	// failed part way through, or whose result was unhealthy, when the
+	// deployment was performed atomically.
+	RevertDeployment(terminal.UI, *errors.UIErrorContext) []*errors.WrappedUIContext
+
 	// SetTemplates supplies the rendered templates to the deployer for use in
 	// subsequent function calls.
 	SetTemplates(map[string]string)
 
+	// SetStageWaiter sets the function used by Deploy to wait for a stage of
+	// an ordered deployment to complete before deploying the next. Without
+	// it, stages are registered in order but without waiting.
+	SetStageWaiter(StageWaitFunc)
+
 	// SetRunnerConfig is used to set the deployer configuration on the created
 	// deployer implementation.
 	SetRunnerConfig(config *Config)
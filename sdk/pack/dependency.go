@@ -3,6 +3,13 @@
 
 package pack
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+)
+
 // Dependency is a single dependency of a pack. A pack can have multiple and
 // each dependency represents an individual pack. A pack can be used as a
 // dependency multiple times. This allows helper pack to define jobspec blocks
@@ -24,6 +31,12 @@ type Dependency struct {
 	// specifying a git source. Defaults to latest.
 	Ref string `hcl:"ref,optional"`
 
+	// Version is a version constraint, such as "~> 1.2", which the pack must
+	// satisfy. It is resolved against the git tags of the source when
+	// vendoring, falling back to the pack.version of the pack's metadata.
+	// Cannot be used with Ref.
+	Version string `hcl:"version,optional"`
+
 	// Source is the remote source where the pack can be fetched. This string
 	// can follow any format as supported by go-getter or be a local path
 	// indicating the pack has already been downloaded.
@@ -64,5 +77,27 @@ func (d *Dependency) validate() error {
 	if d.Enabled == nil {
 		d.Enabled = pointerOf(true)
 	}
+
+	if d.Version != "" {
+		if d.Ref != "" {
+			return errors.New("dependency cannot set both ref and version")
+		}
+		if _, err := d.VersionConstraints(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// VersionConstraints parses the Version of the dependency. It returns nil if
+// the dependency has no version constraint.
+func (d *Dependency) VersionConstraints() (version.Constraints, error) {
+	if d.Version == "" {
+		return nil, nil
+	}
+	c, err := version.NewConstraint(d.Version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q for dependency %q: %w", d.Version, d.Name, err)
+	}
+	return c, nil
+}
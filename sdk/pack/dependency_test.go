@@ -54,6 +54,52 @@ func TestDependency_Validate(t *testing.T) {
 			},
 			name: "false enabled input",
 		},
+		{
+			inputDependency: &Dependency{
+				Name:    "example",
+				Source:  "git://example.com/example",
+				Version: "~> 1.2",
+			},
+			expectedOutputDependency: &Dependency{
+				Name:    "example",
+				Source:  "git://example.com/example",
+				Version: "~> 1.2",
+				Enabled: pointerOf(true),
+			},
+			name: "version constraint",
+		},
+		{
+			inputDependency: &Dependency{
+				Name:    "example",
+				Source:  "git://example.com/example",
+				Version: "not a version",
+			},
+			expectedOutputDependency: &Dependency{
+				Name:    "example",
+				Source:  "git://example.com/example",
+				Version: "not a version",
+				Enabled: pointerOf(true),
+			},
+			expectError: true,
+			name:        "invalid version constraint",
+		},
+		{
+			inputDependency: &Dependency{
+				Name:    "example",
+				Source:  "git://example.com/example",
+				Ref:     "v1.2.0",
+				Version: "~> 1.2",
+			},
+			expectedOutputDependency: &Dependency{
+				Name:    "example",
+				Source:  "git://example.com/example",
+				Ref:     "v1.2.0",
+				Version: "~> 1.2",
+				Enabled: pointerOf(true),
+			},
+			expectError: true,
+			name:        "ref and version",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,364 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-glint"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/helper"
+)
+
+// EventType identifies the kind of a structured event output by the JSON UI.
+type EventType string
+
+const (
+	// EventLog is a message output using one of the message functions of the
+	// UI, such as Info or Warning.
+	EventLog EventType = "log"
+
+	// EventError is an error output using ErrorWithContext.
+	EventError EventType = "error"
+
+	// EventTable and EventNamedValues carry the data of Table and NamedValues
+	// calls.
+	EventTable       EventType = "table"
+	EventNamedValues EventType = "named_values"
+
+	// The events below are emitted by commands using Event, and carry data
+	// which the other UIs only show as text.
+	EventJobRegistered    EventType = "job_registered"
+	EventJobDeregistered  EventType = "job_deregistered"
+	EventPlan             EventType = "plan"
+	EventDeploymentStatus EventType = "deployment_status"
+	EventRelease          EventType = "release"
+	EventResult           EventType = "result"
+)
+
+// The levels of the events output by the JSON UI.
+const (
+	LevelTrace = "trace"
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// EventUI is implemented by UIs which can output structured events alongside
+// the messages of the UI interface.
+type EventUI interface {
+	UI
+
+	// Event outputs an event of the given type, with a summary message and
+	// data which is encoded as JSON.
+	Event(typ EventType, level, msg string, data any)
+}
+
+// Event outputs a structured event if the UI supports them, and does nothing
+// otherwise. Commands use it to expose data they already show as text, such
+// as the diff of a plan, in a form which can be consumed by other programs.
+func Event(ui UI, typ EventType, level, msg string, data any) {
+	if eui, ok := ui.(EventUI); ok {
+		eui.Event(typ, level, msg, data)
+	}
+}
+
+// jsonEvent is a single line of output of the JSON UI.
+type jsonEvent struct {
+	Level     string    `json:"@level"`
+	Message   string    `json:"@message"`
+	Timestamp time.Time `json:"@timestamp"`
+	Type      EventType `json:"type"`
+	Data      any       `json:"data,omitempty"`
+}
+
+type jsonUI struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+}
+
+// JSONUI returns a UI which outputs newline-delimited JSON events to w,
+// rather than text. It is not interactive.
+func JSONUI(w io.Writer) UI {
+	return &jsonUI{mu: &sync.Mutex{}, w: w}
+}
+
+// Event implements EventUI
+func (ui *jsonUI) Event(typ EventType, level, msg string, data any) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+	ui.write(typ, level, msg, data)
+}
+
+// write encodes and outputs a single event. The caller must hold the lock.
+func (ui *jsonUI) write(typ EventType, level, msg string, data any) {
+	b, err := json.Marshal(&jsonEvent{
+		Level:     level,
+		Message:   msg,
+		Timestamp: time.Now().UTC(),
+		Type:      typ,
+		Data:      data,
+	})
+	if err != nil {
+		// The data is built by the commands, so this should not happen, but
+		// the stream must stay parsable if it does.
+		b, _ = json.Marshal(&jsonEvent{
+			Level:     LevelError,
+			Message:   fmt.Sprintf("failed to encode %s event: %s", typ, err),
+			Timestamp: time.Now().UTC(),
+			Type:      EventError,
+		})
+	}
+	fmt.Fprintln(ui.w, string(b))
+}
+
+// log outputs a message as a log event, skipping those which are empty once
+// formatting is removed as they are only used for spacing.
+func (ui *jsonUI) log(level, msg string) {
+	msg = strings.TrimSpace(reAnsi.ReplaceAllString(msg, ""))
+	if msg == "" {
+		return
+	}
+
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+	ui.write(EventLog, level, msg, nil)
+}
+
+// Input implements UI
+func (ui *jsonUI) Input(input *Input) (string, error) {
+	return "", ErrNonInteractive
+}
+
+// Interactive implements UI
+func (ui *jsonUI) Interactive() bool {
+	return false
+}
+
+func (ui *jsonUI) WithPrefix(prefix string) UI {
+	return &jsonUI{
+		mu:     ui.mu,
+		w:      ui.w,
+		prefix: ui.prefix + prefix,
+	}
+}
+
+// Output implements UI
+func (ui *jsonUI) Output(msg string, raw ...any) {
+	msg, style, _ := Interpret(msg, raw...)
+	ui.log(styleLevel(style), msg)
+}
+
+// AppendToRow implements UI
+func (ui *jsonUI) AppendToRow(msg string, raw ...any) {
+	ui.Output(msg, raw...)
+}
+
+// styleLevel returns the level of the event for a message of the style.
+func styleLevel(style string) string {
+	switch style {
+	case ErrorStyle, ErrorBoldStyle:
+		return LevelError
+	case WarningStyle, WarningBoldStyle:
+		return LevelWarn
+	case DebugStyle:
+		return LevelDebug
+	case TraceStyle:
+		return LevelTrace
+	default:
+		return LevelInfo
+	}
+}
+
+// NamedValues implements UI
+func (ui *jsonUI) NamedValues(rows []NamedValue, opts ...Option) {
+	values := make(map[string]any, len(rows))
+	for _, row := range rows {
+		values[row.Name] = row.Value
+	}
+	ui.Event(EventNamedValues, LevelInfo, "", values)
+}
+
+// OutputWriters implements UI. Both writers are stderr, so that anything
+// written to them does not break up the stream of events on stdout.
+func (ui *jsonUI) OutputWriters() (io.Writer, io.Writer, error) {
+	return os.Stderr, os.Stderr, nil
+}
+
+// Status implements UI
+func (ui *jsonUI) Status() Status {
+	return &jsonStatus{ui: ui}
+}
+
+// StepGroup implements UI
+func (ui *jsonUI) StepGroup() StepGroup {
+	return &jsonStepGroup{ui: ui}
+}
+
+// LiveView implements UI
+func (ui *jsonUI) LiveView() LiveView {
+	return &jsonLiveView{}
+}
+
+// Table implements UI
+func (ui *jsonUI) Table(tbl *Table, opts ...Option) {
+	ui.Event(EventTable, LevelInfo, "", &jsonTable{Headers: tbl.Headers, Rows: tbl.Rows})
+}
+
+type jsonTable struct {
+	Headers []string   `json:"headers"`
+	Rows    [][]string `json:"rows"`
+}
+
+// Debug implements UI
+func (ui *jsonUI) Debug(msg string) {
+	ui.log(LevelDebug, ui.prefix+msg)
+}
+
+// Error implements UI
+func (ui *jsonUI) Error(msg string) {
+	ui.log(LevelError, ui.prefix+msg)
+}
+
+// ErrorWithContext satisfies the ErrorWithContext function on the UI
+// interface. The context entries are kept as a list, rather than being laid
+// out as text.
+func (ui *jsonUI) ErrorWithContext(err error, sub string, ctx ...string) {
+	ui.Event(EventError, LevelError, ui.prefix+helper.Title(sub), &jsonError{
+		Error:   err.Error(),
+		Context: ctx,
+	})
+}
+
+type jsonError struct {
+	Error   string   `json:"error"`
+	Context []string `json:"context,omitempty"`
+}
+
+// Header implements UI
+func (ui *jsonUI) Header(msg string) {
+	ui.log(LevelInfo, ui.prefix+msg)
+}
+
+// Info implements UI
+func (ui *jsonUI) Info(msg string) {
+	ui.log(LevelInfo, ui.prefix+msg)
+}
+
+// Success implements UI
+func (ui *jsonUI) Success(msg string) {
+	ui.log(LevelInfo, ui.prefix+msg)
+}
+
+// Trace implements UI
+func (ui *jsonUI) Trace(msg string) {
+	ui.log(LevelTrace, ui.prefix+msg)
+}
+
+// Warning implements UI
+func (ui *jsonUI) Warning(msg string) {
+	ui.log(LevelWarn, ui.prefix+msg)
+}
+
+// WarningBold implements UI
+func (ui *jsonUI) WarningBold(msg string) {
+	ui.log(LevelWarn, ui.prefix+msg)
+}
+
+type jsonStatus struct {
+	ui *jsonUI
+}
+
+func (s *jsonStatus) Update(msg string) {
+	s.ui.log(LevelInfo, msg)
+}
+
+func (s *jsonStatus) Step(status, msg string) {
+	s.ui.log(statusLevel(status), msg)
+}
+
+func (s *jsonStatus) Close() error {
+	return nil
+}
+
+// statusLevel returns the level of the event for a step with the status.
+func statusLevel(status string) string {
+	switch status {
+	case StatusError, StatusAbort:
+		return LevelError
+	case StatusWarn, StatusTimeout:
+		return LevelWarn
+	default:
+		return LevelInfo
+	}
+}
+
+type jsonStepGroup struct {
+	ui *jsonUI
+	wg sync.WaitGroup
+}
+
+// Add implements StepGroup
+func (g *jsonStepGroup) Add(str string, args ...any) Step {
+	step := &jsonStep{ui: g.ui, wg: &g.wg}
+	g.wg.Add(1)
+	step.Update(str, args...)
+	return step
+}
+
+// Wait implements StepGroup
+func (g *jsonStepGroup) Wait() {
+	g.wg.Wait()
+}
+
+type jsonStep struct {
+	ui   *jsonUI
+	wg   *sync.WaitGroup
+	once sync.Once
+}
+
+// TermOutput implements Step. Each write is output as a log event.
+func (s *jsonStep) TermOutput() io.Writer {
+	return jsonLogWriter{ui: s.ui}
+}
+
+func (s *jsonStep) Update(str string, args ...any) {
+	s.ui.log(LevelInfo, fmt.Sprintf(str, args...))
+}
+
+func (s *jsonStep) Status(status string) {}
+
+func (s *jsonStep) Done() {
+	s.once.Do(s.wg.Done)
+}
+
+func (s *jsonStep) Abort() {
+	s.Done()
+}
+
+type jsonLogWriter struct {
+	ui *jsonUI
+}
+
+func (w jsonLogWriter) Write(p []byte) (int, error) {
+	w.ui.log(LevelInfo, string(p))
+	return len(p), nil
+}
+
+// jsonLiveView does nothing, as glint components cannot be output as JSON.
+type jsonLiveView struct{}
+
+func (v *jsonLiveView) SetComponents(c ...glint.Component) {}
+
+func (v *jsonLiveView) Close() error {
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright IBM Corp. 2023, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event map[string]any
+		must.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		must.MapContainsKey(t, event, "@timestamp")
+		delete(event, "@timestamp")
+		events = append(events, event)
+	}
+	must.NoError(t, scanner.Err())
+	return events
+}
+
+func TestJSONUI(t *testing.T) {
+	var buf bytes.Buffer
+	ui := JSONUI(&buf)
+
+	ui.Info("Evaluation ID: abc")
+	ui.Info("")
+	ui.Warning(colorWarning.Sprint("Job Warnings:\nfoo\n"))
+	ui.Output("failed", WithErrorStyle())
+	ui.WithPrefix("[child] ").Success("done")
+	ui.ErrorWithContext(errors.New("boom"), "failed to deploy", "Pack Name: example")
+	ui.Table(&Table{Headers: []string{"Name"}, Rows: [][]string{{"example"}}})
+	Event(ui, EventResult, LevelInfo, "Run succeeded", map[string]any{"exit_code": 0})
+
+	must.Eq(t, []map[string]any{
+		{"@level": "info", "@message": "Evaluation ID: abc", "type": "log"},
+		{"@level": "warn", "@message": "Job Warnings:\nfoo", "type": "log"},
+		{"@level": "error", "@message": "failed", "type": "log"},
+		{"@level": "info", "@message": "[child] done", "type": "log"},
+		{
+			"@level": "error", "@message": "Failed To Deploy", "type": "error",
+			"data": map[string]any{"error": "boom", "context": []any{"Pack Name: example"}},
+		},
+		{
+			"@level": "info", "@message": "", "type": "table",
+			"data": map[string]any{"headers": []any{"Name"}, "rows": []any{[]any{"example"}}},
+		},
+		{
+			"@level": "info", "@message": "Run succeeded", "type": "result",
+			"data": map[string]any{"exit_code": float64(0)},
+		},
+	}, decodeEvents(t, &buf))
+}
+
+func TestEvent_TextUI(t *testing.T) {
+	// Text UIs do not support events, so they are dropped.
+	var ui nonInteractiveUI
+	Event(&ui, EventResult, LevelInfo, "Run succeeded", nil)
+
+	_, ok := UI(&ui).(EventUI)
+	must.False(t, ok)
+}